@@ -0,0 +1,227 @@
+package beeorm
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const aggregateCountColumn = "Count"
+
+func WithCache(where Where, ttl time.Duration) Where {
+	options := getWhereOptions(where)
+	options.cacheTTL = ttl
+	return options
+}
+
+func Count[E any](orm ORM, where Where) int {
+	row := aggregateRow(orm, getEntitySchema[E](orm), "Count", "COUNT(1)", where, sumAggregateValues)
+	if row == nil {
+		return 0
+	}
+	count, _ := strconv.Atoi(*row)
+	return count
+}
+
+func Sum[E any](orm ORM, column string, where Where) float64 {
+	schema := getEntitySchema[E](orm)
+	row := aggregateRow(orm, schema, "Sum", "SUM("+schema.quoteAggregateColumn(column)+")", where, sumAggregateValues)
+	if row == nil {
+		return 0
+	}
+	sum, _ := strconv.ParseFloat(*row, 64)
+	return sum
+}
+
+func Min[E any](orm ORM, column string, where Where) (value string, found bool) {
+	schema := getEntitySchema[E](orm)
+	row := aggregateRow(orm, schema, "Min", "MIN("+schema.quoteAggregateColumn(column)+")", where, minAggregateValue)
+	if row == nil {
+		return "", false
+	}
+	return *row, true
+}
+
+func Max[E any](orm ORM, column string, where Where) (value string, found bool) {
+	schema := getEntitySchema[E](orm)
+	row := aggregateRow(orm, schema, "Max", "MAX("+schema.quoteAggregateColumn(column)+")", where, maxAggregateValue)
+	if row == nil {
+		return "", false
+	}
+	return *row, true
+}
+
+func GroupBy[E any](orm ORM, columns []string, where Where) []Bind {
+	if len(columns) == 0 {
+		panic(fmt.Errorf("missing group by columns"))
+	}
+	schema := getEntitySchema[E](orm)
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = schema.quoteAggregateColumn(column)
+	}
+	groupBy := strings.Join(quoted, ",")
+	rows := queryAggregate(orm, schema, "GroupBy", groupBy+",COUNT(1)", groupBy, where)
+	results := make([]Bind, 0, len(rows))
+	groups := make(map[string]Bind)
+	for _, row := range rows {
+		groupKey := ""
+		for _, value := range row[0:len(columns)] {
+			if value == nil {
+				groupKey += "\x00nil"
+			} else {
+				groupKey += "\x00" + *value
+			}
+		}
+		count, _ := strconv.Atoi(*row[len(columns)])
+		if bind, has := groups[groupKey]; has {
+			bind[aggregateCountColumn] = bind[aggregateCountColumn].(int) + count
+			continue
+		}
+		bind := make(Bind, len(columns)+1)
+		for j, column := range columns {
+			if row[j] == nil {
+				bind[column] = nil
+			} else {
+				bind[column] = *row[j]
+			}
+		}
+		bind[aggregateCountColumn] = count
+		groups[groupKey] = bind
+		results = append(results, bind)
+	}
+	return results
+}
+
+func (e *entitySchema) quoteAggregateColumn(column string) string {
+	if _, has := e.columnMapping[column]; !has {
+		panic(fmt.Errorf("invalid column name %s", column))
+	}
+	return "`" + column + "`"
+}
+
+func aggregateRow(orm ORM, schema *entitySchema, operation, expression string, where Where, merge func(current, value string) string) *string {
+	var result *string
+	for _, row := range queryAggregate(orm, schema, operation, expression, "", where) {
+		if row[0] == nil {
+			continue
+		}
+		value := *row[0]
+		if result != nil {
+			value = merge(*result, value)
+		}
+		result = &value
+	}
+	return result
+}
+
+func sumAggregateValues(current, value string) string {
+	a, _ := strconv.ParseFloat(current, 64)
+	b, _ := strconv.ParseFloat(value, 64)
+	return strconv.FormatFloat(a+b, 'f', -1, 64)
+}
+
+func minAggregateValue(current, value string) string {
+	if compareAggregateValues(value, current) < 0 {
+		return value
+	}
+	return current
+}
+
+func maxAggregateValue(current, value string) string {
+	if compareAggregateValues(value, current) > 0 {
+		return value
+	}
+	return current
+}
+
+func compareAggregateValues(a, b string) int {
+	aFloat, errA := strconv.ParseFloat(a, 64)
+	bFloat, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		if aFloat < bFloat {
+			return -1
+		} else if aFloat > bFloat {
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+func queryAggregate(orm ORM, schema *entitySchema, operation, expression, groupBy string, where Where) [][]*string {
+	if where == nil {
+		where = NewWhere("1")
+	}
+	var ttl time.Duration
+	if options, isOptions := where.(*whereOptions); isOptions {
+		ttl = options.cacheTTL
+	}
+	shards := schema.getWhereShards(where)
+	where = schema.applySoftDelete(where)
+	conditions, tail := splitWhereTail(where.String())
+	if groupBy == "" {
+		checkError(checkWhereWithoutTail(where, operation))
+	} else if whereTailHasClause(tail, "LIMIT") || whereTailHasClause(tail, "GROUP BY") {
+		panic(fmt.Errorf("%s doesn't support GROUP BY or LIMIT in where: '%s'", operation, strings.TrimSpace(tail)))
+	}
+	if strings.TrimSpace(conditions) == "" {
+		conditions = "1"
+	}
+	/* #nosec */
+	query := "SELECT " + expression + " FROM `" + schema.GetTableName() + "` WHERE " + conditions
+	if groupBy != "" {
+		query += " GROUP BY " + groupBy + tail
+	}
+	var cacheKey string
+	var cache RedisCache
+	if ttl > 0 {
+		hashSource := query + fmt.Sprintf("%v", where.GetParameters())
+		if len(shards) < len(schema.getShards()) {
+			hashSource += shards[0].mysqlPoolCode
+		}
+		cacheKey = schema.cacheKey + ":aggregate:" + hashString(hashSource)
+		cache = orm.Engine().Redis(schema.getForcedRedisCode())
+		fromCache, has := cache.Get(orm, cacheKey)
+		if has {
+			var results [][]*string
+			err := jsoniter.ConfigFastest.UnmarshalFromString(fromCache, &results)
+			checkError(err)
+			return results
+		}
+	}
+	results := make([][]*string, 0)
+	for _, shard := range shards {
+		func() {
+			rows, closeRows := shard.GetDB().Query(orm, query, where.GetParameters()...)
+			defer closeRows()
+			columns := len(rows.Columns())
+			for rows.Next() {
+				values := make([]sql.NullString, columns)
+				pointers := make([]any, columns)
+				for i := range values {
+					pointers[i] = &values[i]
+				}
+				rows.Scan(pointers...)
+				row := make([]*string, columns)
+				for i, value := range values {
+					if value.Valid {
+						v := value.String
+						row[i] = &v
+					}
+				}
+				results = append(results, row)
+			}
+		}()
+	}
+	if ttl > 0 {
+		asJSON, err := jsoniter.ConfigFastest.MarshalToString(results)
+		checkError(err)
+		cache.Set(orm, cacheKey, asJSON, ttl)
+	}
+	return results
+}
@@ -0,0 +1,76 @@
+package beeorm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type aggregateEntity struct {
+	ID         uint64 `orm:"softDelete"`
+	Name       string
+	Category   string
+	Price      float64
+	FakeDelete bool
+}
+
+func TestAggregate(t *testing.T) {
+	var entity *aggregateEntity
+	orm := PrepareTables(t, NewRegistry(), entity)
+
+	assert.Equal(t, 0, Count[aggregateEntity](orm, nil))
+	assert.Equal(t, float64(0), Sum[aggregateEntity](orm, "Price", nil))
+	_, found := Min[aggregateEntity](orm, "Price", nil)
+	assert.False(t, found)
+
+	for i := 1; i <= 10; i++ {
+		entity = NewEntity[aggregateEntity](orm)
+		entity.Name = fmt.Sprintf("Name %d", i)
+		entity.Category = "a"
+		if i > 6 {
+			entity.Category = "b"
+		}
+		entity.Price = float64(i)
+	}
+	assert.NoError(t, orm.Flush())
+	DeleteEntity(orm, entity)
+	assert.NoError(t, orm.Flush())
+
+	assert.Equal(t, 9, Count[aggregateEntity](orm, nil))
+	assert.Equal(t, 10, Count[aggregateEntity](orm, WithDeleted(NewWhere("1"))))
+	assert.Equal(t, 3, Count[aggregateEntity](orm, NewWhere("`Price` > ?", 6)))
+	assert.Equal(t, float64(45), Sum[aggregateEntity](orm, "Price", nil))
+	minValue, found := Min[aggregateEntity](orm, "Price", NewWhere("`Category` = ?", "b"))
+	assert.True(t, found)
+	assert.Equal(t, "7", minValue)
+	maxValue, found := Max[aggregateEntity](orm, "Price", nil)
+	assert.True(t, found)
+	assert.Equal(t, "9", maxValue)
+
+	groups := GroupBy[aggregateEntity](orm, []string{"Category"}, NewWhere("1 ORDER BY `Category`"))
+	assert.Equal(t, []Bind{{"Category": "a", "Count": 6}, {"Category": "b", "Count": 3}}, groups)
+
+	where := WithCache(NewWhere("`Category` = ?", "a"), time.Minute)
+	assert.Equal(t, 6, Count[aggregateEntity](orm, where))
+	entity = NewEntity[aggregateEntity](orm)
+	entity.Name = "Name 11"
+	entity.Category = "a"
+	assert.NoError(t, orm.Flush())
+	assert.Equal(t, 6, Count[aggregateEntity](orm, where))
+	assert.Equal(t, 7, Count[aggregateEntity](orm, NewWhere("`Category` = ?", "a")))
+
+	assert.PanicsWithError(t, "invalid column name Invalid", func() {
+		Sum[aggregateEntity](orm, "Invalid", nil)
+	})
+	assert.PanicsWithError(t, "Count doesn't support ORDER BY, GROUP BY or LIMIT in where: 'LIMIT 5'", func() {
+		Count[aggregateEntity](orm, NewWhere("`Category` = ? LIMIT 5", "a"))
+	})
+	assert.PanicsWithError(t, "Max doesn't support ORDER BY, GROUP BY or LIMIT in where: 'ORDER BY `Price` DESC'", func() {
+		Max[aggregateEntity](orm, "Price", NewWhere("1 ORDER BY `Price` DESC"))
+	})
+	assert.PanicsWithError(t, "GroupBy doesn't support GROUP BY or LIMIT in where: 'ORDER BY `Category` LIMIT 1'", func() {
+		GroupBy[aggregateEntity](orm, []string{"Category"}, NewWhere("1 ORDER BY `Category` LIMIT 1"))
+	})
+}
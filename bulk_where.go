@@ -0,0 +1,394 @@
+package beeorm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const bulkOperationPageSize = 1000
+
+type bulkOperation struct {
+	schema *entitySchema
+	where  Where
+	bind   Bind
+}
+
+func UpdateWhere[E any](orm ORM, where Where, bind Bind) error {
+	schema := getEntitySchema[E](orm)
+	err := checkWhereWithoutTail(where, "UpdateWhere")
+	if err != nil {
+		return err
+	}
+	newBind := Bind{}
+	for column, value := range bind {
+		if column == "ID" {
+			return &BindError{column, "update not allowed"}
+		}
+		setter, has := schema.fieldBindSetters[column]
+		if !has {
+			return &BindError{column, "unknown field"}
+		}
+		newValue, err := setter(value)
+		if err != nil {
+			return err
+		}
+		newBind[column] = newValue
+	}
+	if len(newBind) == 0 {
+		return nil
+	}
+	orm.(*ormImplementation).appendBulkOperation(&bulkOperation{schema: schema, where: where, bind: newBind})
+	return nil
+}
+
+func DeleteWhere[E any](orm ORM, where Where) error {
+	schema := getEntitySchema[E](orm)
+	err := checkWhereWithoutTail(where, "DeleteWhere")
+	if err != nil {
+		return err
+	}
+	if len(schema.onDeleteChildren) > 0 && schema.softDeleteColumn == "" {
+		return fmt.Errorf("entity '%s' referenced with onDelete can't be deleted with DeleteWhere", schema.t.String())
+	}
+	orm.(*ormImplementation).appendBulkOperation(&bulkOperation{schema: schema, where: where})
+	return nil
+}
+
+func (orm *ormImplementation) appendBulkOperation(operation *bulkOperation) {
+	orm.mutexFlush.Lock()
+	defer orm.mutexFlush.Unlock()
+	orm.bulkOperations = append(orm.bulkOperations, operation)
+}
+
+func (orm *ormImplementation) handleBulkOperation(async bool, operation *bulkOperation) {
+	schema := operation.schema
+	where := schema.applySoftDelete(operation.where)
+	conditions := where.String()
+	if strings.TrimSpace(conditions) == "" {
+		conditions = "1"
+	}
+	columns := orm.bulkOperationColumns(operation)
+	/* #nosec */
+	query := "SELECT `ID`"
+	for _, column := range columns {
+		query += ",`" + column + "`"
+	}
+	query += " FROM `" + schema.GetTableName() + "` WHERE `ID` > ? AND (" + conditions + ") ORDER BY `ID` LIMIT " +
+		strconv.Itoa(bulkOperationPageSize)
+	for _, shard := range schema.getWhereShards(operation.where) {
+		bulkShard := shard
+		if async {
+			orm.executeBulkOperation(true, operation, bulkShard, bulkShard.GetDB().Primary(), query, columns, conditions, where.GetParameters())
+			continue
+		}
+		orm.appendDBAction(bulkShard, func(db DBBase) {
+			orm.executeBulkOperation(false, operation, bulkShard, db, query, columns, conditions, where.GetParameters())
+		})
+	}
+}
+
+func (orm *ormImplementation) bulkOperationColumns(operation *bulkOperation) []string {
+	schema := operation.schema
+	isDelete := operation.bind == nil
+	_, hasLogTable := orm.engine.registry.entityLogSchemas[schema.t]
+	if isDelete && hasLogTable {
+		return schema.GetColumns()[1:]
+	}
+	columns := make(map[string]bool)
+	for _, indexColumns := range schema.GetUniqueIndexes() {
+		for _, column := range indexColumns {
+			if isDelete || hasBindColumn(operation.bind, indexColumns) {
+				columns[column] = true
+			}
+		}
+	}
+	for column := range schema.cachedReferences {
+		if _, has := operation.bind[column]; isDelete || has {
+			columns[column] = true
+		}
+	}
+	if hasLogTable {
+		for column := range operation.bind {
+			columns[column] = true
+		}
+	}
+	result := make([]string, 0, len(columns))
+	for column := range columns {
+		result = append(result, column)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func hasBindColumn(bind Bind, columns []string) bool {
+	for _, column := range columns {
+		if _, has := bind[column]; has {
+			return true
+		}
+	}
+	return false
+}
+
+func (orm *ormImplementation) executeBulkOperation(async bool, operation *bulkOperation, schema *entitySchema, db DBBase,
+	query string, columns []string, conditions string, parameters []any) {
+	isDelete := operation.bind == nil
+	var softDeleteValue any
+	if isDelete && schema.softDeleteColumn != "" {
+		softDeleteValue = schema.getSoftDeleteBindValue()
+	}
+	lastID := uint64(0)
+	for {
+		ids, oldBinds := orm.loadBulkOperationColumns(schema, db, query, columns, append([]any{lastID}, parameters...))
+		if len(ids) == 0 {
+			break
+		}
+		lastID = ids[len(ids)-1]
+		var sql string
+		var args []any
+		if !isDelete {
+			sql, args = buildBulkUpdateQuery(async, schema, operation.bind)
+		} else if softDeleteValue != nil {
+			sql = schema.getSoftDeleteQuery()
+			args = []any{softDeleteValue}
+		} else {
+			sql = schema.getDeleteQuery()
+		}
+		sql += " WHERE `ID` IN ("
+		for i, id := range ids {
+			if i > 0 {
+				sql += ","
+			}
+			if async {
+				sql += strconv.FormatUint(id, 10)
+			} else {
+				sql += "?"
+				args = append(args, id)
+			}
+		}
+		sql += ") AND (" + conditions + ")"
+		for _, parameter := range parameters {
+			if async {
+				asUint64, isUint64 := parameter.(uint64)
+				if isUint64 {
+					parameter = strconv.FormatUint(asUint64, 10)
+				}
+			}
+			args = append(args, parameter)
+		}
+		if isDelete && softDeleteValue == nil {
+			orm.invalidateForeignKeyChildren(schema, ids, make(map[*entitySchema]map[uint64]bool))
+		}
+		if async {
+			orm.publishAsyncEvent(schema, append([]any{sql}, args...))
+		} else {
+			db.Exec(orm, sql, args...)
+		}
+		for i, id := range ids {
+			orm.invalidateBulkOperationRow(schema, id, oldBinds[i], operation.bind)
+		}
+		if len(ids) < bulkOperationPageSize {
+			break
+		}
+	}
+}
+
+func (orm *ormImplementation) loadBulkOperationRows(schema *entitySchema, query string, parameters []any) (ids []uint64, binds []Bind) {
+	rows, closeRows := schema.GetDB().Primary().Query(orm, query, parameters...)
+	defer closeRows()
+	for rows.Next() {
+		pointers := prepareScan(schema)
+		rows.Scan(pointers...)
+		value := reflect.New(schema.t)
+		deserializeFromDB(schema.fields, value.Elem(), pointers)
+		bind := Bind{}
+		err := fillBindFromOneSource(orm, bind, value.Elem(), schema.fields, "")
+		checkError(err)
+		ids = append(ids, *pointers[0].(*uint64))
+		binds = append(binds, bind)
+	}
+	return ids, binds
+}
+
+func (orm *ormImplementation) loadBulkOperationColumns(schema *entitySchema, db DBBase, query string, columns []string,
+	parameters []any) (ids []uint64, binds []Bind) {
+	rows, closeRows := db.Query(orm, query, parameters...)
+	defer closeRows()
+	for rows.Next() {
+		var id uint64
+		pointers := make([]any, len(columns)+1)
+		pointers[0] = &id
+		for i := range columns {
+			pointers[i+1] = &sql.NullString{}
+		}
+		rows.Scan(pointers...)
+		bind := Bind{}
+		for i, column := range columns {
+			value := pointers[i+1].(*sql.NullString)
+			if !value.Valid {
+				bind[column] = nil
+				continue
+			}
+			bindValue, err := schema.fieldBindSetters[column](value.String)
+			checkError(err)
+			bind[column] = bindValue
+		}
+		ids = append(ids, id)
+		binds = append(binds, bind)
+	}
+	return ids, binds
+}
+
+func buildBulkUpdateQuery(async bool, schema *entitySchema, bind Bind) (sql string, args []any) {
+	columns := make([]string, 0, len(bind))
+	for column := range bind {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	/* #nosec */
+	sql = "UPDATE `" + schema.GetTableName() + "` SET "
+	args = make([]any, 0, len(columns))
+	for i, column := range columns {
+		if i > 0 {
+			sql += ","
+		}
+		sql += "`" + column + "`=?"
+		value := bind[column]
+		if async {
+			asUint64, isUint64 := value.(uint64)
+			if isUint64 {
+				value = strconv.FormatUint(asUint64, 10)
+			}
+		}
+		args = append(args, value)
+	}
+	_, hasVersion := bind[schema.versionColumn]
+	if schema.versionColumn != "" && !hasVersion {
+		sql += ",`" + schema.versionColumn + "`=`" + schema.versionColumn + "`+1"
+	}
+	return sql, args
+}
+
+func (orm *ormImplementation) invalidateBulkOperationRow(schema *entitySchema, id uint64, oldBind, newBind Bind) {
+	isDelete := newBind == nil
+	idAsString := strconv.FormatUint(id, 10)
+	if schema.hasLocalCache {
+		orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
+			schema.localCache.removeEntity(orm, id)
+		})
+		orm.invalidateLocalCacheEntity(schema, id)
+	}
+	if schema.hasRedisCache {
+		orm.RedisPipeLine(schema.redisCache.GetCode()).Del(schema.getCacheKey() + ":" + idAsString)
+	}
+	if orm.transaction != nil {
+		orm.transaction.mutex.Lock()
+		orm.transaction.touched = append(orm.transaction.touched, transactionTouchedEntity{schema: schema, id: id})
+		orm.transaction.mutex.Unlock()
+	}
+	uniqueIndexes := schema.GetUniqueIndexes()
+	if len(uniqueIndexes) > 0 {
+		var mergedBind Bind
+		if !isDelete {
+			mergedBind = make(Bind, len(oldBind))
+			for column, value := range oldBind {
+				mergedBind[column] = value
+			}
+			for column, value := range newBind {
+				mergedBind[column] = value
+			}
+		}
+		p := orm.RedisPipeLine(schema.getForcedRedisCode())
+		for indexName, indexColumns := range uniqueIndexes {
+			changed := isDelete
+			for _, column := range indexColumns {
+				if _, has := newBind[column]; has {
+					changed = true
+					break
+				}
+			}
+			if !changed {
+				continue
+			}
+			hSetKey := schema.getCacheKey() + ":" + indexName
+			hField, hasKey := buildUniqueKeyHSetField(schema, indexColumns, oldBind)
+			if hasKey {
+				p.HDel(hSetKey, hField)
+			}
+			if !isDelete {
+				hField, hasKey = buildUniqueKeyHSetField(schema, indexColumns, mergedBind)
+				if hasKey {
+					p.HSet(hSetKey, hField, idAsString)
+				}
+			}
+		}
+	}
+	for columnName := range schema.cachedReferences {
+		newValue, changed := newBind[columnName]
+		if !isDelete && !changed {
+			continue
+		}
+		refColumn := columnName
+		if before := oldBind[columnName]; before != nil {
+			orm.invalidateBulkOperationReference(schema, refColumn, before.(uint64))
+			redisSetKey := schema.cacheKey + ":" + refColumn + ":" + strconv.FormatUint(before.(uint64), 10)
+			orm.RedisPipeLine(schema.getForcedRedisCode()).SRem(redisSetKey, idAsString)
+		}
+		if !isDelete && newValue != nil {
+			orm.invalidateBulkOperationReference(schema, refColumn, newValue.(uint64))
+			redisSetKey := schema.cacheKey + ":" + refColumn + ":" + strconv.FormatUint(newValue.(uint64), 10)
+			orm.RedisPipeLine(schema.getForcedRedisCode()).SAdd(redisSetKey, idAsString)
+		}
+	}
+	if isDelete && schema.cacheAll {
+		orm.invalidateBulkOperationReference(schema, cacheAllFakeReferenceKey, 0)
+		redisSetKey := schema.cacheKey + ":" + cacheAllFakeReferenceKey
+		orm.RedisPipeLine(schema.getForcedRedisCode()).SRem(redisSetKey, idAsString)
+	}
+	logTableSchema, hasLogTable := orm.engine.registry.entityLogSchemas[schema.t]
+	if hasLogTable {
+		data := make([]any, 6, 7)
+		data[0] = "INSERT INTO `" + logTableSchema.tableName + "`(`ID`,`EntityID`,`Date`,`Meta`,`Before`) VALUES(?,?,?,?,?)"
+		data[1] = strconv.FormatUint(logTableSchema.uuid(orm), 10)
+		data[2] = idAsString
+		data[3] = time.Now().Format(time.DateTime)
+		if len(orm.meta) > 0 {
+			asJSON, _ := jsoniter.ConfigFastest.MarshalToString(orm.meta)
+			data[4] = asJSON
+		} else {
+			data[4] = nil
+		}
+		if isDelete {
+			oldBind["ID"] = id
+			asJSON, _ := jsoniter.ConfigFastest.MarshalToString(oldBind)
+			data[5] = asJSON
+		} else {
+			before := make(Bind, len(newBind))
+			for column := range newBind {
+				before[column] = oldBind[column]
+			}
+			data[0] = "INSERT INTO `" + logTableSchema.tableName + "`(`ID`,`EntityID`,`Date`,`Meta`,`Before`,`After`) VALUES(?,?,?,?,?,?)"
+			asJSON, _ := jsoniter.ConfigFastest.MarshalToString(before)
+			data[5] = asJSON
+			asJSON, _ = jsoniter.ConfigFastest.MarshalToString(newBind)
+			data = append(data, asJSON)
+		}
+		orm.publishAsyncEvent(logTableSchema, data)
+	}
+}
+
+func (orm *ormImplementation) invalidateBulkOperationReference(schema *entitySchema, column string, id uint64) {
+	if !schema.hasLocalCache {
+		return
+	}
+	orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
+		schema.localCache.removeReference(orm, column, id)
+	})
+	orm.invalidateLocalCacheReference(schema, column, id)
+}
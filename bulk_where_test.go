@@ -0,0 +1,126 @@
+package beeorm
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+type bulkWhereEntity struct {
+	ID   uint64                        `orm:"localCache;redisCache"`
+	Name string                        `orm:"unique=Name"`
+	Age  uint8                         `orm:"index=Age"`
+	Ref  Reference[bulkWhereReference] `orm:"index=Ref;cached"`
+}
+
+type bulkWhereReference struct {
+	ID   uint64
+	Name string
+}
+
+func TestBulkWhereLocalCache(t *testing.T) {
+	testBulkWhere(t, false, true, false)
+}
+
+func TestBulkWhereRedisCache(t *testing.T) {
+	testBulkWhere(t, false, false, true)
+}
+
+func TestBulkWhereNoCache(t *testing.T) {
+	testBulkWhere(t, false, false, false)
+}
+
+func TestBulkWhereAsync(t *testing.T) {
+	testBulkWhere(t, true, true, true)
+}
+
+func testBulkWhere(t *testing.T, async, local, redis bool) {
+	var entity *bulkWhereEntity
+	var reference *bulkWhereReference
+	orm := PrepareTables(t, NewRegistry(), entity, reference, LogEntity[bulkWhereEntity]{})
+	schema := GetEntitySchema[bulkWhereEntity](orm)
+	schema.DisableCache(!local, !redis)
+
+	flush := func() {
+		if async {
+			assert.NoError(t, orm.FlushAsync())
+			assert.NoError(t, runAsyncConsumer(orm, false))
+		} else {
+			assert.NoError(t, orm.Flush())
+		}
+	}
+
+	ref1 := NewEntity[bulkWhereReference](orm)
+	ref1.Name = "Ref 1"
+	ref2 := NewEntity[bulkWhereReference](orm)
+	ref2.Name = "Ref 2"
+	var ids []uint64
+	for _, name := range []string{"a", "b", "c", "d"} {
+		entity = NewEntity[bulkWhereEntity](orm)
+		entity.Name = name
+		entity.Age = 10
+		entity.Ref = Reference[bulkWhereReference](ref1.ID)
+		ids = append(ids, entity.ID)
+	}
+	assert.NoError(t, orm.Flush())
+	assert.NoError(t, runAsyncConsumer(orm, false))
+	assert.Equal(t, 4, GetByReference[bulkWhereEntity](orm, "Ref", ref1.ID).Len())
+	_, found := GetByUniqueIndex[bulkWhereEntity](orm, "Name", "a")
+	assert.True(t, found)
+
+	err := UpdateWhere[bulkWhereEntity](orm, NewWhere("`Name` IN ?", []string{"a", "b"}), Bind{"Age": 20, "Ref": ref2.ID})
+	assert.NoError(t, err)
+	flush()
+	entity, found = GetByID[bulkWhereEntity](orm, ids[0])
+	assert.True(t, found)
+	assert.Equal(t, uint8(20), entity.Age)
+	assert.Equal(t, ref2.ID, entity.Ref.GetID())
+	entity, found = GetByID[bulkWhereEntity](orm, ids[2])
+	assert.True(t, found)
+	assert.Equal(t, uint8(10), entity.Age)
+	assert.Equal(t, 2, GetByReference[bulkWhereEntity](orm, "Ref", ref1.ID).Len())
+	assert.Equal(t, 2, GetByReference[bulkWhereEntity](orm, "Ref", ref2.ID).Len())
+
+	err = UpdateWhere[bulkWhereEntity](orm, NewWhere("ID = ?", ids[3]), Bind{"Name": "e"})
+	assert.NoError(t, err)
+	flush()
+	_, found = GetByUniqueIndex[bulkWhereEntity](orm, "Name", "d")
+	assert.False(t, found)
+	entity, found = GetByUniqueIndex[bulkWhereEntity](orm, "Name", "e")
+	assert.True(t, found)
+	assert.Equal(t, ids[3], entity.ID)
+
+	logs := Search[LogEntity[bulkWhereEntity]](orm, NewWhere("EntityID = ? ORDER BY ID DESC", ids[3]), nil)
+	assert.True(t, logs.Next())
+	var bind Bind
+	assert.NoError(t, jsoniter.ConfigFastest.Unmarshal(logs.Entity().Before, &bind))
+	assert.Equal(t, Bind{"Name": "d"}, bind)
+	assert.NoError(t, jsoniter.ConfigFastest.Unmarshal(logs.Entity().After, &bind))
+	assert.Equal(t, Bind{"Name": "e"}, bind)
+
+	assert.NoError(t, DeleteWhere[bulkWhereEntity](orm, NewWhere("`Age` = ?", 20)))
+	flush()
+	_, found = GetByID[bulkWhereEntity](orm, ids[0])
+	assert.False(t, found)
+	_, found = GetByID[bulkWhereEntity](orm, ids[1])
+	assert.False(t, found)
+	_, found = GetByID[bulkWhereEntity](orm, ids[2])
+	assert.True(t, found)
+	_, found = GetByUniqueIndex[bulkWhereEntity](orm, "Name", "a")
+	assert.False(t, found)
+	assert.Equal(t, 0, GetByReference[bulkWhereEntity](orm, "Ref", ref2.ID).Len())
+
+	err = UpdateWhere[bulkWhereEntity](orm, NewWhere("1"), Bind{"Invalid": 1})
+	assert.EqualError(t, err, "[Invalid] unknown field")
+	err = UpdateWhere[bulkWhereEntity](orm, NewWhere("1"), Bind{"ID": 1})
+	assert.EqualError(t, err, "[ID] update not allowed")
+	err = UpdateWhere[bulkWhereEntity](orm, NewWhere("`Age` = ? LIMIT 10", 10), Bind{"Age": 30})
+	assert.EqualError(t, err, "UpdateWhere doesn't support ORDER BY, GROUP BY or LIMIT in where: 'LIMIT 10'")
+	err = DeleteWhere[bulkWhereEntity](orm, NewWhere("`Age` = ? ORDER BY `ID` LIMIT 1", 10))
+	assert.EqualError(t, err, "DeleteWhere doesn't support ORDER BY, GROUP BY or LIMIT in where: 'ORDER BY `ID` LIMIT 1'")
+	assert.NoError(t, orm.Flush())
+	entity, found = GetByID[bulkWhereEntity](orm, ids[2])
+	assert.True(t, found)
+	assert.Equal(t, uint8(10), entity.Age)
+}
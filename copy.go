@@ -6,7 +6,7 @@ import (
 
 func Copy[E any](orm ORM, source E) E {
 	schema := orm.Engine().Registry().EntitySchema(source).(*entitySchema)
-	insertable := newEntityInsertable(orm, schema)
+	insertable := newEntityInsertable(orm, schema, schema.getShardKey(reflect.ValueOf(source).Elem()))
 	copyEntity(reflect.ValueOf(source).Elem(), insertable.value.Elem(), schema.fields, false)
 	return insertable.entity.(E)
 }
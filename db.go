@@ -14,6 +14,7 @@ type MySQLConfig interface {
 	GetDataSourceURI() string
 	GetOptions() *MySQLOptions
 	getClient() *sql.DB
+	getDialect() dialect
 }
 
 type mySQLConfig struct {
@@ -22,6 +23,7 @@ type mySQLConfig struct {
 	databaseName   string
 	client         *sql.DB
 	options        *MySQLOptions
+	dialect        dialect
 }
 
 func (p *mySQLConfig) GetCode() string {
@@ -44,6 +46,10 @@ func (p *mySQLConfig) GetOptions() *MySQLOptions {
 	return p.options
 }
 
+func (p *mySQLConfig) getDialect() dialect {
+	return p.dialect
+}
+
 type ExecResult interface {
 	LastInsertId() uint64
 	RowsAffected() uint64
@@ -227,7 +233,7 @@ type preparedStmtStruct struct {
 func (p preparedStmtStruct) Exec(orm ORM, args ...any) ExecResult {
 	hasLogger, _ := orm.getDBLoggers()
 	start := getNow(hasLogger)
-	rows, err := p.stmt.Exec(args...)
+	rows, err := p.stmt.Exec(p.db.config.getDialect().translateArgs(args)...)
 	if hasLogger {
 		message := p.query
 		if len(args) > 0 {
@@ -242,7 +248,7 @@ func (p preparedStmtStruct) Exec(orm ORM, args ...any) ExecResult {
 func (p preparedStmtStruct) Query(orm ORM, args ...any) (rows Rows, close func()) {
 	hasLogger, _ := orm.getDBLoggers()
 	start := getNow(hasLogger)
-	result, err := p.stmt.Query(args...)
+	result, err := p.stmt.Query(p.db.config.getDialect().translateArgs(args)...)
 	if hasLogger {
 		message := p.query
 		if len(args) > 0 {
@@ -263,7 +269,7 @@ func (p preparedStmtStruct) Query(orm ORM, args ...any) (rows Rows, close func()
 func (p preparedStmtStruct) QueryRow(orm ORM, args []any, toFill ...any) (found bool) {
 	hasLogger, _ := orm.getDBLoggers()
 	start := getNow(hasLogger)
-	row := p.stmt.QueryRow(args...)
+	row := p.stmt.QueryRow(p.db.config.getDialect().translateArgs(args)...)
 	err := row.Scan(toFill...)
 	message := ""
 	if hasLogger {
@@ -311,6 +317,7 @@ type DBBase interface {
 type DB interface {
 	DBBase
 	Begin(orm ORM) DBTransaction
+	Primary() DB
 }
 
 type DBTransaction interface {
@@ -323,6 +330,7 @@ type dbImplementation struct {
 	client      sqlClient
 	config      MySQLConfig
 	transaction bool
+	replicas    *dbReplicas
 }
 
 func (db *dbImplementation) GetConfig() MySQLConfig {
@@ -378,8 +386,13 @@ func (db *dbImplementation) SetMockDBClient(mock DBClient) {
 }
 
 func (db *dbImplementation) Prepare(orm ORM, query string) (stmt PreparedStmt, close func()) {
+	tx := orm.getDBTransaction(db, true)
+	if tx != nil {
+		return tx.Prepare(orm, query)
+	}
 	hasLogger, _ := orm.getDBLoggers()
 	start := getNow(hasLogger)
+	query = db.config.getDialect().translateQuery(query)
 	result, err := db.client.Prepare(query)
 	if hasLogger {
 		message := query
@@ -394,6 +407,10 @@ func (db *dbImplementation) Prepare(orm ORM, query string) (stmt PreparedStmt, c
 }
 
 func (db *dbImplementation) Exec(orm ORM, query string, args ...any) ExecResult {
+	tx := orm.getDBTransaction(db, true)
+	if tx != nil {
+		return tx.Exec(orm, query, args...)
+	}
 	results, err := db.exec(orm, query, args...)
 	checkError(err)
 	return results
@@ -402,7 +419,8 @@ func (db *dbImplementation) Exec(orm ORM, query string, args ...any) ExecResult
 func (db *dbImplementation) exec(orm ORM, query string, args ...any) (ExecResult, error) {
 	hasLogger, _ := orm.getDBLoggers()
 	start := getNow(hasLogger)
-	rows, err := db.client.Exec(query, args...)
+	query = db.config.getDialect().translateQuery(query)
+	rows, err := db.client.Exec(query, db.config.getDialect().translateArgs(args)...)
 	if hasLogger {
 		message := query
 		if len(args) > 0 {
@@ -410,17 +428,25 @@ func (db *dbImplementation) exec(orm ORM, query string, args ...any) (ExecResult
 		}
 		db.fillLogFields(orm, "EXEC", message, start, err)
 	}
+	if err == nil {
+		db.stickToPrimary(orm)
+	}
 	return &execResult{r: rows}, err
 }
 
 func (db *dbImplementation) QueryRow(orm ORM, query Where, toFill ...any) (found bool) {
+	tx := orm.getDBTransaction(db, false)
+	if tx != nil {
+		return tx.QueryRow(orm, query, toFill...)
+	}
 	hasLogger, _ := orm.getDBLoggers()
 	start := getNow(hasLogger)
-	row := db.client.QueryRow(query.String(), query.GetParameters()...)
+	sqlQuery := db.config.getDialect().translateQuery(query.String())
+	row := db.readClient(orm).QueryRow(sqlQuery, db.config.getDialect().translateArgs(query.GetParameters())...)
 	err := row.Scan(toFill...)
 	message := ""
 	if hasLogger {
-		message = query.String()
+		message = sqlQuery
 		if len(query.GetParameters()) > 0 {
 			message += " " + fmt.Sprintf("%v", query.GetParameters())
 		}
@@ -444,9 +470,14 @@ func (db *dbImplementation) QueryRow(orm ORM, query Where, toFill ...any) (found
 }
 
 func (db *dbImplementation) Query(orm ORM, query string, args ...any) (rows Rows, close func()) {
+	tx := orm.getDBTransaction(db, false)
+	if tx != nil {
+		return tx.Query(orm, query, args...)
+	}
 	hasLogger, _ := orm.getDBLoggers()
 	start := getNow(hasLogger)
-	result, err := db.client.Query(query, args...)
+	query = db.config.getDialect().translateQuery(query)
+	result, err := db.readClient(orm).Query(query, db.config.getDialect().translateArgs(args)...)
 	if hasLogger {
 		message := query
 		if len(args) > 0 {
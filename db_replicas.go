@@ -0,0 +1,202 @@
+package beeorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const defaultReplicaHealthCheckInterval = time.Second * 5
+
+type dbReplica struct {
+	dataSourceName string
+	db             *sql.DB
+	client         *standardSQLClient
+	healthy        atomic.Bool
+}
+
+type dbReplicas struct {
+	replicas []*dbReplica
+	counter  atomic.Uint64
+	stop     chan struct{}
+}
+
+func newDBReplicas(config MySQLConfig, maxOpen, maxIdle int, maxLifetime time.Duration) (*dbReplicas, error) {
+	replicas := &dbReplicas{stop: make(chan struct{})}
+	options := config.GetOptions()
+	for _, dataSourceName := range options.Replicas {
+		db, err := sql.Open(config.getDialect().driverName(options), dataSourceName)
+		if err != nil {
+			return nil, err
+		}
+		db.SetMaxOpenConns(maxOpen)
+		db.SetMaxIdleConns(maxIdle)
+		db.SetConnMaxLifetime(maxLifetime)
+		replicas.replicas = append(replicas.replicas, &dbReplica{dataSourceName: dataSourceName, db: db, client: &standardSQLClient{db: db}})
+	}
+	replicas.checkHealth()
+	interval := options.ReplicaHealthCheckInterval
+	if interval <= 0 {
+		interval = defaultReplicaHealthCheckInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-replicas.stop:
+				return
+			case <-ticker.C:
+				replicas.checkHealth()
+			}
+		}
+	}()
+	return replicas, nil
+}
+
+func (r *dbReplicas) close() {
+	close(r.stop)
+	for _, replica := range r.replicas {
+		replica.healthy.Store(false)
+		_ = replica.db.Close()
+	}
+}
+
+func (r *dbReplicas) checkHealth() {
+	for _, replica := range r.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := replica.db.PingContext(ctx)
+		cancel()
+		replica.healthy.Store(err == nil)
+	}
+}
+
+func (r *dbReplicas) next() *dbReplica {
+	total := uint64(len(r.replicas))
+	start := r.counter.Add(1)
+	for i := uint64(0); i < total; i++ {
+		replica := r.replicas[(start+i)%total]
+		if replica.healthy.Load() {
+			return replica
+		}
+	}
+	return nil
+}
+
+func (db *dbImplementation) Primary() DB {
+	if db.replicas == nil {
+		return db
+	}
+	return &dbImplementation{client: db.client, config: db.config, transaction: db.transaction}
+}
+
+func withPrimary(where Where) Where {
+	options := getWhereOptions(where)
+	options.primary = true
+	return options
+}
+
+func getWhereDB(schema *entitySchema, where Where) DB {
+	options, isOptions := where.(*whereOptions)
+	if isOptions && options.primary {
+		return schema.GetDB().Primary()
+	}
+	return schema.GetDB()
+}
+
+func (db *dbImplementation) readClient(orm ORM) sqlClientBase {
+	if db.replicas == nil || db.transaction || orm.(*ormImplementation).isStickyToPrimary(db.config.GetCode()) {
+		return db.client
+	}
+	replica := db.replicas.next()
+	if replica == nil {
+		return db.client
+	}
+	return replica.client
+}
+
+func (db *dbImplementation) stickToPrimary(orm ORM) {
+	stickiness := db.config.GetOptions().ReplicaStickiness
+	if stickiness <= 0 || len(db.config.GetOptions().Replicas) == 0 {
+		return
+	}
+	cImplementation := orm.(*ormImplementation)
+	cImplementation.mutexData.Lock()
+	defer cImplementation.mutexData.Unlock()
+	if cImplementation.stickyPools == nil {
+		cImplementation.stickyPools = make(map[string]time.Time)
+	}
+	cImplementation.stickyPools[db.config.GetCode()] = time.Now().Add(stickiness)
+}
+
+func (orm *ormImplementation) isStickyToPrimary(code string) bool {
+	orm.mutexData.Lock()
+	defer orm.mutexData.Unlock()
+	until, has := orm.stickyPools[code]
+	if !has {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(orm.stickyPools, code)
+		return false
+	}
+	return true
+}
+
+func (db *dbImplementation) replicaLag() (time.Duration, error) {
+	if db.replicas == nil {
+		return 0, nil
+	}
+	var maxLag time.Duration
+	for _, replica := range db.replicas.replicas {
+		if !replica.healthy.Load() {
+			continue
+		}
+		lag, err := replica.lag()
+		if err != nil {
+			return 0, err
+		}
+		maxLag = max(maxLag, lag)
+	}
+	return maxLag, nil
+}
+
+func (r *dbReplica) lag() (time.Duration, error) {
+	rows, err := r.db.Query("SHOW REPLICA STATUS")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, errors.New("replica status is empty")
+	}
+	values := make([]sql.NullInt64, len(columns))
+	pointers := make([]any, len(columns))
+	for i, column := range columns {
+		if column == "Seconds_Behind_Source" || column == "Seconds_Behind_Master" {
+			pointers[i] = &values[i]
+		} else {
+			pointers[i] = new(sql.RawBytes)
+		}
+	}
+	if err = rows.Scan(pointers...); err != nil {
+		return 0, err
+	}
+	for _, value := range values {
+		if value.Valid {
+			return time.Duration(value.Int64) * time.Second, nil
+		}
+	}
+	return 0, errors.New("replication is not running on replica")
+}
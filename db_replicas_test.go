@@ -0,0 +1,116 @@
+package beeorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dbReplicaEntity struct {
+	ID   uint64
+	Name string
+}
+
+type dbReplicaCachedEntity struct {
+	ID   uint64 `orm:"localCache;redisCache"`
+	Name string
+	Ref  Reference[dbReplicaEntity] `orm:"cached"`
+}
+
+func TestDBReplicas(t *testing.T) {
+	var entity *dbReplicaEntity
+	PrepareTables(t, NewRegistry(), entity)
+
+	registry := NewRegistry()
+	registry.RegisterMySQL("root:root@tcp(localhost:3377)/test", DefaultPoolCode, &MySQLOptions{
+		Replicas:          []string{"root:root@tcp(localhost:3377)/test", "root:root@tcp(localhost:1)/test"},
+		ReplicaStickiness: time.Millisecond * 500,
+	})
+	registry.RegisterRedis("localhost:6385", 0, DefaultPoolCode, nil)
+	registry.RegisterEntity(entity)
+	engine, err := registry.Validate()
+	assert.NoError(t, err)
+	orm := engine.NewORM(context.Background())
+	db := engine.DB(DefaultPoolCode).(*dbImplementation)
+	assert.True(t, db.replicas.replicas[0].healthy.Load())
+	assert.False(t, db.replicas.replicas[1].healthy.Load())
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, db.replicas.replicas[0].client, db.readClient(orm))
+	}
+
+	entity = NewEntity[dbReplicaEntity](orm)
+	entity.Name = "Test"
+	assert.NoError(t, orm.Flush())
+	assert.Equal(t, db.client, db.readClient(orm))
+	assert.Equal(t, db.replicas.replicas[0].client, db.readClient(engine.NewORM(context.Background())))
+	loaded, found := GetByID[dbReplicaEntity](orm, entity.ID)
+	assert.True(t, found)
+	assert.Equal(t, "Test", loaded.Name)
+
+	time.Sleep(time.Millisecond * 600)
+	assert.Equal(t, db.replicas.replicas[0].client, db.readClient(orm))
+
+	err = orm.Transaction(func(tx ORM) error {
+		txDB := tx.getDBTransaction(db, true).(*dbImplementation)
+		assert.Equal(t, txDB.client, txDB.readClient(tx))
+		return nil
+	})
+	assert.NoError(t, err)
+
+	primary := db.Primary().(*dbImplementation)
+	assert.Equal(t, db.client, primary.readClient(engine.NewORM(context.Background())))
+	assert.Nil(t, primary.Primary().(*dbImplementation).replicas)
+
+	db.replicas.replicas[0].healthy.Store(false)
+	assert.Equal(t, db.client, db.readClient(orm))
+
+	engine.Close()
+	db.replicas.checkHealth()
+	assert.False(t, db.replicas.replicas[0].healthy.Load())
+	assert.Equal(t, db.client, db.readClient(orm))
+}
+
+func TestDBReplicasCacheReads(t *testing.T) {
+	var entity *dbReplicaCachedEntity
+	var reference *dbReplicaEntity
+	PrepareTables(t, NewRegistry(), entity, reference)
+
+	registry := NewRegistry()
+	registry.RegisterMySQL("root:root@tcp(localhost:3377)/test", DefaultPoolCode, &MySQLOptions{
+		Replicas: []string{"root:root@tcp(localhost:3377)/information_schema"},
+	})
+	registry.RegisterRedis("localhost:6385", 0, DefaultPoolCode, nil)
+	registry.RegisterLocalCache(DefaultPoolCode, 0)
+	registry.RegisterEntity(entity, reference)
+	engine, err := registry.Validate()
+	assert.NoError(t, err)
+	defer engine.Close()
+	orm := engine.NewORM(context.Background())
+	ref := NewEntity[dbReplicaEntity](orm)
+	ref.Name = "Ref"
+	entity = NewEntity[dbReplicaCachedEntity](orm)
+	entity.Name = "Cached"
+	entity.Ref = Reference[dbReplicaEntity](ref.ID)
+	assert.NoError(t, orm.Flush())
+
+	lc, _ := GetEntitySchema[dbReplicaCachedEntity](orm).GetLocalCache()
+	clearCache := func() {
+		lc.Clear(orm)
+		engine.Redis(DefaultPoolCode).FlushDB(orm)
+	}
+	clearCache()
+	orm = engine.NewORM(context.Background())
+	loaded, found := GetByID[dbReplicaCachedEntity](orm, entity.ID)
+	assert.True(t, found)
+	assert.Equal(t, "Cached", loaded.Name)
+	clearCache()
+	assert.Len(t, GetByIDs[dbReplicaCachedEntity](orm, entity.ID).All(), 1)
+	clearCache()
+	assert.Equal(t, 1, GetByReference[dbReplicaCachedEntity](orm, "Ref", ref.ID).Len())
+	assert.Panics(t, func() {
+		GetByID[dbReplicaEntity](orm, ref.ID)
+	})
+}
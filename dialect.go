@@ -0,0 +1,191 @@
+package beeorm
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var columnTypeRegexp = regexp.MustCompile(`^([a-z]+)(\([^)]*\))?`)
+
+type dialect interface {
+	driverName(options *MySQLOptions) string
+	translateQuery(query string) string
+	translateArgs(args []any) []any
+	loadServerLimits(db *sql.DB) (maxConnections, waitTimeout int, err error)
+	tableIdentifier(config MySQLConfig, tableName string) string
+	resetAutoIncrementSQL(config MySQLConfig, tableName string) string
+	truncateTableSQL(config MySQLConfig, tableName string) string
+	columnDefinition(column *columnDefinition) string
+	upsertClause(schema *entitySchema, columns []string) string
+	upsertReturnsID() bool
+	getAllTables(db DB) []string
+	createTableSQL(orm ORM, db DB, tableName string) string
+	getSchemaChanges(orm ORM, schema *entitySchema) (preAlters, alters, postAlters []Alter)
+	skippableAsyncError(rec any) (error, bool)
+	retryableAsyncError(rec any) (error, bool)
+}
+
+type columnDefinition struct {
+	name          string
+	columnType    string
+	notNull       bool
+	defaultValue  string
+	autoIncrement bool
+}
+
+type mysqlDialect struct{}
+
+func (d *mysqlDialect) driverName(options *MySQLOptions) string {
+	if options != nil && options.Driver != "" {
+		return options.Driver
+	}
+	return "mysql"
+}
+
+func (d *mysqlDialect) translateQuery(query string) string {
+	return query
+}
+
+func (d *mysqlDialect) translateArgs(args []any) []any {
+	return args
+}
+
+func (d *mysqlDialect) loadServerLimits(db *sql.DB) (maxConnections, waitTimeout int, err error) {
+	var skip string
+	err = db.QueryRow("SHOW VARIABLES LIKE 'max_connections'").Scan(&skip, &maxConnections)
+	if err != nil {
+		return 0, 0, err
+	}
+	err = db.QueryRow("SHOW VARIABLES LIKE 'wait_timeout'").Scan(&skip, &waitTimeout)
+	if err != nil {
+		return 0, 0, err
+	}
+	return maxConnections, waitTimeout, nil
+}
+
+func (d *mysqlDialect) tableIdentifier(config MySQLConfig, tableName string) string {
+	return fmt.Sprintf("`%s`.`%s`", config.GetDatabaseName(), tableName)
+}
+
+func (d *mysqlDialect) resetAutoIncrementSQL(config MySQLConfig, tableName string) string {
+	return fmt.Sprintf("ALTER TABLE %s AUTO_INCREMENT = 1", d.tableIdentifier(config, tableName))
+}
+
+func (d *mysqlDialect) truncateTableSQL(config MySQLConfig, tableName string) string {
+	return "TRUNCATE TABLE " + d.tableIdentifier(config, tableName)
+}
+
+func (d *mysqlDialect) columnDefinition(column *columnDefinition) string {
+	definition := fmt.Sprintf("`%s` %s", column.name, column.columnType)
+	if column.notNull {
+		definition += " NOT NULL"
+	}
+	if column.defaultValue != "" {
+		definition += " DEFAULT " + column.defaultValue
+	}
+	if column.autoIncrement {
+		definition += " AUTO_INCREMENT"
+	}
+	return definition
+}
+
+func (d *mysqlDialect) upsertClause(schema *entitySchema, columns []string) string {
+	clause := " ON DUPLICATE KEY UPDATE `ID`=LAST_INSERT_ID(`ID`)"
+	for _, column := range columns {
+		clause += ",`" + column + "`=VALUES(`" + column + "`)"
+	}
+	if schema.versionColumn != "" {
+		clause += ",`" + schema.versionColumn + "`=`" + schema.versionColumn + "`+1"
+	}
+	return clause
+}
+
+func (d *mysqlDialect) upsertReturnsID() bool {
+	return false
+}
+
+func (d *mysqlDialect) getAllTables(db DB) []string {
+	tables := make([]string, 0)
+	results, err := db.GetDBClient().Query("SHOW FULL TABLES WHERE Table_Type = 'BASE TABLE'")
+	checkError(err)
+	defer func() {
+		_ = results.Close()
+	}()
+	var skip string
+	for results.Next() {
+		var row string
+		err = results.Scan(&row, &skip)
+		checkError(err)
+		tables = append(tables, row)
+	}
+	err = results.Err()
+	checkError(err)
+	return tables
+}
+
+func (d *mysqlDialect) createTableSQL(orm ORM, db DB, tableName string) string {
+	var skip, createSQL string
+	if !db.QueryRow(orm, NewWhere(fmt.Sprintf("SHOW CREATE TABLE `%s`", tableName)), &skip, &createSQL) {
+		return ""
+	}
+	return createSQL + ";"
+}
+
+func (d *mysqlDialect) getSchemaChanges(orm ORM, schema *entitySchema) (preAlters, alters, postAlters []Alter) {
+	preAlters, alters, postAlters = getMySQLSchemaChanges(orm, schema)
+	fkPreAlters, fkPostAlters := getMySQLForeignKeyChanges(orm, schema)
+	preAlters = append(preAlters, fkPreAlters...)
+	postAlters = append(postAlters, fkPostAlters...)
+	return preAlters, alters, postAlters
+}
+
+func (d *mysqlDialect) skippableAsyncError(rec any) (error, bool) {
+	asMySQLError, isMySQLError := rec.(*mysql.MySQLError)
+	if isMySQLError && slices.Contains(mySQLErrorCodesToSkip, asMySQLError.Number) {
+		return asMySQLError, true
+	}
+	return nil, false
+}
+
+func (d *mysqlDialect) retryableAsyncError(rec any) (error, bool) {
+	asError, isError := rec.(error)
+	if !isError {
+		return nil, false
+	}
+	asMySQLError, isMySQLError := asError.(*mysql.MySQLError)
+	if (isMySQLError && slices.Contains(mySQLErrorCodesToRetry, asMySQLError.Number)) || isConnectionError(asError) {
+		return asError, true
+	}
+	return nil, false
+}
+
+func isTableEmpty(db DB, tableName string) bool {
+	/* #nosec */
+	query := db.GetConfig().getDialect().translateQuery(fmt.Sprintf("SELECT * FROM `%s` LIMIT 1", tableName))
+	rows, err := db.GetDBClient().Query(query)
+	checkError(err)
+	defer func() {
+		_ = rows.Close()
+	}()
+	return !rows.Next()
+}
+
+func tableIndexName(tableName, indexName string) string {
+	return tableName + "_" + indexName
+}
+
+func sortedIndexes(indexes map[string]*IndexSchemaDefinition) []*IndexSchemaDefinition {
+	sorted := make([]*IndexSchemaDefinition, 0, len(indexes))
+	for _, index := range indexes {
+		sorted = append(sorted, index)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
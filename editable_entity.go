@@ -1,6 +1,7 @@
 package beeorm
 
 import (
+	"fmt"
 	"reflect"
 )
 
@@ -10,6 +11,7 @@ const (
 	Insert FlushType = iota
 	Update
 	Delete
+	Upsert
 )
 
 type EntityFlush interface {
@@ -29,6 +31,7 @@ type entityFlushDelete interface {
 	EntityFlush
 	getOldBind() (Bind, error)
 	getValue() reflect.Value
+	isForced() bool
 }
 
 type entityFlushUpdate interface {
@@ -96,6 +99,7 @@ type removableEntity struct {
 	id     uint64
 	value  reflect.Value
 	source any
+	force  bool
 }
 
 func (r *removableEntity) flushType() FlushType {
@@ -110,6 +114,10 @@ func (r *removableEntity) getValue() reflect.Value {
 	return r.value
 }
 
+func (r *removableEntity) isForced() bool {
+	return r.force
+}
+
 type editableEntity struct {
 	writableEntity
 	entity      any
@@ -171,7 +179,15 @@ func NewEntity[E any](orm ORM) *E {
 	return newEntity(orm, getEntitySchema[E](orm)).(*E)
 }
 
-func newEntityInsertable(orm ORM, schema *entitySchema) *insertableEntity {
+func NewEntityWithShardKey[E any](orm ORM, shardKey uint64) *E {
+	schema := getEntitySchema[E](orm)
+	if schema.shardKey == "" {
+		panic(fmt.Errorf("entity '%s' has no shard key", schema.t.String()))
+	}
+	return newEntityInsertable(orm, schema, shardKey).entity.(*E)
+}
+
+func newEntityInsertable(orm ORM, schema *entitySchema, shardKey uint64) *insertableEntity {
 	entity := &insertableEntity{}
 	entity.orm = orm
 	entity.schema = schema
@@ -180,6 +196,10 @@ func newEntityInsertable(orm ORM, schema *entitySchema) *insertableEntity {
 	initNewEntity(elem, schema.fields)
 	entity.entity = value.Interface()
 	id := schema.uuid(orm)
+	if schema.shardKey != "" {
+		id = schema.shardKeyID(id, shardKey)
+		elem.FieldByName(schema.shardKey).SetUint(shardKey)
+	}
 	entity.id = id
 	elem.Field(0).SetUint(id)
 	entity.value = value
@@ -188,7 +208,7 @@ func newEntityInsertable(orm ORM, schema *entitySchema) *insertableEntity {
 }
 
 func newEntity(orm ORM, schema *entitySchema) any {
-	return newEntityInsertable(orm, schema).entity
+	return newEntityInsertable(orm, schema, 0).entity
 }
 
 func DeleteEntity[E any](orm ORM, source E) {
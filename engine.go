@@ -4,6 +4,8 @@ import (
 	"context"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -32,6 +34,8 @@ type Engine interface {
 	Redis(code string) RedisCache
 	Registry() EngineRegistry
 	Option(key string) any
+	NodeID() string
+	Close()
 }
 
 type engineRegistryImplementation struct {
@@ -45,7 +49,12 @@ type engineRegistryImplementation struct {
 	dbTables               map[string]map[string]bool
 	options                map[string]any
 	enums                  map[string][]string
+	localCacheSchemas      map[string]*entitySchema
 	asyncConsumerBlockTime time.Duration
+	asyncRetryPolicy       *AsyncRetryPolicy
+	asyncConsumerMetrics   *asyncConsumerMetrics
+	asyncFlushStreams      bool
+	asyncBufferOptions     *AsyncBufferOptions
 }
 
 type engineImplementation struct {
@@ -55,7 +64,11 @@ type engineImplementation struct {
 	redisServers                 map[string]RedisCache
 	options                      map[string]any
 	pluginFlush                  []PluginInterfaceEntityFlush
-	asyncTemporaryIsQueueRunning bool
+	asyncTemporaryIsQueueRunning atomic.Bool
+	asyncTemporaryQueueStop      func()
+	nodeID                       string
+	closers                      []func()
+	closeOnce                    sync.Once
 }
 
 func (e *engineImplementation) NewORM(context context.Context) ORM {
@@ -66,6 +79,18 @@ func (e *engineImplementation) Registry() EngineRegistry {
 	return e.registry
 }
 
+func (e *engineImplementation) NodeID() string {
+	return e.nodeID
+}
+
+func (e *engineImplementation) Close() {
+	e.closeOnce.Do(func() {
+		for _, closer := range e.closers {
+			closer()
+		}
+	})
+}
+
 func (e *engineImplementation) Option(key string) any {
 	return e.options[key]
 }
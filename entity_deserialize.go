@@ -627,7 +627,7 @@ func deserializeTimeFromDB(f reflect.Value, v string) {
 	if v == zeroTimeAsString {
 		f.SetZero()
 	} else {
-		t, _ := time.ParseInLocation(time.DateTime, v, time.UTC)
+		t := parseTimeFromDB(time.DateTime, v)
 		f.Set(reflect.ValueOf(t))
 	}
 }
@@ -636,11 +636,22 @@ func deserializeDateFromDB(f reflect.Value, v string) {
 	if v == zeroDateAsString {
 		f.SetZero()
 	} else {
-		t, _ := time.ParseInLocation(time.DateOnly, v, time.UTC)
+		t := parseTimeFromDB(time.DateOnly, v)
 		f.Set(reflect.ValueOf(t))
 	}
 }
 
+func parseTimeFromDB(layout, v string) time.Time {
+	t, err := time.ParseInLocation(layout, v, time.UTC)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339Nano, v)
+		if err == nil {
+			t, _ = time.ParseInLocation(layout, t.UTC().Format(layout), time.UTC)
+		}
+	}
+	return t
+}
+
 func deserializeStringFromDB(f reflect.Value, v sql.NullString) {
 	f.SetString(v.String)
 }
@@ -712,7 +723,7 @@ func deserializeTimePointersFromDB(f reflect.Value, v sql.NullString) {
 			f.SetZero()
 			return
 		}
-		t, _ := time.ParseInLocation(time.DateTime, v.String, time.UTC)
+		t := parseTimeFromDB(time.DateTime, v.String)
 		f.Set(reflect.ValueOf(&t))
 		return
 	}
@@ -725,7 +736,7 @@ func deserializeDatePointersFromDB(f reflect.Value, v sql.NullString) {
 			f.SetZero()
 			return
 		}
-		t, _ := time.ParseInLocation(time.DateOnly, v.String, time.UTC)
+		t := parseTimeFromDB(time.DateOnly, v.String)
 		f.Set(reflect.ValueOf(&t))
 		return
 	}
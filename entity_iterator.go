@@ -1,9 +1,7 @@
 package beeorm
 
 import (
-	"fmt"
 	"reflect"
-	"strings"
 )
 
 type EntityIterator[E any] interface {
@@ -73,7 +71,7 @@ func (lc *localCacheIDsIterator[E]) Entity() *E {
 	if lc.index == 0 {
 		value, hit := lc.schema.localCache.getEntity(lc.orm, lc.ids[0])
 		if hit {
-			if value == nil {
+			if value == nil || lc.schema.isSoftDeleted(value) {
 				return nil
 			}
 			return value.(*E)
@@ -88,43 +86,17 @@ func (lc *localCacheIDsIterator[E]) Entity() *E {
 }
 
 func (lc *localCacheIDsIterator[E]) LoadReference(columns ...string) {
-	if lc.Len() <= 1 {
-		return
-	}
-	var ids []uint64
-	for _, row := range columns {
-		fields := strings.Split(row, "/")
-		reference, has := lc.schema.references[fields[0]]
-		if !has {
-			panic(fmt.Errorf("invalid reference name %s", row))
-		}
-		index := lc.index
-		lc.index = -1
-		for lc.Next() {
-			entity := reflect.ValueOf(lc.Entity()).Elem()
-			field := entity.FieldByName(fields[0])
-			id := field.Uint()
-			if id == 0 {
-				continue
-			}
-			has = false
-			for _, before := range ids {
-				if before == id {
-					has = true
-					break
-				}
-			}
-			if !has {
-				ids = append(ids, id)
-			}
-		}
-		lc.index = index
-		if len(ids) <= 1 {
-			return
+	index := lc.index
+	lc.index = -1
+	entities := make([]reflect.Value, 0, lc.Len())
+	for lc.Next() {
+		entity := lc.Entity()
+		if entity != nil {
+			entities = append(entities, reflect.ValueOf(entity).Elem())
 		}
-		refSchema := lc.orm.Engine().Registry().EntitySchema(reference.Type).(*entitySchema)
-		warmup(lc.orm, refSchema, ids)
 	}
+	lc.index = index
+	loadReferences(lc.orm, lc.schema, entities, columns)
 }
 
 func (lc *localCacheIDsIterator[E]) warmup() {
@@ -159,8 +131,10 @@ func (el *emptyResultsIterator[E]) LoadReference(_ ...string) {
 }
 
 type entityIterator[E any] struct {
-	index int
-	rows  []*E
+	orm    *ormImplementation
+	schema *entitySchema
+	index  int
+	rows   []*E
 }
 
 func (ei *entityIterator[E]) Next() bool {
@@ -191,8 +165,14 @@ func (ei *entityIterator[E]) All() []*E {
 	return ei.rows
 }
 
-func (ei *entityIterator[E]) LoadReference(_ ...string) {
-
+func (ei *entityIterator[E]) LoadReference(columns ...string) {
+	entities := make([]reflect.Value, 0, len(ei.rows))
+	for _, row := range ei.rows {
+		if row != nil {
+			entities = append(entities, reflect.ValueOf(row).Elem())
+		}
+	}
+	loadReferences(ei.orm, ei.schema, entities, columns)
 }
 
 type entityAnonymousIterator struct {
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -85,8 +86,16 @@ type fieldGetter func(reflect.Value) any
 type entitySchema struct {
 	index                     uint64
 	tableName                 string
+	tableRenamedFrom          string
+	renamedColumns            map[string]string
+	foreignKeys               []*foreignKeyDefinition
+	foreignKeyChildren        []foreignKeyChild
+	onDeleteChildren          []onDeleteChild
 	archived                  bool
 	mysqlPoolCode             string
+	shardPools                []string
+	shardKey                  string
+	shards                    []*entitySchema
 	t                         reflect.Type
 	tSlice                    reflect.Type
 	fields                    *tableFields
@@ -107,17 +116,24 @@ type entitySchema struct {
 	hasLocalCache             bool
 	localCache                *localCache
 	localCacheLimit           int
+	localCacheTTL             time.Duration
 	redisCacheName            string
 	hasRedisCache             bool
 	redisCache                *redisCache
+	redisCacheTTL             time.Duration
+	versionColumn             string
+	softDeleteColumn          string
+	softDeleteCondition       string
+	softDeleteWithTime        bool
 	cacheKey                  string
 	uuidCacheKey              string
-	uuidMutex                 sync.Mutex
+	uuidMutex                 *sync.Mutex
 	asyncCacheKey             string
 	structureHash             string
 	mapBindToScanPointer      mapBindToScanPointer
 	mapPointerToValue         mapPointerToValue
 	asyncTemporaryQueue       *xsync.MPMCQueueOf[asyncTemporaryQueueEvent]
+	asyncTemporaryQueueLen    *atomic.Int64
 }
 
 type mapBindToScanPointer map[string]func() any
@@ -208,35 +224,54 @@ func (e *entitySchema) GetType() reflect.Type {
 }
 
 func (e *entitySchema) DropTable(orm ORM) {
-	pool := e.GetDB()
-	pool.Exec(orm, fmt.Sprintf("DROP TABLE IF EXISTS `%s`.`%s`;", pool.GetConfig().GetDatabaseName(), e.tableName))
+	for _, shard := range e.getShards() {
+		pool := shard.GetDB()
+		pool.Exec(orm, fmt.Sprintf("DROP TABLE IF EXISTS %s;", pool.GetConfig().getDialect().tableIdentifier(pool.GetConfig(), e.tableName)))
+	}
 }
 
 func (e *entitySchema) TruncateTable(orm ORM) {
-	pool := e.GetDB()
-	if e.archived {
-		_ = pool.Exec(orm, fmt.Sprintf("DROP TABLE `%s`.`%s`", pool.GetConfig().GetDatabaseName(), e.tableName))
-		e.UpdateSchema(orm)
-	} else {
-		_ = pool.Exec(orm, fmt.Sprintf("TRUNCATE TABLE `%s`.`%s`", pool.GetConfig().GetDatabaseName(), e.tableName))
+	for _, shard := range e.getShards() {
+		pool := shard.GetDB()
+		dialect := pool.GetConfig().getDialect()
+		if e.archived {
+			_ = pool.Exec(orm, fmt.Sprintf("DROP TABLE %s", dialect.tableIdentifier(pool.GetConfig(), e.tableName)))
+			shard.updateSchema(orm)
+		} else if len(e.foreignKeyChildren) > 0 {
+			_ = pool.Exec(orm, fmt.Sprintf("DELETE FROM %s", dialect.tableIdentifier(pool.GetConfig(), e.tableName)))
+			_ = pool.Exec(orm, dialect.resetAutoIncrementSQL(pool.GetConfig(), e.tableName))
+		} else {
+			_ = pool.Exec(orm, dialect.truncateTableSQL(pool.GetConfig(), e.tableName))
+		}
 	}
 }
 
 func (e *entitySchema) UpdateSchema(orm ORM) {
+	for _, shard := range e.getShards() {
+		shard.updateSchema(orm)
+	}
+}
+
+func (e *entitySchema) updateSchema(orm ORM) {
 	pool := e.GetDB()
-	alters, has := e.GetSchemaChanges(orm)
-	if has {
-		for _, alter := range alters {
+	pre, alters, post := getSchemaChanges(orm, e)
+	for _, list := range [][]Alter{pre, alters, post} {
+		for _, alter := range list {
 			_ = pool.Exec(orm, alter.SQL)
 		}
 	}
 }
 
 func (e *entitySchema) UpdateSchemaAndTruncateTable(orm ORM) {
-	e.UpdateSchema(orm)
-	pool := e.GetDB()
-	_ = pool.Exec(orm, fmt.Sprintf("DELETE FROM `%s`.`%s`", pool.GetConfig().GetDatabaseName(), e.tableName))
-	_ = pool.Exec(orm, fmt.Sprintf("ALTER TABLE `%s`.`%s` AUTO_INCREMENT = 1", pool.GetConfig().GetDatabaseName(), e.tableName))
+	for _, shard := range e.getShards() {
+		shard.updateSchema(orm)
+		pool := shard.GetDB()
+		_ = pool.Exec(orm, fmt.Sprintf("DELETE FROM %s", pool.GetConfig().getDialect().tableIdentifier(pool.GetConfig(), e.tableName)))
+		resetSQL := pool.GetConfig().getDialect().resetAutoIncrementSQL(pool.GetConfig(), e.tableName)
+		if resetSQL != "" {
+			_ = pool.Exec(orm, resetSQL)
+		}
+	}
 }
 
 func (e *entitySchema) GetDB() DB {
@@ -266,10 +301,13 @@ func (e *entitySchema) GetUniqueIndexes() map[string][]string {
 }
 
 func (e *entitySchema) GetSchemaChanges(orm ORM) (alters []Alter, has bool) {
-	pre, alters, post := getSchemaChanges(orm, e)
-	final := pre
-	final = append(final, alters...)
-	final = append(final, post...)
+	var final []Alter
+	for _, shard := range e.getShards() {
+		pre, alters, post := getSchemaChanges(orm, shard)
+		final = append(final, pre...)
+		final = append(final, alters...)
+		final = append(final, post...)
+	}
 	return final, len(final) > 0
 }
 
@@ -283,11 +321,16 @@ func (e *entitySchema) init(registry *registry, entityType reflect.Type) error {
 	e.mapBindToScanPointer = mapBindToScanPointer{}
 	e.mapPointerToValue = mapPointerToValue{}
 	e.mysqlPoolCode = e.getTag("mysql", "default", DefaultPoolCode)
+	err := e.initShardPools(registry)
+	if err != nil {
+		return err
+	}
 	_, has := registry.mysqlPools[e.mysqlPoolCode]
 	if !has {
 		return fmt.Errorf("mysql pool '%s' not found", e.mysqlPoolCode)
 	}
 	e.tableName = e.getTag("table", entityType.Name(), entityType.Name())
+	e.tableRenamedFrom = e.getTag("renamedFrom", "", "")
 	e.archived = e.getTag("archived", "true", "") == "true"
 	e.cacheAll = e.getTag("cacheAll", "true", "") == "true"
 	redisCacheName := e.getTag("redisCache", DefaultPoolCode, "")
@@ -301,7 +344,11 @@ func (e *entitySchema) init(registry *registry, entityType reflect.Type) error {
 	if e.mysqlPoolCode != DefaultPoolCode {
 		cacheKey = e.mysqlPoolCode
 	}
-	cacheKey += e.tableName
+	if e.tableRenamedFrom != "" {
+		cacheKey += e.tableRenamedFrom
+	} else {
+		cacheKey += e.tableName
+	}
 	uniqueIndices := make(map[string]map[int]string)
 	indices := make(map[string]map[int]string)
 	uniqueGlobal := e.getTag("unique", "", "")
@@ -360,7 +407,11 @@ func (e *entitySchema) init(registry *registry, entityType reflect.Type) error {
 	for i, name := range e.columnNames {
 		columnMapping[name] = i
 	}
-	cacheKey = hashString(cacheKey + e.fieldsQuery)
+	err = e.initRenamedColumns()
+	if err != nil {
+		return err
+	}
+	cacheKey = hashString(cacheKey + e.getCacheFieldsQuery())
 	e.uuidCacheKey = cacheKey[0:12]
 	cacheKey = cacheKey[0:5]
 	h := fnv.New32a()
@@ -377,8 +428,24 @@ func (e *entitySchema) init(registry *registry, entityType reflect.Type) error {
 		e.hasLocalCache = true
 		e.localCacheLimit = localCacheLimitAsInt
 	}
+	localCacheTTL := e.getTag("localCacheTTL", "", "")
+	if localCacheTTL != "" {
+		ttl, err := time.ParseDuration(localCacheTTL)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid local cache TTL '%s'", localCacheTTL)
+		}
+		e.localCacheTTL = ttl
+	}
 	e.redisCacheName = redisCacheName
 	e.hasRedisCache = redisCacheName != ""
+	redisCacheTTL := e.getTag("redisCacheTTL", "", "")
+	if redisCacheTTL != "" {
+		ttl, err := time.ParseDuration(redisCacheTTL)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid redis cache TTL '%s'", redisCacheTTL)
+		}
+		e.redisCacheTTL = ttl
+	}
 	e.cacheKey = cacheKey
 	e.asyncCacheKey = flushAsyncEventsList
 	asyncGroup := e.getTag("split_async_flush", "true", "")
@@ -387,7 +454,9 @@ func (e *entitySchema) init(registry *registry, entityType reflect.Type) error {
 	} else if asyncGroup != "" {
 		e.asyncCacheKey = asyncGroup
 	}
-	e.asyncTemporaryQueue = xsync.NewMPMCQueueOf[asyncTemporaryQueueEvent](10000)
+	e.asyncTemporaryQueue = newAsyncTemporaryQueue(e.engine.Registry().(*engineRegistryImplementation).asyncBufferOptions)
+	e.asyncTemporaryQueueLen = &atomic.Int64{}
+	e.uuidMutex = &sync.Mutex{}
 	e.uniqueIndices = make(map[string][]string)
 	for name, index := range uniqueIndices {
 		e.uniqueIndices[name] = make([]string, len(index))
@@ -395,7 +464,15 @@ func (e *entitySchema) init(registry *registry, entityType reflect.Type) error {
 			e.uniqueIndices[name][i-1] = index[i]
 		}
 	}
-	err := e.validateIndexes(uniqueIndices, indices)
+	err = e.validateIndexes(uniqueIndices, indices)
+	if err != nil {
+		return err
+	}
+	err = e.initVersionColumn()
+	if err != nil {
+		return err
+	}
+	err = e.initSoftDelete()
 	if err != nil {
 		return err
 	}
@@ -411,6 +488,28 @@ func (e *entitySchema) init(registry *registry, entityType reflect.Type) error {
 	return nil
 }
 
+func (e *entitySchema) initVersionColumn() error {
+	for field, tags := range e.tags {
+		if tags["version"] != "true" {
+			continue
+		}
+		if e.versionColumn != "" {
+			return fmt.Errorf("only one version field is allowed, found '%s' and '%s'", e.versionColumn, field)
+		}
+		structField, has := e.t.FieldByName(field)
+		if !has || len(structField.Index) > 1 {
+			return fmt.Errorf("version field '%s' must be defined in main struct", field)
+		}
+		switch structField.Type.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return fmt.Errorf("version field '%s' must be unsigned integer", field)
+		}
+		e.versionColumn = field
+	}
+	return nil
+}
+
 func (e *entitySchema) validateIndexes(uniqueIndices map[string]map[int]string, indices map[string]map[int]string) error {
 	all := make(map[string]map[int]string)
 	for k, v := range uniqueIndices {
@@ -478,6 +577,9 @@ func (e *entitySchema) uuid(orm ORM) uint64 {
 		e.initUUID(orm)
 		return e.uuid(orm)
 	}
+	if len(e.shards) > 0 {
+		return e.shardedID(uint64(id))
+	}
 	return uint64(id)
 }
 
@@ -500,7 +602,16 @@ func (e *entitySchema) initUUID(orm ORM) {
 		return
 	}
 	maxID := int64(0)
-	e.GetDB().QueryRow(orm, NewWhere("SELECT IFNULL(MAX(ID), 0) FROM `"+e.GetTableName()+"`"), &maxID)
+	for _, shard := range e.getShards() {
+		shardMaxID := int64(0)
+		shard.GetDB().Primary().QueryRow(orm, NewWhere("SELECT IFNULL(MAX(`ID`), 0) FROM `"+e.GetTableName()+"`"), &shardMaxID)
+		if shardMaxID > maxID {
+			maxID = shardMaxID
+		}
+	}
+	if len(e.shards) > 0 {
+		maxID = maxID/int64(len(e.shards)) + 1
+	}
 	if maxID == 0 {
 		maxID = 1
 	}
@@ -526,6 +637,13 @@ func (e *entitySchema) DisableCache(local, redis bool) {
 		e.redisCacheName = ""
 		e.hasRedisCache = false
 	}
+	for _, shard := range e.shards {
+		if shard != e {
+			shard.hasLocalCache = e.hasLocalCache
+			shard.redisCacheName = e.redisCacheName
+			shard.hasRedisCache = e.hasRedisCache
+		}
+	}
 }
 
 func (e *entitySchema) NewEntity(orm ORM) any {
@@ -562,7 +680,7 @@ func (e *entitySchema) IsDirty(orm ORM, id uint64) (oldValues, newValues Bind, h
 
 func (e *entitySchema) Copy(orm ORM, source any) any {
 	schema := orm.Engine().Registry().EntitySchema(e.t).(*entitySchema)
-	insertable := newEntityInsertable(orm, schema)
+	insertable := newEntityInsertable(orm, schema, schema.getShardKey(reflect.ValueOf(source).Elem()))
 	copyEntity(reflect.ValueOf(source).Elem(), insertable.value.Elem(), schema.fields, false)
 	return insertable.entity
 }
@@ -593,19 +711,28 @@ func (e *entitySchema) DeleteEntity(orm ORM, source any) {
 func (e *entitySchema) search(orm ORM, where Where, pager *Pager, withCount bool) (results EntityAnonymousIterator, totalRows int) {
 	schema := orm.Engine().Registry().EntitySchema(e.t).(*entitySchema)
 	entities := reflect.New(reflect.SliceOf(reflect.PtrTo(e.t))).Elem()
-	if schema.hasLocalCache {
+	if schema.hasLocalCache && !isWithDeleted(where) {
 		ids, total := searchIDs(orm, schema, where, pager, withCount)
 		if total == 0 {
 			return emptyResultsAnonymousIteratorInstance, 0
 		}
 		return &localCacheIDsAnonymousIterator{c: orm.(*ormImplementation), schema: schema, ids: ids, index: -1}, total
 	}
+	shards := schema.getWhereShards(where)
+	if len(shards) > 1 {
+		rows, total := searchShards(orm, schema, shards, where, pager, withCount, false)
+		for _, row := range rows {
+			entities = reflect.Append(entities, row.value)
+		}
+		return &entityAnonymousIterator{index: -1, rows: entities}, total
+	}
+	where = schema.applySoftDelete(where)
 	whereQuery := where.String()
 	query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.GetTableName() + "` WHERE " + whereQuery
 	if pager != nil {
 		query += " " + pager.String()
 	}
-	pool := schema.GetDB()
+	pool := shards[0].GetDB()
 	queryResults, def := pool.Query(orm, query, where.GetParameters()...)
 	defer def()
 
@@ -621,7 +748,7 @@ func (e *entitySchema) search(orm ORM, where Where, pager *Pager, withCount bool
 	def()
 	totalRows = i
 	if pager != nil {
-		totalRows = getTotalRows(orm, withCount, pager, where, schema, i)
+		totalRows = getTotalRows(orm, withCount, pager, where, shards[0], i)
 	}
 	resultsIterator := &entityAnonymousIterator{index: -1}
 	resultsIterator.rows = entities
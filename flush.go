@@ -1,6 +1,8 @@
 package beeorm
 
 import (
+	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +18,11 @@ type sqlOperations map[DB]schemaSQLOperations
 type dbAction func(db DBBase)
 type PostFlushAction func(orm ORM)
 
+type flushEntityUpdate struct {
+	apply  func()
+	revert func()
+}
+
 func (orm *ormImplementation) Flush() error {
 	return orm.flush(false)
 }
@@ -27,10 +34,36 @@ func (orm *ormImplementation) FlushAsync() error {
 func (orm *ormImplementation) flush(async bool) error {
 	orm.mutexFlush.Lock()
 	defer orm.mutexFlush.Unlock()
-	if orm.trackedEntities == nil || orm.trackedEntities.Size() == 0 {
+	if (orm.trackedEntities == nil || orm.trackedEntities.Size() == 0) && len(orm.bulkOperations) == 0 {
 		return nil
 	}
+	orm.initTrackedEntities()
+	err := orm.applyOnDeleteReferences()
+	if err != nil {
+		return err
+	}
+	err = orm.checkShardKeys()
+	if err != nil {
+		return err
+	}
 	sqlGroup := orm.groupSQLOperations()
+	if async {
+		err := orm.checkAsyncVersions(sqlGroup)
+		if err != nil {
+			return err
+		}
+		err = orm.checkAsyncForeignKeys(sqlGroup)
+		if err != nil {
+			return err
+		}
+		err = orm.checkAsyncBuffer(sqlGroup)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			orm.asyncEvents = nil
+		}()
+	}
 	for _, operations := range sqlGroup {
 		for schema, queryOperations := range operations {
 			deletes, has := queryOperations[Delete]
@@ -42,7 +75,14 @@ func (orm *ormImplementation) flush(async bool) error {
 			}
 			inserts, has := queryOperations[Insert]
 			if has {
-				err := orm.handleInserts(async, schema, inserts)
+				err := orm.handleInserts(async, schema, inserts, "")
+				if err != nil {
+					return err
+				}
+			}
+			upserts, has := queryOperations[Upsert]
+			if has {
+				err := orm.handleUpserts(async, schema, upserts)
 				if err != nil {
 					return err
 				}
@@ -56,79 +96,175 @@ func (orm *ormImplementation) flush(async bool) error {
 			}
 		}
 	}
+	for _, operation := range orm.bulkOperations {
+		orm.handleBulkOperation(async, operation)
+	}
 	if !async {
-		func() {
-			var transactions []DBTransaction
-			defer func() {
-				for _, tx := range transactions {
-					tx.Rollback(orm)
-				}
-			}()
-			for code, actions := range orm.flushDBActions {
-				var d DBBase
-				d = orm.Engine().DB(code)
-				if len(actions) > 1 || len(orm.flushDBActions) > 1 {
-					tx := d.(DB).Begin(orm)
-					transactions = append(transactions, tx)
-					d = tx
-				}
-				for _, action := range actions {
-					action(d)
-				}
-			}
-			for _, tx := range transactions {
-				tx.Commit(orm)
-			}
-		}()
+		err := orm.executeDBActions()
+		if err != nil {
+			lockErr := err.(*OptimisticLockError)
+			if lockErr.schema.hasLocalCache {
+				lockErr.schema.localCache.removeEntity(orm, lockErr.ID)
+			}
+			if lockErr.schema.hasRedisCache {
+				lockErr.schema.redisCache.Del(orm, lockErr.schema.getCacheKey()+":"+strconv.FormatUint(lockErr.ID, 10))
+			}
+			orm.trackedEntities.Clear()
+			orm.flushDBActions = nil
+			orm.flushPostActions = orm.flushPostActions[0:0]
+			orm.flushEntityUpdates = nil
+			orm.redisPipeLines = nil
+			orm.localCacheInvalidations = nil
+			orm.bulkOperations = nil
+			return err
+		}
 	}
-	for _, pipeline := range orm.redisPipeLines {
-		pipeline.Exec(orm)
+	for _, update := range orm.flushEntityUpdates {
+		update.apply()
 	}
-	for _, action := range orm.flushPostActions {
-		action(orm)
+	if async {
+		orm.publishAsyncLocalCacheInvalidations()
+	}
+	if len(orm.asyncEvents) > 0 {
+		orm.publishAsyncEvents(orm.asyncEvents)
+	}
+	orm.publishLocalCacheInvalidations()
+	if orm.transaction != nil {
+		orm.deferFlushSideEffects()
+	} else {
+		for _, pipeline := range orm.redisPipeLines {
+			pipeline.Exec(orm)
+		}
+		for _, action := range orm.flushPostActions {
+			action(orm)
+		}
 	}
 	orm.trackedEntities.Clear()
 	orm.flushDBActions = nil
 	orm.flushPostActions = orm.flushPostActions[0:0]
+	orm.flushEntityUpdates = nil
 	orm.redisPipeLines = nil
+	orm.bulkOperations = nil
+	return nil
+}
+
+func (orm *ormImplementation) checkAsyncVersions(sqlGroup sqlOperations) error {
+	for _, operations := range sqlGroup {
+		for schema, queryOperations := range operations {
+			if schema.versionColumn != "" && len(queryOperations[Update]) > 0 {
+				return fmt.Errorf("entity '%s' with version field can't be updated with FlushAsync", schema.t.String())
+			}
+		}
+	}
+	return nil
+}
+
+func (orm *ormImplementation) checkAsyncForeignKeys(sqlGroup sqlOperations) error {
+	for _, operations := range sqlGroup {
+		for schema, queryOperations := range operations {
+			if !schema.hasForeignKeyChildrenToInvalidate() {
+				continue
+			}
+			for _, operation := range queryOperations[Delete] {
+				if isHardDelete(operation) {
+					return fmt.Errorf("entity '%s' referenced by foreign keys can't be deleted with FlushAsync", schema.t.String())
+				}
+			}
+		}
+	}
+	for _, operation := range orm.bulkOperations {
+		schema := operation.schema
+		if operation.bind == nil && schema.softDeleteColumn == "" && schema.hasForeignKeyChildrenToInvalidate() {
+			return fmt.Errorf("entity '%s' referenced by foreign keys can't be deleted with FlushAsync", schema.t.String())
+		}
+	}
+	return nil
+}
+
+func (orm *ormImplementation) executeDBActions() (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			lockErr, isLockErr := rec.(*OptimisticLockError)
+			if !isLockErr {
+				panic(rec)
+			}
+			err = lockErr
+		}
+	}()
+	if orm.transaction != nil {
+		for code, actions := range orm.flushDBActions {
+			d := orm.getDBTransaction(orm.engine.DB(code).(*dbImplementation), true)
+			for _, action := range actions {
+				action(d)
+			}
+		}
+		return nil
+	}
+	var transactions []DBTransaction
+	defer func() {
+		for _, tx := range transactions {
+			tx.Rollback(orm)
+		}
+	}()
+	for code, actions := range orm.flushDBActions {
+		var d DBBase
+		d = orm.Engine().DB(code)
+		if len(actions) > 1 || len(orm.flushDBActions) > 1 || len(orm.bulkOperations) > 0 {
+			tx := d.(DB).Begin(orm)
+			transactions = append(transactions, tx)
+			d = tx
+		}
+		for _, action := range actions {
+			action(d)
+		}
+	}
+	for _, tx := range transactions {
+		tx.Commit(orm)
+	}
 	return nil
 }
 
 func (orm *ormImplementation) ClearFlush() {
 	orm.mutexFlush.Lock()
 	defer orm.mutexFlush.Unlock()
+	orm.initTrackedEntities()
 	orm.trackedEntities.Clear()
 	orm.flushDBActions = nil
 	orm.flushPostActions = orm.flushPostActions[0:0]
+	orm.flushEntityUpdates = nil
 	orm.redisPipeLines = nil
+	orm.localCacheInvalidations = nil
+	orm.bulkOperations = nil
 }
 
 func (orm *ormImplementation) handleDeletes(async bool, schema *entitySchema, operations []EntityFlush) error {
-	var args []any
-	if !async {
-		args = make([]any, len(operations))
-	}
-	sql := "DELETE FROM `" + schema.GetTableName() + "` WHERE ID IN ("
-	if async {
+	if schema.softDeleteColumn == "" {
+		ids := make([]uint64, len(operations))
 		for i, operation := range operations {
-			if i > 0 {
-				sql += ","
-			}
-			sql += strconv.FormatUint(operation.ID(), 10)
+			ids[i] = operation.ID()
 		}
+		orm.appendDeleteQuery(async, schema, schema.getDeleteQuery(), nil, ids)
+		orm.invalidateForeignKeyChildren(schema, ids, make(map[*entitySchema]map[uint64]bool))
 	} else {
-		sql += "?" + strings.Repeat(",?", len(operations)-1)
-	}
-	sql += ")"
-	if !async {
-		for i, operation := range operations {
-			args[i] = operation.ID()
+		var softDeletes, forcedDeletes []uint64
+		value := schema.getSoftDeleteBindValue()
+		fSetter := schema.fieldSetters[schema.softDeleteColumn]
+		for _, operation := range operations {
+			deleteFlush := operation.(entityFlushDelete)
+			if deleteFlush.isForced() {
+				forcedDeletes = append(forcedDeletes, operation.ID())
+			} else {
+				softDeletes = append(softDeletes, operation.ID())
+				fSetter(value, deleteFlush.getValue())
+			}
+		}
+		if len(forcedDeletes) > 0 {
+			orm.appendDeleteQuery(async, schema, schema.getDeleteQuery(), nil, forcedDeletes)
+			orm.invalidateForeignKeyChildren(schema, forcedDeletes, make(map[*entitySchema]map[uint64]bool))
+		}
+		if len(softDeletes) > 0 {
+			orm.appendDeleteQuery(async, schema, schema.getSoftDeleteQuery(), value, softDeletes)
 		}
-		orm.appendDBAction(schema, func(db DBBase) {
-			db.Exec(orm, sql, args...)
-		})
-	} else {
-		publishAsyncEvent(schema, []any{sql})
 	}
 
 	lc, hasLocalCache := schema.GetLocalCache()
@@ -155,12 +291,16 @@ func (orm *ormImplementation) handleDeletes(async bool, schema *entitySchema, op
 			orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
 				lc.setEntity(orm, operation.ID(), nil)
 			})
+			orm.invalidateLocalCacheEntity(schema, operation.ID())
 		}
 		rc, hasRedisCache := schema.GetRedisCache()
 		if hasRedisCache {
 			cacheKey := schema.getCacheKey() + ":" + strconv.FormatUint(operation.ID(), 10)
 			orm.RedisPipeLine(rc.GetCode()).Del(cacheKey)
 			orm.RedisPipeLine(rc.GetCode()).LPush(cacheKey, "")
+			if schema.redisCacheTTL > 0 {
+				orm.RedisPipeLine(rc.GetCode()).Expire(cacheKey, schema.redisCacheTTL)
+			}
 		}
 		for columnName := range schema.cachedReferences {
 			if bind == nil {
@@ -178,6 +318,7 @@ func (orm *ormImplementation) handleDeletes(async bool, schema *entitySchema, op
 				orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
 					lc.removeReference(orm, refColumn, id.(uint64))
 				})
+				orm.invalidateLocalCacheReference(schema, refColumn, id.(uint64))
 			}
 			idAsString := strconv.FormatUint(id.(uint64), 10)
 			redisSetKey := schema.cacheKey + ":" + refColumn + ":" + idAsString
@@ -188,6 +329,7 @@ func (orm *ormImplementation) handleDeletes(async bool, schema *entitySchema, op
 				orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
 					lc.removeReference(orm, cacheAllFakeReferenceKey, 0)
 				})
+				orm.invalidateLocalCacheReference(schema, cacheAllFakeReferenceKey, 0)
 			}
 			redisSetKey := schema.cacheKey + ":" + cacheAllFakeReferenceKey
 			orm.RedisPipeLine(schema.getForcedRedisCode()).SRem(redisSetKey, strconv.FormatUint(deleteFlush.ID(), 10))
@@ -195,7 +337,7 @@ func (orm *ormImplementation) handleDeletes(async bool, schema *entitySchema, op
 		logTableSchema, hasLogTable := orm.engine.registry.entityLogSchemas[schema.t]
 		if hasLogTable {
 			data := make([]any, 6)
-			data[0] = "INSERT INTO `" + logTableSchema.tableName + "`(ID,EntityID,Date,Meta,`Before`) VALUES(?,?,?,?,?)"
+			data[0] = "INSERT INTO `" + logTableSchema.tableName + "`(`ID`,`EntityID`,`Date`,`Meta`,`Before`) VALUES(?,?,?,?,?)"
 			data[1] = strconv.FormatUint(logTableSchema.uuid(orm), 10)
 			data[2] = strconv.FormatUint(operation.ID(), 10)
 			data[3] = time.Now().Format(time.DateTime)
@@ -213,7 +355,7 @@ func (orm *ormImplementation) handleDeletes(async bool, schema *entitySchema, op
 			}
 			asJSON, _ := jsoniter.ConfigFastest.MarshalToString(bind)
 			data[5] = asJSON
-			publishAsyncEvent(logTableSchema, data)
+			orm.publishAsyncEvent(logTableSchema, data)
 		}
 		for _, p := range orm.engine.pluginFlush {
 			if bind == nil {
@@ -234,7 +376,41 @@ func (orm *ormImplementation) handleDeletes(async bool, schema *entitySchema, op
 	return nil
 }
 
-func (orm *ormImplementation) handleInserts(async bool, schema *entitySchema, operations []EntityFlush) error {
+func (orm *ormImplementation) appendDeleteQuery(async bool, schema *entitySchema, query string, value any, ids []uint64) {
+	var args []any
+	if !async {
+		args = make([]any, 0, len(ids)+1)
+		if value != nil {
+			args = append(args, value)
+		}
+	}
+	sql := query + " WHERE `ID` IN ("
+	if async {
+		for i, id := range ids {
+			if i > 0 {
+				sql += ","
+			}
+			sql += strconv.FormatUint(id, 10)
+		}
+	} else {
+		sql += "?" + strings.Repeat(",?", len(ids)-1)
+	}
+	sql += ")"
+	if !async {
+		for _, id := range ids {
+			args = append(args, id)
+		}
+		orm.appendDBAction(schema, func(db DBBase) {
+			db.Exec(orm, sql, args...)
+		})
+	} else if value != nil {
+		orm.publishAsyncEvent(schema, []any{sql, value})
+	} else {
+		orm.publishAsyncEvent(schema, []any{sql})
+	}
+}
+
+func (orm *ormImplementation) handleInserts(async bool, schema *entitySchema, operations []EntityFlush, onDuplicate string) error {
 	columns := schema.GetColumns()
 	sql := "INSERT INTO `" + schema.GetTableName() + "`(`ID`"
 	for _, column := range columns[1:] {
@@ -275,7 +451,7 @@ func (orm *ormImplementation) handleInserts(async bool, schema *entitySchema, op
 					continue
 				}
 				previousID, inUse := cache.HGet(orm, hSetKey, hField)
-				if inUse {
+				if inUse && onDuplicate == "" {
 					idAsUint, _ := strconv.ParseUint(previousID, 10, 64)
 					return &DuplicatedKeyBindError{Index: indexName, ID: idAsUint, Columns: indexColumns}
 				}
@@ -323,13 +499,13 @@ func (orm *ormImplementation) handleInserts(async bool, schema *entitySchema, op
 			sql += ")"
 		}
 		if async {
-			asyncData[0] = sql
-			publishAsyncEvent(schema, asyncData)
+			asyncData[0] = sql + onDuplicate
+			orm.publishAsyncEvent(schema, asyncData)
 		}
 		logTableSchema, hasLogTable := orm.engine.registry.entityLogSchemas[schema.t]
 		if hasLogTable {
 			data := make([]any, 6)
-			data[0] = "INSERT INTO `" + logTableSchema.tableName + "`(ID,EntityID,Date,Meta,`After`) VALUES(?,?,?,?,?)"
+			data[0] = "INSERT INTO `" + logTableSchema.tableName + "`(`ID`,`EntityID`,`Date`,`Meta`,`After`) VALUES(?,?,?,?,?)"
 			data[1] = strconv.FormatUint(logTableSchema.uuid(orm), 10)
 			data[2] = strconv.FormatUint(bind["ID"].(uint64), 10)
 			data[3] = time.Now().Format(time.DateTime)
@@ -341,12 +517,13 @@ func (orm *ormImplementation) handleInserts(async bool, schema *entitySchema, op
 			}
 			asJSON, _ := jsoniter.ConfigFastest.MarshalToString(bind)
 			data[5] = asJSON
-			publishAsyncEvent(logTableSchema, data)
+			orm.publishAsyncEvent(logTableSchema, data)
 		}
 		if hasLocalCache {
 			orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
 				lc.setEntity(orm, insert.ID(), insert.getEntity())
 			})
+			orm.invalidateLocalCacheEntity(schema, insert.ID())
 		}
 		for columnName := range schema.cachedReferences {
 			id := bind[columnName]
@@ -358,6 +535,7 @@ func (orm *ormImplementation) handleInserts(async bool, schema *entitySchema, op
 				orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
 					lc.removeReference(orm, refColumn, id.(uint64))
 				})
+				orm.invalidateLocalCacheReference(schema, refColumn, id.(uint64))
 			}
 			redisSetKey := schema.cacheKey + ":" + refColumn + ":" + strconv.FormatUint(id.(uint64), 10)
 			orm.RedisPipeLine(schema.getForcedRedisCode()).SAdd(redisSetKey, strconv.FormatUint(insert.ID(), 10))
@@ -367,18 +545,30 @@ func (orm *ormImplementation) handleInserts(async bool, schema *entitySchema, op
 				orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
 					lc.removeReference(orm, cacheAllFakeReferenceKey, 0)
 				})
+				orm.invalidateLocalCacheReference(schema, cacheAllFakeReferenceKey, 0)
 			}
 			redisSetKey := schema.cacheKey + ":" + cacheAllFakeReferenceKey
 			orm.RedisPipeLine(schema.getForcedRedisCode()).SAdd(redisSetKey, strconv.FormatUint(insert.ID(), 10))
 		}
 		if hasRedisCache {
 			idAsString := strconv.FormatUint(bind["ID"].(uint64), 10)
-			orm.RedisPipeLine(rc.GetCode()).RPush(schema.getCacheKey()+":"+idAsString, convertBindToRedisValue(bind, schema)...)
+			cacheKey := schema.getCacheKey() + ":" + idAsString
+			orm.RedisPipeLine(rc.GetCode()).RPush(cacheKey, convertBindToRedisValue(bind, schema)...)
+			if schema.redisCacheTTL > 0 {
+				orm.RedisPipeLine(rc.GetCode()).Expire(cacheKey, schema.redisCacheTTL)
+			}
 		}
 	}
 	if !async {
 		orm.appendDBAction(schema, func(db DBBase) {
-			db.Exec(orm, sql, args...)
+			if onDuplicate != "" && schema.GetDB().GetConfig().getDialect().upsertReturnsID() {
+				orm.executeUpsertReturningID(db, schema, operations[0].(entityFlushInsert), sql+onDuplicate, args)
+				return
+			}
+			res := db.Exec(orm, sql+onDuplicate, args...)
+			if onDuplicate != "" && res.RowsAffected() != 1 {
+				orm.handleUpsertConflict(schema, operations[0].(entityFlushInsert), res.LastInsertId())
+			}
 		})
 	}
 
@@ -397,6 +587,13 @@ func (orm *ormImplementation) handleUpdates(async bool, schema *entitySchema, op
 		if len(newBind) == 0 {
 			continue
 		}
+		hasVersion := schema.versionColumn != ""
+		var version uint64
+		if hasVersion {
+			version = update.getSourceValue().Elem().FieldByName(schema.versionColumn).Uint()
+			oldBind[schema.versionColumn] = version
+			newBind[schema.versionColumn] = version + 1
+		}
 		if len(orm.engine.pluginFlush) > 0 {
 			for _, p := range orm.engine.pluginFlush {
 				after, err := p.EntityFlush(schema, elem, oldBind, newBind, orm.engine)
@@ -447,10 +644,14 @@ func (orm *ormImplementation) handleUpdates(async bool, schema *entitySchema, op
 		k := 0
 		var args []any
 		var asyncArgs []any
+		argsLength := len(newBind) + 1
+		if hasVersion {
+			argsLength++
+		}
 		if async {
-			asyncArgs = make([]any, len(newBind)+2)
+			asyncArgs = make([]any, argsLength+1)
 		} else {
-			args = make([]any, len(newBind)+1)
+			args = make([]any, argsLength)
 		}
 		for column, value := range newBind {
 			if k > 0 {
@@ -468,25 +669,43 @@ func (orm *ormImplementation) handleUpdates(async bool, schema *entitySchema, op
 			}
 			k++
 		}
-		sql += " WHERE ID = ?"
+		sql += " WHERE `ID` = ?"
 		if async {
 			asyncArgs[k+1] = strconv.FormatUint(update.ID(), 10)
 		} else {
 			args[k] = update.ID()
 		}
+		if hasVersion {
+			sql += " AND `" + schema.versionColumn + "` = ?"
+			if async {
+				asyncArgs[k+2] = strconv.FormatUint(version, 10)
+			} else {
+				args[k+1] = version
+			}
+		}
 		if async {
 			asyncArgs[0] = sql
-			publishAsyncEvent(schema, asyncArgs)
+			orm.publishAsyncEvent(schema, asyncArgs)
 		} else {
+			id := update.ID()
 			orm.appendDBAction(schema, func(db DBBase) {
-				db.Exec(orm, sql, args...)
+				res := db.Exec(orm, sql, args...)
+				if hasVersion && res.RowsAffected() == 0 {
+					panic(&OptimisticLockError{EntityName: schema.t.String(), ID: id, Version: version, schema: schema})
+				}
+			})
+		}
+		if hasVersion || update.getEntity() == nil {
+			orm.flushEntityUpdates = append(orm.flushEntityUpdates, flushEntityUpdate{
+				apply:  newEntityUpdateSetter(schema, elem, update.getEntity() == nil, newBind),
+				revert: newEntityUpdateSetter(schema, elem, update.getEntity() == nil, oldBind),
 			})
 		}
 
 		logTableSchema, hasLogTable := orm.engine.registry.entityLogSchemas[schema.t]
 		if hasLogTable {
 			data := make([]any, 7)
-			data[0] = "INSERT INTO `" + logTableSchema.tableName + "`(ID,EntityID,Date,Meta,`Before`,`After`) VALUES(?,?,?,?,?,?)"
+			data[0] = "INSERT INTO `" + logTableSchema.tableName + "`(`ID`,`EntityID`,`Date`,`Meta`,`Before`,`After`) VALUES(?,?,?,?,?,?)"
 			data[1] = strconv.FormatUint(logTableSchema.uuid(orm), 10)
 			data[2] = strconv.FormatUint(update.ID(), 10)
 			data[3] = time.Now().Format(time.DateTime)
@@ -500,23 +719,13 @@ func (orm *ormImplementation) handleUpdates(async bool, schema *entitySchema, op
 			data[5] = asJSON
 			asJSON, _ = jsoniter.ConfigFastest.MarshalToString(newBind)
 			data[6] = asJSON
-			publishAsyncEvent(logTableSchema, data)
+			orm.publishAsyncEvent(logTableSchema, data)
 		}
 
-		if update.getEntity() == nil {
-			for field, newValue := range newBind {
-				fSetter := schema.fieldSetters[field]
-				if schema.hasLocalCache {
-					func() {
-						schema.localCache.mutex.Lock()
-						defer schema.localCache.mutex.Unlock()
-						fSetter(newValue, elem)
-					}()
-				} else {
-					fSetter(newValue, elem)
-				}
-			}
-		} else if schema.hasLocalCache {
+		if schema.hasLocalCache {
+			orm.invalidateLocalCacheEntity(schema, update.ID())
+		}
+		if update.getEntity() != nil && schema.hasLocalCache {
 			orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
 				sourceValue := update.getSourceValue()
 				func() {
@@ -531,9 +740,13 @@ func (orm *ormImplementation) handleUpdates(async bool, schema *entitySchema, op
 		if schema.hasRedisCache {
 			p := orm.RedisPipeLine(schema.redisCache.GetCode())
 			rKey := schema.getCacheKey() + ":" + strconv.FormatUint(update.ID(), 10)
-			for column, val := range newBind {
-				index := int64(schema.columnMapping[column] + 1)
-				p.LSet(rKey, index, convertBindValueToRedisValue(val))
+			if schema.redisCacheTTL > 0 {
+				p.Del(rKey)
+			} else {
+				for column, val := range newBind {
+					index := int64(schema.columnMapping[column] + 1)
+					p.LSet(rKey, index, convertBindValueToRedisValue(val))
+				}
 			}
 		}
 		for columnName := range schema.cachedReferences {
@@ -557,6 +770,7 @@ func (orm *ormImplementation) handleUpdates(async bool, schema *entitySchema, op
 					orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
 						schema.localCache.removeReference(orm, refColumn, oldAsInt)
 					})
+					orm.invalidateLocalCacheReference(schema, refColumn, oldAsInt)
 				}
 				redisSetKey := schema.cacheKey + ":" + refColumn + ":" + strconv.FormatUint(oldAsInt, 10)
 				orm.RedisPipeLine(schema.getForcedRedisCode()).SRem(redisSetKey, strconv.FormatUint(update.ID(), 10))
@@ -566,6 +780,7 @@ func (orm *ormImplementation) handleUpdates(async bool, schema *entitySchema, op
 					orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
 						schema.localCache.removeReference(orm, refColumn, newAsInt)
 					})
+					orm.invalidateLocalCacheReference(schema, refColumn, newAsInt)
 				}
 				redisSetKey := schema.cacheKey + ":" + refColumn + ":" + strconv.FormatUint(newAsInt, 10)
 				orm.RedisPipeLine(schema.getForcedRedisCode()).SAdd(redisSetKey, strconv.FormatUint(update.ID(), 10))
@@ -575,11 +790,31 @@ func (orm *ormImplementation) handleUpdates(async bool, schema *entitySchema, op
 	return nil
 }
 
+func newEntityUpdateSetter(schema *entitySchema, elem reflect.Value, fields bool, bind Bind) func() {
+	return func() {
+		if schema.hasLocalCache {
+			schema.localCache.mutex.Lock()
+			defer schema.localCache.mutex.Unlock()
+		}
+		if schema.versionColumn != "" {
+			elem.FieldByName(schema.versionColumn).SetUint(bind[schema.versionColumn].(uint64))
+		}
+		if !fields {
+			return
+		}
+		for field, value := range bind {
+			if field != schema.versionColumn {
+				schema.fieldSetters[field](value, elem)
+			}
+		}
+	}
+}
+
 func (orm *ormImplementation) groupSQLOperations() sqlOperations {
 	sqlGroup := make(sqlOperations)
 	orm.trackedEntities.Range(func(_ uint64, value *xsync.MapOf[uint64, EntityFlush]) bool {
 		value.Range(func(_ uint64, flush EntityFlush) bool {
-			schema := flush.Schema()
+			schema := flush.Schema().getShard(flush.ID())
 			db := orm.engine.DB(schema.mysqlPoolCode)
 			poolSQLGroup, has := sqlGroup[db]
 			if !has {
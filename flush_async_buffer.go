@@ -1,34 +1,165 @@
 package beeorm
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/puzpuzpuz/xsync/v2"
 )
 
 const redisRPushPackSize = 1000
+const asyncBufferDefaultSize = 10000
+
+type AsyncBufferMode int
+
+const (
+	AsyncBufferMemory AsyncBufferMode = iota
+	AsyncBufferDirect
+)
+
+type AsyncBufferOverflow int
+
+const (
+	AsyncBufferOverflowBlock AsyncBufferOverflow = iota
+	AsyncBufferOverflowSpill
+	AsyncBufferOverflowError
+)
+
+var ErrAsyncBufferFull = errors.New("async buffer is full")
+
+type AsyncBufferOptions struct {
+	Mode     AsyncBufferMode
+	Size     int
+	Overflow AsyncBufferOverflow
+}
 
 type asyncTemporaryQueueEvent []any
 
+type pendingAsyncEvent struct {
+	schema *entitySchema
+	event  asyncTemporaryQueueEvent
+}
+
+func newAsyncTemporaryQueue(options *AsyncBufferOptions) *xsync.MPMCQueueOf[asyncTemporaryQueueEvent] {
+	return xsync.NewMPMCQueueOf[asyncTemporaryQueueEvent](options.Size)
+}
+
 func publishAsyncEvent(schema *entitySchema, event asyncTemporaryQueueEvent) {
 	schema.asyncTemporaryQueue.Enqueue(event)
+	if event != nil {
+		schema.asyncTemporaryQueueLen.Add(1)
+	}
+}
+
+func (orm *ormImplementation) publishAsyncEvents(events []pendingAsyncEvent) {
+	options := orm.engine.registry.asyncBufferOptions
+	for _, event := range events {
+		schema := event.schema
+		if options.Mode == AsyncBufferDirect {
+			asJSON, _ := jsoniter.ConfigFastest.MarshalToString(event.event)
+			pipeLine := orm.RedisPipeLine(schema.getForcedRedisCode())
+			if isAsyncFlushStreamsEnabled(orm) {
+				pipeLine.XAdd(getAsyncFlushStream(schema.asyncCacheKey), []string{flushAsyncEventsStreamField, asJSON})
+			} else {
+				pipeLine.RPush(schema.asyncCacheKey, asJSON)
+			}
+			continue
+		}
+		if options.Overflow == AsyncBufferOverflowSpill {
+			if schema.asyncTemporaryQueue.TryEnqueue(event.event) {
+				schema.asyncTemporaryQueueLen.Add(1)
+				continue
+			}
+			asJSON, _ := jsoniter.ConfigFastest.MarshalToString(event.event)
+			pushAsyncEvents(orm, orm.engine.Redis(schema.getForcedRedisCode()), schema.asyncCacheKey, []any{asJSON})
+			continue
+		}
+		publishAsyncEvent(schema, event.event)
+	}
+}
+
+func (orm *ormImplementation) checkAsyncBuffer(sqlGroup sqlOperations) error {
+	options := orm.engine.registry.asyncBufferOptions
+	if options.Mode != AsyncBufferMemory || options.Overflow != AsyncBufferOverflowError {
+		return nil
+	}
+	isFull := func(schema *entitySchema) bool {
+		if schema.asyncTemporaryQueueLen.Load() >= int64(options.Size) {
+			return true
+		}
+		logTableSchema, hasLogTable := orm.engine.registry.entityLogSchemas[schema.t]
+		return hasLogTable && logTableSchema.asyncTemporaryQueueLen.Load() >= int64(options.Size)
+	}
+	for _, operations := range sqlGroup {
+		for schema := range operations {
+			if isFull(schema) {
+				return ErrAsyncBufferFull
+			}
+		}
+	}
+	for _, operation := range orm.bulkOperations {
+		for _, schema := range operation.schema.getShards() {
+			if isFull(schema) {
+				return ErrAsyncBufferFull
+			}
+		}
+	}
+	return nil
+}
+
+func DrainAsyncBuffer(orm ORM) error {
+	engine := orm.Engine().(*engineImplementation)
+	if engine.asyncTemporaryIsQueueRunning.Load() && engine.asyncTemporaryQueueStop != nil {
+		engine.asyncTemporaryQueueStop()
+	}
+	buffer := make([]any, 0, redisRPushPackSize)
+	for _, schema := range getAllShardSchemas(orm.Engine().Registry()) {
+		r := orm.Engine().Redis(schema.getForcedRedisCode())
+		for {
+			if orm.Context().Err() != nil {
+				return orm.Context().Err()
+			}
+			buffer = buffer[0:0]
+			for len(buffer) < redisRPushPackSize {
+				event, has := schema.asyncTemporaryQueue.TryDequeue()
+				if !has {
+					break
+				}
+				if event == nil {
+					continue
+				}
+				schema.asyncTemporaryQueueLen.Add(-1)
+				asJSON, _ := jsoniter.ConfigFastest.MarshalToString(event)
+				buffer = append(buffer, asJSON)
+			}
+			if len(buffer) == 0 {
+				break
+			}
+			pushAsyncEvents(orm, r, schema.asyncCacheKey, buffer)
+		}
+	}
+	return nil
 }
 
 func ConsumeAsyncBuffer(orm ORM, errF func(err error)) (stop func()) {
 	engine := orm.Engine().(*engineImplementation)
-	if engine.asyncTemporaryIsQueueRunning {
+	if engine.asyncTemporaryIsQueueRunning.Load() {
 		panic("consumer is already running")
 	}
-	engine.asyncTemporaryIsQueueRunning = true
-	schemas := orm.Engine().Registry().Entities()
+	engine.asyncTemporaryIsQueueRunning.Store(true)
+	schemas := getAllShardSchemas(orm.Engine().Registry())
+	defer func() {
+		engine.asyncTemporaryQueueStop = stop
+	}()
 	stop = func() {
-		if !engine.asyncTemporaryIsQueueRunning {
+		if !engine.asyncTemporaryIsQueueRunning.Load() {
 			return
 		}
 		for _, schema := range schemas {
-			schema.(*entitySchema).asyncTemporaryQueue.TryEnqueue(nil)
+			schema.asyncTemporaryQueue.TryEnqueue(nil)
 		}
 		maxIterations := 10000
 		for {
@@ -36,7 +167,7 @@ func ConsumeAsyncBuffer(orm ORM, errF func(err error)) (stop func()) {
 			if maxIterations == 0 {
 				return
 			}
-			if engine.asyncTemporaryIsQueueRunning {
+			if engine.asyncTemporaryIsQueueRunning.Load() {
 				time.Sleep(time.Millisecond)
 				continue
 			}
@@ -46,7 +177,7 @@ func ConsumeAsyncBuffer(orm ORM, errF func(err error)) (stop func()) {
 	go func() {
 		waitGroup := &sync.WaitGroup{}
 		for _, schema := range schemas {
-			var schemaLocal = schema.(*entitySchema)
+			var schemaLocal = schema
 			waitGroup.Add(1)
 			go func() {
 				defer waitGroup.Done()
@@ -54,7 +185,7 @@ func ConsumeAsyncBuffer(orm ORM, errF func(err error)) (stop func()) {
 			}()
 		}
 		waitGroup.Wait()
-		engine.asyncTemporaryIsQueueRunning = false
+		engine.asyncTemporaryIsQueueRunning.Store(false)
 	}()
 	return stop
 }
@@ -88,6 +219,7 @@ func consumeAsyncTempEvent(orm ORM, schema *entitySchema, errF func(err error))
 			if values == nil {
 				return false
 			}
+			schema.asyncTemporaryQueueLen.Add(-1)
 			rows := 1
 			asJSON, _ := jsoniter.ConfigFastest.MarshalToString(values)
 			buffer[0] = asJSON
@@ -101,11 +233,12 @@ func consumeAsyncTempEvent(orm ORM, schema *entitySchema, errF func(err error))
 					breakMe = true
 					break
 				}
+				schema.asyncTemporaryQueueLen.Add(-1)
 				asJSON, _ = jsoniter.ConfigFastest.MarshalToString(e)
 				buffer[i] = asJSON
 				rows++
 			}
-			r.RPush(orm, schema.asyncCacheKey, buffer[0:rows]...)
+			pushAsyncEvents(orm, r, schema.asyncCacheKey, buffer[0:rows])
 			return !breakMe
 		}()
 		if !res {
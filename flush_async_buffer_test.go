@@ -0,0 +1,89 @@
+package beeorm
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareAsyncBufferTest(t *testing.T, options *AsyncBufferOptions, streams bool) (*ormImplementation, *entitySchema) {
+	registry := NewRegistry()
+	registry.RegisterRedisInMemory(DefaultPoolCode)
+	if options != nil {
+		registry.SetAsyncBufferOptions(options)
+	}
+	if streams {
+		registry.EnableAsyncFlushStreams()
+	}
+	validatedRegistry, err := registry.Validate()
+	assert.NoError(t, err)
+	engine := validatedRegistry.(*engineImplementation)
+	schema := &entitySchema{engine: engine, asyncCacheKey: flushAsyncEventsList}
+	schema.asyncTemporaryQueue = newAsyncTemporaryQueue(engine.registry.asyncBufferOptions)
+	schema.asyncTemporaryQueueLen = &atomic.Int64{}
+	engine.registry.entitySchemaList = append(engine.registry.entitySchemaList, schema)
+	return engine.NewORM(context.Background()).(*ormImplementation), schema
+}
+
+func TestAsyncBufferOptions(t *testing.T) {
+	orm, _ := prepareAsyncBufferTest(t, nil, false)
+	assert.Equal(t, &AsyncBufferOptions{Size: asyncBufferDefaultSize}, orm.engine.registry.asyncBufferOptions)
+
+	orm, schema := prepareAsyncBufferTest(t, &AsyncBufferOptions{Size: 2, Overflow: AsyncBufferOverflowError}, false)
+	r := orm.Engine().Redis(DefaultPoolCode)
+	event := pendingAsyncEvent{schema: schema, event: asyncTemporaryQueueEvent{"UPDATE `Table` SET `Name` = ? WHERE ID = ?", "a", "1"}}
+	group := sqlOperations{nil: schemaSQLOperations{schema: nil}}
+	assert.NoError(t, orm.checkAsyncBuffer(group))
+	orm.publishAsyncEvents([]pendingAsyncEvent{event, event})
+	assert.Equal(t, int64(2), schema.asyncTemporaryQueueLen.Load())
+	assert.ErrorIs(t, orm.checkAsyncBuffer(group), ErrAsyncBufferFull)
+	assert.Equal(t, int64(0), r.LLen(orm, flushAsyncEventsList))
+	assert.NoError(t, DrainAsyncBuffer(orm))
+	assert.Equal(t, int64(0), schema.asyncTemporaryQueueLen.Load())
+	assert.Equal(t, int64(2), r.LLen(orm, flushAsyncEventsList))
+	assert.NoError(t, orm.checkAsyncBuffer(group))
+
+	orm, schema = prepareAsyncBufferTest(t, &AsyncBufferOptions{Size: 1, Overflow: AsyncBufferOverflowSpill}, false)
+	r = orm.Engine().Redis(DefaultPoolCode)
+	event.schema = schema
+	orm.publishAsyncEvents([]pendingAsyncEvent{event, event, event})
+	assert.Equal(t, int64(1), schema.asyncTemporaryQueueLen.Load())
+	assert.Equal(t, int64(2), r.LLen(orm, flushAsyncEventsList))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, DrainAsyncBuffer(orm.CloneWithContext(ctx)), context.Canceled)
+	assert.Equal(t, int64(1), schema.asyncTemporaryQueueLen.Load())
+	assert.NoError(t, DrainAsyncBuffer(orm))
+	assert.Equal(t, int64(3), r.LLen(orm, flushAsyncEventsList))
+
+	orm, schema = prepareAsyncBufferTest(t, &AsyncBufferOptions{Mode: AsyncBufferDirect}, false)
+	r = orm.Engine().Redis(DefaultPoolCode)
+	event.schema = schema
+	orm.publishAsyncEvents([]pendingAsyncEvent{event, event})
+	assert.Equal(t, int64(0), schema.asyncTemporaryQueueLen.Load())
+	assert.Equal(t, int64(0), r.LLen(orm, flushAsyncEventsList))
+	orm.RedisPipeLine(DefaultPoolCode).Exec(orm)
+	assert.Equal(t, int64(2), r.LLen(orm, flushAsyncEventsList))
+	assert.Equal(t, `["UPDATE `+"`Table`"+` SET `+"`Name`"+` = ? WHERE ID = ?","a","1"]`, r.LRange(orm, flushAsyncEventsList, 0, 0)[0])
+
+	orm, schema = prepareAsyncBufferTest(t, &AsyncBufferOptions{Mode: AsyncBufferDirect}, true)
+	r = orm.Engine().Redis(DefaultPoolCode)
+	event.schema = schema
+	orm.publishAsyncEvents([]pendingAsyncEvent{event})
+	orm.RedisPipeLine(DefaultPoolCode).Exec(orm)
+	assert.Equal(t, int64(1), r.XLen(orm, getAsyncFlushStream(flushAsyncEventsList)))
+
+	orm, schema = prepareAsyncBufferTest(t, nil, false)
+	r = orm.Engine().Redis(DefaultPoolCode)
+	event.schema = schema
+	ConsumeAsyncBuffer(orm, func(err error) {
+		panic(err)
+	})
+	orm.publishAsyncEvents([]pendingAsyncEvent{event, event})
+	assert.NoError(t, DrainAsyncBuffer(orm))
+	assert.False(t, orm.engine.asyncTemporaryIsQueueRunning.Load())
+	assert.Equal(t, int64(0), schema.asyncTemporaryQueueLen.Load())
+	assert.Equal(t, int64(2), r.LLen(orm, flushAsyncEventsList))
+}
@@ -3,14 +3,14 @@ package beeorm
 import (
 	"context"
 	"fmt"
-	"slices"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/bsm/redislock"
-
-	"github.com/go-sql-driver/mysql"
 	jsoniter "github.com/json-iterator/go"
 )
 
@@ -18,6 +18,19 @@ const asyncConsumerPage = 1000
 const asyncConsumerBlockTime = time.Second * 3
 const flushAsyncEventsList = "flush_async_events"
 const flushAsyncEventsListErrorSuffix = ":err"
+const flushAsyncEventsListArchiveSuffix = ":archive"
+
+var asyncEventTableRegexp = regexp.MustCompile("^(?:INSERT INTO|UPDATE|DELETE FROM) `([^`]+)`")
+var asyncEventUpdateIDRegexp = regexp.MustCompile(" WHERE `?ID`? = \\?")
+var asyncEventDeleteIDRegexp = regexp.MustCompile(" WHERE `?ID`? IN \\((\\d+)\\)$")
+
+type asyncFlushDeadLetter struct {
+	Event    string
+	Error    string
+	Table    string
+	EntityID uint64
+	Time     int64
+}
 
 var mySQLErrorCodesToSkip = []uint16{
 	1022, // Can't write; duplicate key in table '%s'
@@ -31,42 +44,35 @@ var mySQLErrorCodesToSkip = []uint16{
 	1067, // Invalid default value for '%s'
 	1109, // Message: Unknown table '%s' in %s
 	1146, // Table '%s.%s' doesn't exist
+	1451, // Cannot delete or update a parent row: a foreign key constraint fails
+	1452, // Cannot add or update a child row: a foreign key constraint fails
 	1149, // You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use
 	2032, // Data truncated
 }
 
 const asyncConsumerLockName = "async_consumer"
+const asyncConsumerTrimScript = `if redis.call("get", KEYS[1]) == ARGV[1] then redis.call("ltrim", KEYS[2], ARGV[2], -1) return 1 else return 0 end`
+const asyncConsumerLockTTL = time.Minute
+const asyncConsumerLockRefreshInterval = time.Second * 20
+
+type AsyncConsumerInfo struct {
+	Node     string
+	Hostname string
+	PID      int
+	Since    time.Time
+}
 
 func ConsumeAsyncFlushEvents(orm ORM, block bool) error {
-	lock, lockObtained := orm.Engine().Redis(DefaultPoolCode).GetLocker().Obtain(orm, asyncConsumerLockName, time.Minute, 0)
-	if !lockObtained {
-		return redislock.ErrNotObtained
-	}
-	defer func() {
-		lock.Release(orm)
-	}()
-	go func() {
-		defer func() {
-			if rec := recover(); rec != nil {
-				lockObtained = false
-				time.Sleep(time.Second * 50)
-			}
-		}()
-		time.Sleep(time.Second * 50)
-		if !lock.Refresh(orm, time.Minute) {
-			lockObtained = false
-		}
-	}()
 	errorMutex := sync.Mutex{}
 	waitGroup := &sync.WaitGroup{}
 	ctxNoCancel := orm.CloneWithContext(context.Background())
 	groups := make(map[DB]map[RedisCache]map[string]bool)
 	var stop uint32
 	var globalError error
-	for _, schema := range orm.Engine().Registry().Entities() {
+	for _, schema := range getAllShardSchemas(orm.Engine().Registry()) {
 		db := schema.GetDB()
 		dbGroup, has := groups[db]
-		asyncCacheKey := schema.(*entitySchema).asyncCacheKey
+		asyncCacheKey := schema.asyncCacheKey
 		if !has {
 			dbGroup = make(map[RedisCache]map[string]bool)
 			groups[db] = dbGroup
@@ -84,39 +90,83 @@ func ConsumeAsyncFlushEvents(orm ORM, block bool) error {
 		redisGroup[asyncCacheKey] = true
 		waitGroup.Add(1)
 		go func() {
+			defer waitGroup.Done()
 			defer func() {
 				if rec := recover(); rec != nil {
 					atomic.AddUint32(&stop, 1)
+					orm.Engine().Registry().(*engineRegistryImplementation).asyncConsumerMetrics.fatal.Add(1)
 					asError, isError := rec.(error)
 					if !isError {
 						asError = fmt.Errorf("%v", rec)
 					}
+					errorMutex.Lock()
 					if globalError == nil {
-						errorMutex.Lock()
 						globalError = asError
-						errorMutex.Unlock()
 					}
+					errorMutex.Unlock()
 				}
 			}()
-			consumeAsyncEvents(orm.Context(), ctxNoCancel.Clone(), asyncCacheKey, db, r, block, waitGroup, &lockObtained, &stop)
+			consumeAsyncEvents(orm.Context(), ctxNoCancel.Clone(), asyncCacheKey, db, r, block, &stop)
 		}()
 	}
 	waitGroup.Wait()
 	return globalError
 }
 
-func consumeAsyncEvents(context context.Context, orm ORM, list string, db DB, r RedisCache,
-	block bool, waitGroup *sync.WaitGroup, lockObtained *bool, stop *uint32) {
-	defer waitGroup.Done()
+func consumeAsyncEvents(context context.Context, orm ORM, list string, db DB, r RedisCache, block bool, stop *uint32) {
+	blockTime := orm.Engine().Registry().(*engineRegistryImplementation).asyncConsumerBlockTime
+	for {
+		if context.Err() != nil || atomic.LoadUint32(stop) > 0 {
+			return
+		}
+		lock, obtained := obtainAsyncConsumerLock(orm, list, r)
+		if !obtained {
+			if !block {
+				return
+			}
+			time.Sleep(blockTime)
+			continue
+		}
+		func() {
+			lockLost := uint32(0)
+			refreshDone := make(chan struct{})
+			refreshStopped := make(chan struct{})
+			go refreshAsyncConsumerLock(orm.Clone(), list, r, lock, &lockLost, refreshDone, refreshStopped)
+			defer func() {
+				close(refreshDone)
+				<-refreshStopped
+				releaseAsyncConsumerLock(orm, list, r, lock, &lockLost)
+			}()
+			if isAsyncFlushStreamsEnabled(orm) {
+				consumeAsyncStreamEventsWithLock(context, orm, list, db, r, block, stop, lock, &lockLost)
+				return
+			}
+			consumeAsyncEventsWithLock(context, orm, list, db, r, block, stop, lock, &lockLost)
+		}()
+		if !block {
+			return
+		}
+	}
+}
+
+func consumeAsyncEventsWithLock(context context.Context, orm ORM, list string, db DB, r RedisCache,
+	block bool, stop *uint32, lock *Lock, lockLost *uint32) {
 	var values []string
 	for {
-		if context.Err() != nil || !*lockObtained || *stop > 0 {
+		if context.Err() != nil || atomic.LoadUint32(lockLost) > 0 || atomic.LoadUint32(stop) > 0 {
 			return
 		}
 
 		values = r.LRange(orm, list, 0, asyncConsumerPage-1)
 		if len(values) > 0 {
-			handleAsyncEvents(context, orm, list, db, r, values)
+			handleAsyncEvents(context, orm, list, db, r, values, func(processed int) bool {
+				trimmed, _ := r.Eval(orm, asyncConsumerTrimScript, []string{lock.key, list}, lock.lock.Token(), processed).(int64)
+				if trimmed == 0 {
+					atomic.StoreUint32(lockLost, 1)
+					return false
+				}
+				return true
+			})
 		}
 		if len(values) < asyncConsumerPage {
 			if !block || context.Err() != nil {
@@ -127,79 +177,219 @@ func consumeAsyncEvents(context context.Context, orm ORM, list string, db DB, r
 	}
 }
 
-func handleAsyncEvents(context context.Context, orm ORM, list string, db DB, r RedisCache, values []string) {
-	operations := len(values)
-	inTX := operations > 1
-	func() {
-		var d DBBase
-		defer func() {
-			if inTX && d != nil {
-				d.(DBTransaction).Rollback(orm)
-			}
-		}()
-		dbPool := db
-		if inTX {
-			d = dbPool.Begin(orm)
-		} else {
-			d = dbPool
-		}
-		for _, event := range values {
-			if context.Err() != nil {
-				return
-			}
-			err := handleAsyncEvent(orm, d, event)
-			if err != nil {
-				if inTX {
-					d.(DBTransaction).Rollback(orm)
+func getAsyncConsumerLockKey(list string) string {
+	return asyncConsumerLockName + ":" + list
+}
+
+func getAsyncConsumerOwnerKey(list string) string {
+	return getAsyncConsumerLockKey(list) + ":owner"
+}
+
+func obtainAsyncConsumerLock(orm ORM, list string, r RedisCache) (*Lock, bool) {
+	lock, obtained := r.GetLocker().Obtain(orm, getAsyncConsumerLockKey(list), asyncConsumerLockTTL, 0)
+	if !obtained {
+		return nil, false
+	}
+	hostname, _ := os.Hostname()
+	info := AsyncConsumerInfo{Node: orm.Engine().NodeID(), Hostname: hostname, PID: os.Getpid(), Since: time.Now()}
+	asJSON, _ := jsoniter.ConfigFastest.MarshalToString(info)
+	r.Set(orm, getAsyncConsumerOwnerKey(list), asJSON, asyncConsumerLockTTL)
+	return lock, true
+}
+
+func refreshAsyncConsumerLock(orm ORM, list string, r RedisCache, lock *Lock, lockLost *uint32, done, stopped chan struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(asyncConsumerLockRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			refreshed := func() bool {
+				defer func() {
+					_ = recover()
+				}()
+				if !lock.Refresh(orm, asyncConsumerLockTTL) {
+					return false
 				}
-				handleAsyncEventsOneByOne(context, orm, list, db, r, values)
+				r.Expire(orm, getAsyncConsumerOwnerKey(list), asyncConsumerLockTTL)
+				return true
+			}()
+			if !refreshed {
+				atomic.StoreUint32(lockLost, 1)
 				return
 			}
 		}
-		if inTX {
-			d.(DBTransaction).Commit(orm)
+	}
+}
+
+func releaseAsyncConsumerLock(orm ORM, list string, r RedisCache, lock *Lock, lockLost *uint32) {
+	if atomic.LoadUint32(lockLost) == 0 {
+		r.Del(orm, getAsyncConsumerOwnerKey(list))
+	}
+	lock.Release(orm)
+}
+
+func handleAsyncEvents(context context.Context, orm ORM, list string, db DB, r RedisCache, values []string, ack func(processed int) bool) {
+	if len(values) > 1 {
+		committed, invalidations := handleAsyncEventsInTransaction(context, orm, db, values)
+		if committed {
+			ack(len(values))
+			for _, invalidation := range invalidations {
+				publishAsyncEventLocalCacheInvalidation(orm, invalidation)
+			}
+			return
+		}
+		if context.Err() != nil {
+			return
+		}
+	}
+	handleAsyncEventsOneByOne(context, orm, list, db, r, values, ack)
+}
+
+func handleAsyncEventsInTransaction(context context.Context, orm ORM, db DB, values []string) (committed bool, invalidations [][]any) {
+	var tx DBTransaction
+	defer func() {
+		rec := recover()
+		if !committed && tx != nil {
+			func() {
+				defer func() {
+					_ = recover()
+				}()
+				tx.Rollback(orm)
+			}()
+		}
+		if rec != nil {
+			action, err := orm.Engine().Registry().(*engineRegistryImplementation).asyncRetryPolicy.classify(db, rec)
+			if action != AsyncErrorSkip && action != AsyncErrorRetry {
+				panic(err)
+			}
+			committed = false
 		}
-		r.Ltrim(orm, list, int64(len(values)), -1)
 	}()
+	tx = db.Begin(orm)
+	for _, event := range values {
+		if context.Err() != nil {
+			return false, nil
+		}
+		_, err := handleAsyncEvent(orm, tx, event, &invalidations)
+		if err != nil {
+			return false, nil
+		}
+	}
+	tx.Commit(orm)
+	return true, invalidations
 }
 
-func handleAsyncEvent(orm ORM, db DBBase, value string) (err *mysql.MySQLError) {
+func handleAsyncEvent(orm ORM, db DBBase, value string, invalidations *[][]any) (action AsyncErrorAction, err error) {
 	defer func() {
 		if rec := recover(); rec != nil {
-			asMySQLError, isMySQLError := rec.(*mysql.MySQLError)
-			if isMySQLError && slices.Contains(mySQLErrorCodesToSkip, asMySQLError.Number) {
-				err = asMySQLError
-				return
+			action, err = orm.Engine().Registry().(*engineRegistryImplementation).asyncRetryPolicy.classify(db, rec)
+			if action != AsyncErrorSkip && action != AsyncErrorRetry {
+				panic(err)
 			}
-			panic(rec)
 		}
 	}()
 	var data []any
 	_ = jsoniter.ConfigFastest.UnmarshalFromString(value, &data)
 	if len(data) == 0 {
-		return nil
+		return AsyncErrorDefault, nil
 	}
 	sql, valid := data[0].(string)
 	if !valid {
-		return
+		return AsyncErrorDefault, nil
+	}
+	if sql == asyncEventLocalCacheInvalidation {
+		if invalidations != nil {
+			*invalidations = append(*invalidations, data)
+		} else {
+			publishAsyncEventLocalCacheInvalidation(orm, data)
+		}
+		return AsyncErrorDefault, nil
 	}
 	if len(data) == 1 {
 		db.Exec(orm, sql)
-		return nil
+		return AsyncErrorDefault, nil
 	}
 	db.Exec(orm, sql, data[1:]...)
-	return nil
+	return AsyncErrorDefault, nil
 }
 
-func handleAsyncEventsOneByOne(context context.Context, orm ORM, list string, db DB, r RedisCache, values []string) {
+func handleAsyncEventsOneByOne(context context.Context, orm ORM, list string, db DB, r RedisCache, values []string, ack func(processed int) bool) {
+	engineRegistry := orm.Engine().Registry().(*engineRegistryImplementation)
+	policy := engineRegistry.asyncRetryPolicy
+	metrics := engineRegistry.asyncConsumerMetrics
 	for _, event := range values {
-		if context.Err() != nil {
+		for attempt := 0; ; attempt++ {
+			if context.Err() != nil {
+				return
+			}
+			action, err := handleAsyncEvent(orm, db, event, nil)
+			if err == nil {
+				if attempt > 0 {
+					metrics.recovered.Add(1)
+				}
+				break
+			}
+			if action == AsyncErrorSkip {
+				metrics.skipped.Add(1)
+				r.RPush(orm, list+flushAsyncEventsListErrorSuffix, newAsyncFlushDeadLetter(event, err))
+				break
+			}
+			if attempt >= policy.MaxRetries {
+				panic(err)
+			}
+			metrics.retries.Add(1)
+			if !policy.wait(context, err, attempt) {
+				return
+			}
+		}
+		if !ack(1) {
 			return
 		}
-		err := handleAsyncEvent(orm, db, event)
-		if err != nil {
-			r.RPush(orm, list+flushAsyncEventsListErrorSuffix, event, err.Error())
+	}
+}
+
+func newAsyncFlushDeadLetter(event string, err error) string {
+	deadLetter := buildAsyncFlushDeadLetter(event, err.Error())
+	deadLetter.Time = time.Now().Unix()
+	asJSON, _ := jsoniter.ConfigFastest.MarshalToString(deadLetter)
+	return asJSON
+}
+
+func buildAsyncFlushDeadLetter(event, errorMessage string) asyncFlushDeadLetter {
+	deadLetter := asyncFlushDeadLetter{Event: event, Error: errorMessage}
+	var data []any
+	_ = jsoniter.ConfigFastest.UnmarshalFromString(event, &data)
+	if len(data) > 0 {
+		sql, _ := data[0].(string)
+		deadLetter.Table, deadLetter.EntityID = parseAsyncEventTarget(sql, data[1:])
+	}
+	return deadLetter
+}
+
+func parseAsyncEventTarget(sql string, args []any) (table string, id uint64) {
+	matches := asyncEventTableRegexp.FindStringSubmatch(sql)
+	if matches == nil {
+		return "", 0
+	}
+	table = matches[1]
+	var idValue any
+	if strings.HasPrefix(sql, "INSERT") {
+		if len(args) > 0 {
+			idValue = args[0]
+		}
+	} else if position := asyncEventUpdateIDRegexp.FindStringIndex(sql); position != nil {
+		index := strings.Count(sql[:position[0]], "?")
+		if index < len(args) {
+			idValue = args[index]
 		}
-		r.Ltrim(orm, list, 1, -1)
+	} else if idMatches := asyncEventDeleteIDRegexp.FindStringSubmatch(sql); idMatches != nil {
+		idValue = idMatches[1]
+	}
+	if idValue != nil {
+		id, _ = strconv.ParseUint(fmt.Sprintf("%v", idValue), 10, 64)
 	}
+	return table, id
 }
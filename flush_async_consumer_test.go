@@ -2,10 +2,13 @@ package beeorm
 
 import (
 	"context"
+	"os"
 	"strconv"
 	"testing"
 	"time"
 
+	jsoniter "github.com/json-iterator/go"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -172,9 +175,90 @@ func TestAsyncConsumer(t *testing.T) {
 	err = runAsyncConsumer(orm, false)
 	assert.Nil(t, err)
 	assert.Equal(t, int64(0), orm.Engine().Redis(DefaultPoolCode).LLen(orm, schema.asyncCacheKey))
-	assert.Equal(t, int64(2), orm.Engine().Redis(DefaultPoolCode).LLen(orm, schema.asyncCacheKey+flushAsyncEventsListErrorSuffix))
-	assert.Contains(t, orm.Engine().Redis(DefaultPoolCode).LPop(orm, schema.asyncCacheKey+flushAsyncEventsListErrorSuffix), "INSERT INTO `flushEntity`")
-	assert.Equal(t, "Error 1062 (23000): Duplicate entry 'Valid name 2' for key 'flushEntity.name'", orm.Engine().Redis(DefaultPoolCode).LPop(orm, schema.asyncCacheKey+flushAsyncEventsListErrorSuffix))
+	assert.Equal(t, int64(1), orm.Engine().Redis(DefaultPoolCode).LLen(orm, schema.asyncCacheKey+flushAsyncEventsListErrorSuffix))
+	var deadLetter asyncFlushDeadLetter
+	err = jsoniter.ConfigFastest.UnmarshalFromString(orm.Engine().Redis(DefaultPoolCode).LPop(orm, schema.asyncCacheKey+flushAsyncEventsListErrorSuffix), &deadLetter)
+	assert.NoError(t, err)
+	assert.Contains(t, deadLetter.Event, "INSERT INTO `flushEntity`")
+	assert.Equal(t, "Error 1062 (23000): Duplicate entry 'Valid name 2' for key 'flushEntity.name'", deadLetter.Error)
+	assert.Equal(t, "flushEntity", deadLetter.Table)
+	assert.Equal(t, e2.ID, deadLetter.EntityID)
+
+	// list owned by another node
+	lock, obtained := obtainAsyncConsumerLock(orm, schema2.asyncCacheKey, orm.Engine().Redis(DefaultPoolCode))
+	assert.True(t, obtained)
+	reference = NewEntity[flushEntityReference](orm)
+	reference.Name = "test reference locked"
+	asyncEntity3 = NewEntity[flushEntityAsync3](orm)
+	asyncEntity3.Name = "test reference locked"
+	err = orm.FlushAsync()
+	assert.NoError(t, err)
+	err = runAsyncConsumer(orm, false)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), orm.Engine().Redis(DefaultPoolCode).LLen(orm, schema2.asyncCacheKey))
+	assert.Equal(t, int64(0), orm.Engine().Redis(DefaultPoolCode).LLen(orm, schema6.asyncCacheKey))
+	lockLost := uint32(0)
+	releaseAsyncConsumerLock(orm, schema2.asyncCacheKey, orm.Engine().Redis(DefaultPoolCode), lock, &lockLost)
+	err = runAsyncConsumer(orm, false)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), orm.Engine().Redis(DefaultPoolCode).LLen(orm, schema2.asyncCacheKey))
+}
+
+func TestAsyncConsumerLock(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterRedisInMemory(DefaultPoolCode)
+	validatedRegistry, err := registry.Validate()
+	assert.NoError(t, err)
+	orm := validatedRegistry.NewORM(context.Background())
+	r := orm.Engine().Redis(DefaultPoolCode)
+	events := &asyncFlushEvents{orm: orm, listName: flushAsyncEventsList, redisPoolName: DefaultPoolCode}
+
+	_, has := events.Consumer()
+	assert.False(t, has)
+	lock, obtained := obtainAsyncConsumerLock(orm, flushAsyncEventsList, r)
+	assert.True(t, obtained)
+	_, obtained = obtainAsyncConsumerLock(orm, flushAsyncEventsList, r)
+	assert.False(t, obtained)
+	consumer, has := events.Consumer()
+	assert.True(t, has)
+	assert.Equal(t, orm.Engine().NodeID(), consumer.Node)
+	assert.Equal(t, os.Getpid(), consumer.PID)
+	assert.NotEmpty(t, orm.Engine().NodeID())
+	assert.WithinDuration(t, time.Now(), consumer.Since, time.Minute)
+
+	lockLost := uint32(0)
+	releaseAsyncConsumerLock(orm, flushAsyncEventsList, r, lock, &lockLost)
+	_, has = events.Consumer()
+	assert.False(t, has)
+	lock, obtained = obtainAsyncConsumerLock(orm, flushAsyncEventsList, r)
+	assert.True(t, obtained)
+	r.Del(orm, getAsyncConsumerLockKey(flushAsyncEventsList))
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	close(done)
+	refreshAsyncConsumerLock(orm, flushAsyncEventsList, r, lock, &lockLost, done, stopped)
+	<-stopped
+	assert.Equal(t, uint32(0), lockLost)
+	assert.False(t, lock.Refresh(orm, time.Second))
+	lockLost = 1
+	releaseAsyncConsumerLock(orm, flushAsyncEventsList, r, lock, &lockLost)
+	_, has = events.Consumer()
+	assert.True(t, has)
+
+	lock, obtained = obtainAsyncConsumerLock(orm, flushAsyncEventsList, r)
+	assert.True(t, obtained)
+	r.RPush(orm, flushAsyncEventsList, "[]")
+	r.Set(orm, getAsyncConsumerLockKey(flushAsyncEventsList), "other", time.Minute)
+	stop := uint32(0)
+	lockLost = 0
+	consumeAsyncEventsWithLock(context.Background(), orm, flushAsyncEventsList, nil, r, false, &stop, lock, &lockLost)
+	assert.Equal(t, uint32(1), lockLost)
+	assert.Equal(t, int64(1), r.LLen(orm, flushAsyncEventsList))
+	r.Set(orm, getAsyncConsumerLockKey(flushAsyncEventsList), lock.lock.Token(), time.Minute)
+	lockLost = 0
+	consumeAsyncEventsWithLock(context.Background(), orm, flushAsyncEventsList, nil, r, false, &stop, lock, &lockLost)
+	assert.Equal(t, uint32(0), lockLost)
+	assert.Equal(t, int64(0), r.LLen(orm, flushAsyncEventsList))
 }
 
 func runAsyncConsumer(orm ORM, block bool) error {
@@ -184,3 +268,75 @@ func runAsyncConsumer(orm ORM, block bool) error {
 	stop()
 	return ConsumeAsyncFlushEvents(orm, block)
 }
+
+func TestAsyncConsumerStreams(t *testing.T) {
+	registry := NewRegistry()
+	registry.EnableAsyncFlushStreams()
+	orm := PrepareTables(t, registry, flushEntityReference{}, flushEntityAsync3{})
+	schema := getEntitySchema[flushEntityReference](orm)
+	schema.DisableCache(true, true)
+	schema2 := getEntitySchema[flushEntityAsync3](orm)
+	r := orm.Engine().Redis(DefaultPoolCode)
+
+	for i := 0; i < asyncConsumerPage+10; i++ {
+		reference := NewEntity[flushEntityReference](orm)
+		reference.Name = "test reference " + strconv.Itoa(i)
+	}
+	entity := NewEntity[flushEntityAsync3](orm)
+	entity.Name = "test stream"
+	err := orm.FlushAsync()
+	assert.NoError(t, err)
+	stop := ConsumeAsyncBuffer(orm, func(err error) {
+		panic(err)
+	})
+	stop()
+	assert.Equal(t, int64(0), r.LLen(orm, schema.asyncCacheKey))
+	assert.Equal(t, int64(asyncConsumerPage+10), r.XLen(orm, getAsyncFlushStream(schema.asyncCacheKey)))
+	assert.Equal(t, int64(1), r.XLen(orm, getAsyncFlushStream(schema2.asyncCacheKey)))
+	for _, stat := range ReadAsyncFlushEvents(orm) {
+		if stat.RedisList() == schema2.asyncCacheKey {
+			assert.Equal(t, uint64(1), stat.EventsCount())
+			assert.Equal(t, uint64(1), stat.Lag())
+			assert.Equal(t, uint64(0), stat.PendingCount())
+			assert.Contains(t, stat.Events(1)[0].QueryAttributes[1], "test stream")
+		}
+	}
+
+	err = ConsumeAsyncFlushEvents(orm, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), r.XLen(orm, getAsyncFlushStream(schema.asyncCacheKey)))
+	assert.Equal(t, int64(0), r.XLen(orm, getAsyncFlushStream(schema2.asyncCacheKey)))
+	assert.Equal(t, int64(0), r.XPending(orm, getAsyncFlushStream(schema.asyncCacheKey), flushAsyncEventsStreamGroup).Count)
+	references := Search[flushEntityReference](orm, NewWhere("1"), nil)
+	assert.Equal(t, asyncConsumerPage+10, references.Len())
+	e, _ := GetByID[flushEntityAsync3](orm, entity.ID)
+	assert.Equal(t, "test stream", e.Name)
+
+	// pending entry of dead consumer
+	stream := getAsyncFlushStream(schema2.asyncCacheKey)
+	entity = NewEntity[flushEntityAsync3](orm)
+	entity.Name = "test stream dead consumer"
+	err = orm.FlushAsync()
+	assert.NoError(t, err)
+	stop = ConsumeAsyncBuffer(orm, func(err error) {
+		panic(err)
+	})
+	stop()
+	streams := r.XReadGroup(orm, &redis.XReadGroupArgs{Group: flushAsyncEventsStreamGroup, Consumer: "dead", Streams: []string{stream, ">"}, Block: -1})
+	assert.Len(t, streams, 1)
+	for _, stat := range ReadAsyncFlushEvents(orm) {
+		if stat.RedisList() == schema2.asyncCacheKey {
+			assert.Equal(t, uint64(1), stat.PendingCount())
+			assert.Equal(t, uint64(0), stat.Lag())
+		}
+	}
+	err = ConsumeAsyncFlushEvents(orm, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), r.XPending(orm, stream, flushAsyncEventsStreamGroup).Count)
+	time.Sleep(asyncConsumerStreamClaimIdle)
+	err = ConsumeAsyncFlushEvents(orm, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), r.XPending(orm, stream, flushAsyncEventsStreamGroup).Count)
+	e, _ = GetByID[flushEntityAsync3](orm, entity.ID)
+	assert.Equal(t, "test stream dead consumer", e.Name)
+}
@@ -0,0 +1,115 @@
+package beeorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type AsyncErrorAction int
+
+const (
+	AsyncErrorDefault AsyncErrorAction = iota
+	AsyncErrorSkip
+	AsyncErrorRetry
+	AsyncErrorFatal
+)
+
+var mySQLErrorCodesToRetry = []uint16{
+	1205, // Lock wait timeout exceeded; try restarting transaction
+	1213, // Deadlock found when trying to get lock; try restarting transaction
+	2006, // MySQL server has gone away
+	2013, // Lost connection to MySQL server during query
+}
+
+type AsyncRetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Classify       func(err error) AsyncErrorAction
+	OnRetry        func(err error, attempt int, backoff time.Duration)
+}
+
+type AsyncConsumerMetrics struct {
+	Retries   uint64
+	Recovered uint64
+	Skipped   uint64
+	Fatal     uint64
+}
+
+type asyncConsumerMetrics struct {
+	retries   atomic.Uint64
+	recovered atomic.Uint64
+	skipped   atomic.Uint64
+	fatal     atomic.Uint64
+}
+
+func newDefaultAsyncRetryPolicy() *AsyncRetryPolicy {
+	return &AsyncRetryPolicy{MaxRetries: 5, InitialBackoff: time.Millisecond * 100, MaxBackoff: time.Second * 5}
+}
+
+func ReadAsyncConsumerMetrics(orm ORM) AsyncConsumerMetrics {
+	metrics := orm.Engine().Registry().(*engineRegistryImplementation).asyncConsumerMetrics
+	return AsyncConsumerMetrics{
+		Retries:   metrics.retries.Load(),
+		Recovered: metrics.recovered.Load(),
+		Skipped:   metrics.skipped.Load(),
+		Fatal:     metrics.fatal.Load(),
+	}
+}
+
+func (p *AsyncRetryPolicy) classify(db DBBase, rec any) (AsyncErrorAction, error) {
+	asError, isError := rec.(error)
+	if !isError {
+		return AsyncErrorFatal, fmt.Errorf("%v", rec)
+	}
+	if p.Classify != nil {
+		action := p.Classify(asError)
+		if action != AsyncErrorDefault {
+			return action, asError
+		}
+	}
+	dialect := db.GetConfig().getDialect()
+	if _, isSkippable := dialect.skippableAsyncError(rec); isSkippable {
+		return AsyncErrorSkip, asError
+	}
+	if _, isRetryable := dialect.retryableAsyncError(rec); isRetryable {
+		return AsyncErrorRetry, asError
+	}
+	return AsyncErrorFatal, asError
+}
+
+func (p *AsyncRetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 0; i < attempt && (p.MaxBackoff <= 0 || backoff < p.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return backoff
+}
+
+func (p *AsyncRetryPolicy) wait(ctx context.Context, err error, attempt int) bool {
+	backoff := p.backoff(attempt)
+	if p.OnRetry != nil {
+		p.OnRetry(err, attempt+1, backoff)
+	}
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func isConnectionError(err error) bool {
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn)
+}
@@ -0,0 +1,84 @@
+package beeorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncRetryPolicy(t *testing.T) {
+	policy := &AsyncRetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond * 10, MaxBackoff: time.Millisecond * 50}
+	assert.Equal(t, time.Millisecond*10, policy.backoff(0))
+	assert.Equal(t, time.Millisecond*20, policy.backoff(1))
+	assert.Equal(t, time.Millisecond*40, policy.backoff(2))
+	assert.Equal(t, time.Millisecond*50, policy.backoff(3))
+	assert.Equal(t, time.Millisecond*50, policy.backoff(100))
+
+	db := &dbImplementation{config: &mySQLConfig{dialect: &mysqlDialect{}}}
+	action, err := policy.classify(db, &mysql.MySQLError{Number: 1213, Message: "Deadlock found"})
+	assert.Equal(t, AsyncErrorRetry, action)
+	assert.EqualError(t, err, "Error 1213: Deadlock found")
+	action, _ = policy.classify(db, &mysql.MySQLError{Number: 1205})
+	assert.Equal(t, AsyncErrorRetry, action)
+	action, _ = policy.classify(db, driver.ErrBadConn)
+	assert.Equal(t, AsyncErrorRetry, action)
+	action, _ = policy.classify(db, &mysql.MySQLError{Number: 1062})
+	assert.Equal(t, AsyncErrorSkip, action)
+	action, _ = policy.classify(db, &mysql.MySQLError{Number: 1451})
+	assert.Equal(t, AsyncErrorSkip, action)
+	action, _ = policy.classify(db, &mysql.MySQLError{Number: 1452})
+	assert.Equal(t, AsyncErrorSkip, action)
+	action, _ = policy.classify(db, &mysql.MySQLError{Number: 1045})
+	assert.Equal(t, AsyncErrorFatal, action)
+	action, err = policy.classify(db, "invalid")
+	assert.Equal(t, AsyncErrorFatal, action)
+	assert.EqualError(t, err, "invalid")
+
+	policy.Classify = func(err error) AsyncErrorAction {
+		var asMySQLError *mysql.MySQLError
+		if errors.As(err, &asMySQLError) && asMySQLError.Number == 1045 {
+			return AsyncErrorSkip
+		}
+		if errors.As(err, &asMySQLError) && asMySQLError.Number == 1062 {
+			return AsyncErrorFatal
+		}
+		return AsyncErrorDefault
+	}
+	action, _ = policy.classify(db, &mysql.MySQLError{Number: 1045})
+	assert.Equal(t, AsyncErrorSkip, action)
+	action, _ = policy.classify(db, &mysql.MySQLError{Number: 1062})
+	assert.Equal(t, AsyncErrorFatal, action)
+	action, _ = policy.classify(db, &mysql.MySQLError{Number: 1213})
+	assert.Equal(t, AsyncErrorRetry, action)
+
+	sqliteDB := &dbImplementation{config: &mySQLConfig{dialect: &sqliteDialect{}}}
+	action, _ = policy.classify(sqliteDB, errors.New("database is locked"))
+	assert.Equal(t, AsyncErrorRetry, action)
+
+	attempts := 0
+	policy.OnRetry = func(err error, attempt int, backoff time.Duration) {
+		attempts = attempt
+		assert.Equal(t, time.Millisecond*20, backoff)
+	}
+	assert.True(t, policy.wait(context.Background(), errors.New("test"), 1))
+	assert.Equal(t, 2, attempts)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, policy.wait(ctx, errors.New("test"), 1))
+
+	registry := NewRegistry()
+	validatedRegistry, err := registry.Validate()
+	assert.NoError(t, err)
+	assert.Equal(t, 5, validatedRegistry.Registry().(*engineRegistryImplementation).asyncRetryPolicy.MaxRetries)
+	registry.SetAsyncRetryPolicy(policy)
+	validatedRegistry, err = registry.Validate()
+	assert.NoError(t, err)
+	orm := validatedRegistry.NewORM(context.Background())
+	assert.Equal(t, policy, validatedRegistry.Registry().(*engineRegistryImplementation).asyncRetryPolicy)
+	assert.Equal(t, AsyncConsumerMetrics{}, ReadAsyncConsumerMetrics(orm))
+}
@@ -0,0 +1,155 @@
+package beeorm
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const flushAsyncEventsStreamSuffix = ":stream"
+const flushAsyncEventsStreamGroup = "beeorm_async_consumer"
+const flushAsyncEventsStreamField = "e"
+const asyncConsumerStreamClaimIdle = asyncConsumerLockRefreshInterval
+
+func getAsyncFlushStream(list string) string {
+	return list + flushAsyncEventsStreamSuffix
+}
+
+func isAsyncFlushStreamsEnabled(orm ORM) bool {
+	return orm.Engine().Registry().(*engineRegistryImplementation).asyncFlushStreams
+}
+
+func pushAsyncEvents(orm ORM, r RedisCache, list string, events []any) {
+	if !isAsyncFlushStreamsEnabled(orm) {
+		r.RPush(orm, list, events...)
+		return
+	}
+	stream := getAsyncFlushStream(list)
+	pipeLine := orm.RedisPipeLine(r.GetConfig().GetCode())
+	for _, event := range events {
+		pipeLine.XAdd(stream, []string{flushAsyncEventsStreamField, event.(string)})
+	}
+	pipeLine.Exec(orm)
+}
+
+func consumeAsyncStreamEventsWithLock(context context.Context, orm ORM, list string, db DB, r RedisCache,
+	block bool, stop *uint32, lock *Lock, lockLost *uint32) {
+	stream := getAsyncFlushStream(list)
+	consumer := orm.Engine().NodeID()
+	blockTime := orm.Engine().Registry().(*engineRegistryImplementation).asyncConsumerBlockTime
+	r.XGroupCreateMkStream(orm, stream, flushAsyncEventsStreamGroup, "0")
+	defer func() {
+		if context.Err() == nil && atomic.LoadUint32(lockLost) == 0 && len(r.XPendingExt(orm, &redis.XPendingExtArgs{Stream: stream,
+			Group: flushAsyncEventsStreamGroup, Start: "-", End: "+", Count: 1, Consumer: consumer})) == 0 {
+			r.XGroupDelConsumer(orm, stream, flushAsyncEventsStreamGroup, consumer)
+		}
+	}()
+	start := "0"
+	consumeAsyncLegacyListEvents(context, orm, list, db, r, stop, lock, lockLost)
+	claimAsyncStreamPending(orm, r, stream, consumer)
+	for {
+		if context.Err() != nil || atomic.LoadUint32(lockLost) > 0 || atomic.LoadUint32(stop) > 0 {
+			return
+		}
+		args := &redis.XReadGroupArgs{Group: flushAsyncEventsStreamGroup, Consumer: consumer, Streams: []string{stream, start},
+			Count: asyncConsumerPage, Block: -1}
+		if block && start == ">" {
+			args.Block = blockTime
+		}
+		var messages []redis.XMessage
+		streams := r.XReadGroup(orm, args)
+		if len(streams) > 0 {
+			messages = streams[0].Messages
+		}
+		if len(messages) > 0 {
+			handleAsyncStreamMessages(context, orm, list, stream, db, r, messages)
+		}
+		if start == "0" {
+			if len(messages) == 0 {
+				start = ">"
+			}
+			continue
+		}
+		if len(messages) < asyncConsumerPage {
+			consumeAsyncLegacyListEvents(context, orm, list, db, r, stop, lock, lockLost)
+			if !block || context.Err() != nil {
+				return
+			}
+			if claimAsyncStreamPending(orm, r, stream, consumer) {
+				start = "0"
+			}
+		}
+	}
+}
+
+func consumeAsyncLegacyListEvents(context context.Context, orm ORM, list string, db DB, r RedisCache,
+	stop *uint32, lock *Lock, lockLost *uint32) {
+	if r.LLen(orm, list) > 0 {
+		consumeAsyncEventsWithLock(context, orm, list, db, r, false, stop, lock, lockLost)
+	}
+}
+
+func handleAsyncStreamMessages(context context.Context, orm ORM, list, stream string, db DB, r RedisCache, messages []redis.XMessage) {
+	ids := make([]string, 0, len(messages))
+	values := make([]string, 0, len(messages))
+	var deleted []string
+	for _, message := range messages {
+		event, has := message.Values[flushAsyncEventsStreamField]
+		if !has {
+			deleted = append(deleted, message.ID)
+			continue
+		}
+		ids = append(ids, message.ID)
+		values = append(values, event.(string))
+	}
+	if len(deleted) > 0 {
+		r.XAck(orm, stream, flushAsyncEventsStreamGroup, deleted...)
+	}
+	if len(values) == 0 {
+		return
+	}
+	acked := 0
+	handleAsyncEvents(context, orm, list, db, r, values, func(processed int) bool {
+		processedIDs := ids[acked : acked+processed]
+		acked += processed
+		r.XAck(orm, stream, flushAsyncEventsStreamGroup, processedIDs...)
+		r.XDel(orm, stream, processedIDs...)
+		return true
+	})
+}
+
+func claimAsyncStreamPending(orm ORM, r RedisCache, stream, consumer string) (claimed bool) {
+	start := "-"
+	for {
+		pending := r.XPendingExt(orm, &redis.XPendingExtArgs{Stream: stream, Group: flushAsyncEventsStreamGroup,
+			Idle: asyncConsumerStreamClaimIdle, Start: start, End: "+", Count: asyncConsumerPage})
+		ids := make([]string, 0, len(pending))
+		for _, entry := range pending {
+			if entry.Consumer != consumer {
+				ids = append(ids, entry.ID)
+			}
+		}
+		if len(ids) > 0 {
+			r.XClaimJustID(orm, &redis.XClaimArgs{Stream: stream, Group: flushAsyncEventsStreamGroup, Consumer: consumer,
+				MinIdle: asyncConsumerStreamClaimIdle, Messages: ids})
+			claimed = true
+		}
+		if len(pending) < asyncConsumerPage {
+			return claimed
+		}
+		start = "(" + pending[len(pending)-1].ID
+	}
+}
+
+func readAsyncStreamGroup(orm ORM, r RedisCache, stream string) (group redis.XInfoGroup, has bool) {
+	if r.Exists(orm, stream) == 0 {
+		return group, false
+	}
+	for _, info := range r.XInfoGroups(orm, stream) {
+		if info.Name == flushAsyncEventsStreamGroup {
+			return info, true
+		}
+	}
+	return group, false
+}
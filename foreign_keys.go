@@ -0,0 +1,242 @@
+package beeorm
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	foreignKeyRestrict = "RESTRICT"
+	foreignKeyCascade  = "CASCADE"
+	foreignKeySetNull  = "SET NULL"
+)
+
+type foreignKeyDefinition struct {
+	Name     string
+	Column   string
+	OnDelete string
+	parent   *entitySchema
+}
+
+type foreignKeyChild struct {
+	schema     *entitySchema
+	foreignKey *foreignKeyDefinition
+}
+
+type foreignKeyDB struct {
+	Name            string
+	Column          string
+	ReferencedTable string
+	OnDelete        string
+}
+
+func (e *entitySchema) initForeignKeys(registry *engineRegistryImplementation) error {
+	columns := make([]string, 0)
+	for column := range e.references {
+		if _, has := e.tags[column]["fk"]; has {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		fk := &foreignKeyDefinition{Column: column}
+		switch e.tags[column]["fk"] {
+		case "true", "restrict":
+			fk.OnDelete = foreignKeyRestrict
+		case "cascade":
+			fk.OnDelete = foreignKeyCascade
+		case "setnull":
+			if e.tags[column]["required"] == "true" {
+				return fmt.Errorf("foreign key in field '%s' with setnull can't be required", column)
+			}
+			fk.OnDelete = foreignKeySetNull
+		default:
+			return fmt.Errorf("invalid foreign key action '%s' in field '%s'", e.tags[column]["fk"], column)
+		}
+		if _, isMySQL := e.GetDB().GetConfig().getDialect().(*mysqlDialect); !isMySQL {
+			return fmt.Errorf("foreign key in field '%s' is supported only in MySQL pools", column)
+		}
+		parent := registry.entitySchemas[e.references[column].Type]
+		if parent == nil {
+			return fmt.Errorf("entity '%s' referenced in field '%s' is not registered", e.references[column].Type.String(), column)
+		}
+		if parent.mysqlPoolCode != e.mysqlPoolCode || len(parent.shardPools) > 0 || len(e.shardPools) > 0 {
+			return fmt.Errorf("foreign key in field '%s' can't reference entity '%s' in different pool", column, parent.t.String())
+		}
+		fk.parent = parent
+		fk.Name = "fk_" + e.tableName + "_" + column
+		if len(fk.Name) > 64 {
+			fk.Name = "fk_" + hashString(e.tableName + column)[0:32]
+		}
+		e.foreignKeys = append(e.foreignKeys, fk)
+		parent.foreignKeyChildren = append(parent.foreignKeyChildren, foreignKeyChild{schema: e, foreignKey: fk})
+	}
+	return nil
+}
+
+func addForeignKeyIndexes(schema *entitySchema, indexes map[string]*IndexSchemaDefinition) {
+OUTER:
+	for _, fk := range schema.foreignKeys {
+		for _, index := range indexes {
+			if index.columnsMap[1] == fk.Column {
+				continue OUTER
+			}
+		}
+		indexes[fk.Name] = &IndexSchemaDefinition{Name: fk.Name, columnsMap: map[int]string{1: fk.Column}}
+	}
+}
+
+func getMySQLForeignKeyChanges(orm ORM, schema *entitySchema) (preAlters, postAlters []Alter) {
+	pool := schema.GetDB().Primary()
+	poolCode := pool.GetConfig().GetCode()
+	databaseName := pool.GetConfig().GetDatabaseName()
+	tableName := schema.GetTableName()
+	dbTableName := tableName
+	var skip string
+	if schema.tableRenamedFrom != "" && !pool.QueryRow(orm, NewWhere(fmt.Sprintf("SHOW TABLES LIKE '%s'", tableName)), &skip) {
+		dbTableName = schema.tableRenamedFrom
+	}
+	dbForeignKeys := getMySQLDBForeignKeys(orm, pool, dbTableName)
+	renamedTo := schema.getColumnsRenamedTo()
+	for _, fk := range dbForeignKeys {
+		if newName, isRenamed := renamedTo[fk.Column]; isRenamed {
+			fk.Column = newName
+		}
+	}
+	dropSQL := func(name string) string {
+		return fmt.Sprintf("ALTER TABLE `%s`.`%s` DROP FOREIGN KEY `%s`;", databaseName, tableName, name)
+	}
+	addSQL := func(name, column, referencedTable, onDelete string) string {
+		return fmt.Sprintf("ALTER TABLE `%s`.`%s` ADD CONSTRAINT `%s` FOREIGN KEY (`%s`) REFERENCES `%s`.`%s` (`ID`) ON DELETE %s;",
+			databaseName, tableName, name, column, databaseName, referencedTable, onDelete)
+	}
+	for _, fk := range schema.foreignKeys {
+		current, has := dbForeignKeys[fk.Name]
+		delete(dbForeignKeys, fk.Name)
+		if has && current.Column == fk.Column && current.OnDelete == fk.OnDelete &&
+			(current.ReferencedTable == fk.parent.tableName || current.ReferencedTable == fk.parent.tableRenamedFrom) {
+			continue
+		}
+		if has {
+			preAlters = append(preAlters, Alter{SQL: dropSQL(fk.Name), Safe: true, Pool: poolCode,
+				Down: addSQL(current.Name, current.Column, current.ReferencedTable, current.OnDelete)})
+		}
+		postAlters = append(postAlters, Alter{SQL: addSQL(fk.Name, fk.Column, fk.parent.tableName, fk.OnDelete),
+			Down: dropSQL(fk.Name), Safe: true, Pool: poolCode})
+	}
+	names := make([]string, 0, len(dbForeignKeys))
+	for name := range dbForeignKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		current := dbForeignKeys[name]
+		preAlters = append(preAlters, Alter{SQL: dropSQL(name), Safe: true, Pool: poolCode,
+			Down: addSQL(current.Name, current.Column, current.ReferencedTable, current.OnDelete)})
+	}
+	return
+}
+
+func getMySQLDBForeignKeys(orm ORM, pool DB, tableName string) map[string]*foreignKeyDB {
+	dbForeignKeys := make(map[string]*foreignKeyDB)
+	results, def := pool.Query(orm, "SELECT k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, r.DELETE_RULE "+
+		"FROM information_schema.KEY_COLUMN_USAGE k JOIN information_schema.REFERENTIAL_CONSTRAINTS r "+
+		"ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME "+
+		"WHERE k.TABLE_SCHEMA = ? AND k.TABLE_NAME = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL",
+		pool.GetConfig().GetDatabaseName(), tableName)
+	defer def()
+	for results.Next() {
+		fk := &foreignKeyDB{}
+		results.Scan(&fk.Name, &fk.Column, &fk.ReferencedTable, &fk.OnDelete)
+		dbForeignKeys[fk.Name] = fk
+	}
+	return dbForeignKeys
+}
+
+func (e *entitySchema) hasForeignKeyChildrenToInvalidate() bool {
+	for _, child := range e.foreignKeyChildren {
+		if child.foreignKey.OnDelete != foreignKeyRestrict {
+			return true
+		}
+	}
+	return false
+}
+
+func (orm *ormImplementation) invalidateForeignKeyChildren(schema *entitySchema, ids []uint64, visited map[*entitySchema]map[uint64]bool) {
+	if len(ids) == 0 {
+		return
+	}
+	for _, child := range schema.foreignKeyChildren {
+		if child.foreignKey.OnDelete == foreignKeyRestrict {
+			continue
+		}
+		childSchema := child.schema
+		column := child.foreignKey.Column
+		args := make([]any, len(ids))
+		for i, id := range ids {
+			args[i] = id
+		}
+		query := fmt.Sprintf("SELECT `ID` FROM `%s` WHERE `%s` IN (%s)", childSchema.GetTableName(), column,
+			strings.TrimSuffix(strings.Repeat("?,", len(ids)), ","))
+		var childIDs []uint64
+		func() {
+			results, def := childSchema.GetDB().Primary().Query(orm, query, args...)
+			defer def()
+			for results.Next() {
+				var id uint64
+				results.Scan(&id)
+				if visited[childSchema] == nil {
+					visited[childSchema] = make(map[uint64]bool)
+				}
+				if !visited[childSchema][id] {
+					visited[childSchema][id] = true
+					childIDs = append(childIDs, id)
+				}
+			}
+		}()
+		lc, hasLocalCache := childSchema.GetLocalCache()
+		rc, hasRedisCache := childSchema.GetRedisCache()
+		for _, id := range childIDs {
+			childID := id
+			if hasLocalCache {
+				orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
+					lc.removeEntity(orm, childID)
+				})
+				orm.invalidateLocalCacheEntity(childSchema, childID)
+			}
+			if hasRedisCache {
+				orm.RedisPipeLine(rc.GetCode()).Del(childSchema.getCacheKey() + ":" + strconv.FormatUint(childID, 10))
+			}
+		}
+		if len(childIDs) == 0 {
+			continue
+		}
+		if _, isCached := childSchema.cachedReferences[column]; isCached {
+			for _, id := range ids {
+				parentID := id
+				if hasLocalCache {
+					orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
+						lc.removeReference(orm, column, parentID)
+					})
+					orm.invalidateLocalCacheReference(childSchema, column, parentID)
+				}
+				orm.RedisPipeLine(childSchema.getForcedRedisCode()).Del(childSchema.cacheKey + ":" + column + ":" + strconv.FormatUint(parentID, 10))
+			}
+		}
+		if child.foreignKey.OnDelete != foreignKeyCascade {
+			continue
+		}
+		if childSchema.cacheAll {
+			if hasLocalCache {
+				orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
+					lc.removeReference(orm, cacheAllFakeReferenceKey, 0)
+				})
+				orm.invalidateLocalCacheReference(childSchema, cacheAllFakeReferenceKey, 0)
+			}
+			orm.RedisPipeLine(childSchema.getForcedRedisCode()).Del(childSchema.cacheKey + ":" + cacheAllFakeReferenceKey)
+		}
+		orm.invalidateForeignKeyChildren(childSchema, childIDs, visited)
+	}
+}
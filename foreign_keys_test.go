@@ -0,0 +1,109 @@
+package beeorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type foreignKeyParent struct {
+	ID   uint64 `orm:"localCache;redisCache"`
+	Name string `orm:"required"`
+}
+
+type foreignKeyCascadeChild struct {
+	ID     uint64                      `orm:"localCache;redisCache"`
+	Parent Reference[foreignKeyParent] `orm:"fk=cascade;required"`
+}
+
+type foreignKeySetNullChild struct {
+	ID     uint64                      `orm:"localCache;redisCache"`
+	Parent Reference[foreignKeyParent] `orm:"fk=setnull;index=Parent"`
+}
+
+type foreignKeyInvalid struct {
+	ID     uint64
+	Parent Reference[foreignKeyParent] `orm:"fk=ignore"`
+}
+
+func TestForeignKeys(t *testing.T) {
+	registry := NewRegistry()
+	orm := PrepareTables(t, registry, foreignKeyCascadeChild{}, foreignKeySetNullChild{}, foreignKeyParent{})
+	assert.Len(t, GetAlters(orm), 0)
+
+	schema := GetEntitySchema[foreignKeyCascadeChild](orm).(*entitySchema)
+	assert.Len(t, schema.foreignKeys, 1)
+	assert.Equal(t, "fk_foreignKeyCascadeChild_Parent", schema.foreignKeys[0].Name)
+	assert.Len(t, GetEntitySchema[foreignKeyParent](orm).(*entitySchema).foreignKeyChildren, 2)
+
+	db := orm.Engine().DB(DefaultPoolCode)
+	db.Exec(orm, "ALTER TABLE `foreignKeyCascadeChild` DROP FOREIGN KEY `fk_foreignKeyCascadeChild_Parent`")
+	alters := GetAlters(orm)
+	assert.Len(t, alters, 1)
+	assert.Equal(t, "ALTER TABLE `test`.`foreignKeyCascadeChild` ADD CONSTRAINT `fk_foreignKeyCascadeChild_Parent` "+
+		"FOREIGN KEY (`Parent`) REFERENCES `test`.`foreignKeyParent` (`ID`) ON DELETE CASCADE;", alters[0].SQL)
+	assert.Equal(t, "ALTER TABLE `test`.`foreignKeyCascadeChild` DROP FOREIGN KEY `fk_foreignKeyCascadeChild_Parent`;", alters[0].Down)
+	alters[0].Exec(orm)
+	assert.Len(t, GetAlters(orm), 0)
+
+	parent := NewEntity[foreignKeyParent](orm)
+	parent.Name = "parent"
+	cascadeChild := NewEntity[foreignKeyCascadeChild](orm)
+	cascadeChild.Parent = Reference[foreignKeyParent](parent.ID)
+	setNullChild := NewEntity[foreignKeySetNullChild](orm)
+	setNullChild.Parent = Reference[foreignKeyParent](parent.ID)
+	assert.NoError(t, orm.Flush())
+	_, found := GetByID[foreignKeyCascadeChild](orm, cascadeChild.ID)
+	assert.True(t, found)
+	loaded, found := GetByID[foreignKeySetNullChild](orm, setNullChild.ID)
+	assert.True(t, found)
+	assert.Equal(t, parent.ID, uint64(loaded.Parent))
+
+	DeleteEntity(orm, parent)
+	assert.NoError(t, orm.Flush())
+	_, found = GetByID[foreignKeyCascadeChild](orm, cascadeChild.ID)
+	assert.False(t, found)
+	loaded, found = GetByID[foreignKeySetNullChild](orm, setNullChild.ID)
+	assert.True(t, found)
+	assert.Equal(t, uint64(0), uint64(loaded.Parent))
+
+	parent = NewEntity[foreignKeyParent](orm)
+	parent.Name = "async parent"
+	assert.NoError(t, orm.Flush())
+	DeleteEntity(orm, parent)
+	assert.EqualError(t, orm.FlushAsync(), "entity 'beeorm.foreignKeyParent' referenced by foreign keys can't be deleted with FlushAsync")
+	orm.ClearFlush()
+
+	parent = NewEntity[foreignKeyParent](orm)
+	parent.Name = "bulk parent"
+	cascadeChild = NewEntity[foreignKeyCascadeChild](orm)
+	cascadeChild.Parent = Reference[foreignKeyParent](parent.ID)
+	setNullChild = NewEntity[foreignKeySetNullChild](orm)
+	setNullChild.Parent = Reference[foreignKeyParent](parent.ID)
+	assert.NoError(t, orm.Flush())
+	_, found = GetByID[foreignKeyCascadeChild](orm, cascadeChild.ID)
+	assert.True(t, found)
+	loaded, found = GetByID[foreignKeySetNullChild](orm, setNullChild.ID)
+	assert.True(t, found)
+	assert.Equal(t, parent.ID, uint64(loaded.Parent))
+	assert.NoError(t, DeleteWhere[foreignKeyParent](orm, NewWhere("`Name` = ?", "bulk parent")))
+	assert.EqualError(t, orm.FlushAsync(), "entity 'beeorm.foreignKeyParent' referenced by foreign keys can't be deleted with FlushAsync")
+	assert.NoError(t, orm.Flush())
+	_, found = GetByID[foreignKeyCascadeChild](orm, cascadeChild.ID)
+	assert.False(t, found)
+	loaded, found = GetByID[foreignKeySetNullChild](orm, setNullChild.ID)
+	assert.True(t, found)
+	assert.Equal(t, uint64(0), uint64(loaded.Parent))
+
+	db.Exec(orm, "ALTER TABLE `foreignKeyParent` ADD COLUMN `Old` int DEFAULT NULL")
+	alters = GetAlters(orm)
+	assert.Len(t, alters, 1)
+	assert.False(t, alters[0].CanExecOnline())
+	alters[0].Exec(orm)
+
+	registry = NewRegistry()
+	registry.RegisterMySQL("root:root@tcp(localhost:3377)/test", DefaultPoolCode, &MySQLOptions{})
+	registry.RegisterEntity(foreignKeyParent{}, foreignKeyInvalid{})
+	_, err := registry.Validate()
+	assert.EqualError(t, err, "invalid entity struct 'beeorm.foreignKeyInvalid': invalid foreign key action 'ignore' in field 'Parent'")
+}
@@ -29,6 +29,14 @@ func GetByID[E any](orm ORM, id uint64) (entity *E, found bool) {
 }
 
 func getByID(orm *ormImplementation, id uint64, schema *entitySchema) (any, bool) {
+	entity, found := getByIDWithDeleted(orm, id, schema)
+	if found && schema.isSoftDeleted(entity) {
+		return nil, false
+	}
+	return entity, found
+}
+
+func getByIDWithDeleted(orm *ormImplementation, id uint64, schema *entitySchema) (any, bool) {
 	if schema.hasLocalCache {
 		e, has := schema.localCache.getEntity(orm, id)
 		if has {
@@ -61,32 +69,43 @@ func getByID(orm *ormImplementation, id uint64, schema *entitySchema) (any, bool
 			}
 		}
 	}
-	query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.GetTableName() + "` WHERE ID = ? LIMIT 1"
+	query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.GetTableName() + "` WHERE `ID` = ? LIMIT 1"
 	pointers := prepareScan(schema)
-	found := schema.GetDB().QueryRow(orm, NewWhere(query, id), pointers...)
+	db := schema.getShard(id).GetDB()
+	if schema.hasLocalCache || hasRedis {
+		db = db.Primary()
+	}
+	found := db.QueryRow(orm, NewWhere(query, id), pointers...)
+	fillCache := orm.transaction == nil
 	if found {
 		value := reflect.New(schema.t)
 		entity := value.Interface()
 		deserializeFromDB(schema.fields, value.Elem(), pointers)
-		if schema.hasLocalCache {
+		if schema.hasLocalCache && fillCache {
 			schema.localCache.setEntity(orm, id, entity)
 		}
-		if hasRedis {
+		if hasRedis && fillCache {
 			bind := make(Bind)
 			err := fillBindFromOneSource(orm, bind, reflect.ValueOf(entity).Elem(), schema.fields, "")
 			checkError(err)
 			values := convertBindToRedisValue(bind, schema)
 			cacheRedis.RPush(orm, cacheKey, values...)
+			if schema.redisCacheTTL > 0 {
+				cacheRedis.Expire(orm, cacheKey, schema.redisCacheTTL)
+			}
 		}
 		return entity, true
 	}
-	if schema.hasLocalCache {
+	if schema.hasLocalCache && fillCache {
 		schema.localCache.setEntity(orm, id, nil)
 	}
-	if hasRedis {
+	if hasRedis && fillCache {
 		p := orm.RedisPipeLine(cacheRedis.GetCode())
 		p.Del(cacheKey)
 		p.RPush(cacheKey, cacheNilValue)
+		if schema.redisCacheTTL > 0 {
+			p.Expire(cacheKey, schema.redisCacheTTL)
+		}
 		p.Exec(orm)
 	}
 	return nil, false
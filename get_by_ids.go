@@ -17,8 +17,18 @@ func getByIDs[E any](orm *ormImplementation, ids []uint64) EntityIterator[E] {
 	if schema.hasLocalCache {
 		return &localCacheIDsIterator[E]{orm: orm, schema: schema, ids: ids, index: -1}
 	}
-	results := &entityIterator[E]{index: -1}
+	results := &entityIterator[E]{orm: orm, schema: schema, index: -1}
 	results.rows = make([]*E, len(ids))
+	for i, row := range getByIDsWithoutLocalCache(orm, schema, ids) {
+		if row != nil {
+			results.rows[i] = row.(*E)
+		}
+	}
+	return results
+}
+
+func getByIDsWithoutLocalCache(orm *ormImplementation, schema *entitySchema, ids []uint64) []any {
+	rows := make([]any, len(ids))
 	var missingKeys []int
 	cacheRedis, hasRedisCache := schema.GetRedisCache()
 	var redisPipeline *RedisPipeLine
@@ -37,71 +47,70 @@ func getByIDs[E any](orm *ormImplementation, ids []uint64) EntityIterator[E] {
 					continue
 				}
 				value := reflect.New(schema.t)
-				e := value.Interface().(*E)
+				e := value.Interface()
 				if deserializeFromRedis(row, schema, value.Elem()) && schema.hasLocalCache {
 					schema.localCache.setEntity(orm, id, e)
 				}
-				results.rows[i] = e
+				rows[i] = e
 			} else {
 				missingKeys = append(missingKeys, i)
 			}
 		}
 		if len(missingKeys) == 0 {
-			return results
+			if schema.softDeleteColumn != "" {
+				for i, row := range rows {
+					if row != nil && schema.isSoftDeleted(row) {
+						rows[i] = nil
+					}
+				}
+			}
+			return rows
 		}
 	}
-	sql := "SELECT " + schema.fieldsQuery + " FROM `" + schema.GetTableName() + "` WHERE `ID` IN ("
-	toSearch := 0
+	var toSearch []uint64
 	if len(missingKeys) > 0 {
+		toSearch = make([]uint64, len(missingKeys))
 		for i, key := range missingKeys {
-			if i > 0 {
-				sql += ","
-			}
-			sql += strconv.FormatUint(ids[key], 10)
+			toSearch[i] = ids[key]
 		}
-		toSearch = len(missingKeys)
 	} else {
-		for i, id := range ids {
-			if i > 0 {
-				sql += ","
-			}
-			sql += strconv.FormatUint(id, 10)
-		}
-		toSearch = len(ids)
+		toSearch = ids
 	}
-	sql += ")"
 	execRedisPipeline := false
-	res, def := schema.GetDB().Query(orm, sql)
-	defer def()
 	foundInDB := 0
-	for res.Next() {
+	fillCache := orm.transaction == nil
+	schema.queryByIDs(orm, toSearch, schema.hasLocalCache || hasRedisCache, func(pointers []any) {
 		foundInDB++
-		pointers := prepareScan(schema)
-		res.Scan(pointers...)
 		value := reflect.New(schema.t)
 		deserializeFromDB(schema.fields, value.Elem(), pointers)
 		id := *pointers[0].(*uint64)
 		for i, originalID := range ids { // TODO too slow
 			if id == originalID {
-				results.rows[i] = value.Interface().(*E)
+				rows[i] = value.Interface()
 			}
 		}
+		if !fillCache {
+			return
+		}
 		if schema.hasLocalCache {
-			schema.localCache.setEntity(orm, id, value.Interface().(*E))
+			schema.localCache.setEntity(orm, id, value.Interface())
 		}
 		if hasRedisCache {
 			bind := make(Bind)
 			err := fillBindFromOneSource(orm, bind, value.Elem(), schema.fields, "")
 			checkError(err)
 			values := convertBindToRedisValue(bind, schema)
-			redisPipeline.RPush(schema.getCacheKey()+":"+strconv.FormatUint(id, 10), values...)
+			cacheKey := schema.getCacheKey() + ":" + strconv.FormatUint(id, 10)
+			redisPipeline.RPush(cacheKey, values...)
+			if schema.redisCacheTTL > 0 {
+				redisPipeline.Expire(cacheKey, schema.redisCacheTTL)
+			}
 			execRedisPipeline = true
 		}
-	}
-	def()
-	if foundInDB < toSearch && (schema.hasLocalCache || hasRedisCache) {
+	})
+	if foundInDB < len(toSearch) && fillCache && (schema.hasLocalCache || hasRedisCache) {
 		for i, id := range ids {
-			if results.rows[i] == nil {
+			if rows[i] == nil {
 				if schema.hasLocalCache {
 					schema.localCache.setEntity(orm, id, nil)
 				}
@@ -109,6 +118,9 @@ func getByIDs[E any](orm *ormImplementation, ids []uint64) EntityIterator[E] {
 					cacheKey := schema.getCacheKey() + ":" + strconv.FormatUint(id, 10)
 					redisPipeline.Del(cacheKey)
 					redisPipeline.RPush(cacheKey, cacheNilValue)
+					if schema.redisCacheTTL > 0 {
+						redisPipeline.Expire(cacheKey, schema.redisCacheTTL)
+					}
 					execRedisPipeline = true
 				}
 			}
@@ -117,11 +129,18 @@ func getByIDs[E any](orm *ormImplementation, ids []uint64) EntityIterator[E] {
 	if execRedisPipeline {
 		redisPipeline.Exec(orm)
 	}
-	return results
+	if schema.softDeleteColumn != "" {
+		for i, row := range rows {
+			if row != nil && schema.isSoftDeleted(row) {
+				rows[i] = nil
+			}
+		}
+	}
+	return rows
 }
 
 func warmup(orm *ormImplementation, schema *entitySchema, ids []uint64) {
-	if len(ids) == 0 {
+	if len(ids) == 0 || orm.transaction != nil {
 		return
 	}
 	var missingKeys []int
@@ -175,25 +194,16 @@ func warmup(orm *ormImplementation, schema *entitySchema, ids []uint64) {
 			return
 		}
 	}
-	sql := "SELECT " + schema.fieldsQuery + " FROM `" + schema.GetTableName() + "` WHERE `ID` IN ("
-	for i, key := range missingKeys {
-		if key < 0 {
-			continue
-		}
-		if i > 0 {
-			sql += ","
+	toSearch := make([]uint64, 0, len(missingKeys))
+	for _, key := range missingKeys {
+		if key >= 0 {
+			toSearch = append(toSearch, ids[key])
 		}
-		sql += strconv.FormatUint(ids[key], 10)
 	}
-	sql += ")"
 	execRedisPipeline := false
-	res, def := schema.GetDB().Query(orm, sql)
-	defer def()
 	foundInDB := 0
-	for res.Next() {
+	schema.queryByIDs(orm, toSearch, true, func(pointers []any) {
 		foundInDB++
-		pointers := prepareScan(schema)
-		res.Scan(pointers...)
 		value := reflect.New(schema.t)
 		deserializeFromDB(schema.fields, value.Elem(), pointers)
 		id := *pointers[0].(*uint64)
@@ -212,11 +222,14 @@ func warmup(orm *ormImplementation, schema *entitySchema, ids []uint64) {
 			err := fillBindFromOneSource(orm, bind, value.Elem(), schema.fields, "")
 			checkError(err)
 			values := convertBindToRedisValue(bind, schema)
-			redisPipeline.RPush(schema.getCacheKey()+":"+strconv.FormatUint(id, 10), values...)
+			cacheKey := schema.getCacheKey() + ":" + strconv.FormatUint(id, 10)
+			redisPipeline.RPush(cacheKey, values...)
+			if schema.redisCacheTTL > 0 {
+				redisPipeline.Expire(cacheKey, schema.redisCacheTTL)
+			}
 			execRedisPipeline = true
 		}
-	}
-	def()
+	})
 	if foundInDB < len(missingKeys) && (schema.hasLocalCache || hasRedisCache) {
 		for _, index := range missingKeys {
 			if index >= 0 {
@@ -227,6 +240,9 @@ func warmup(orm *ormImplementation, schema *entitySchema, ids []uint64) {
 					cacheKey := schema.getCacheKey() + ":" + strconv.FormatUint(ids[index], 10)
 					redisPipeline.Del(cacheKey)
 					redisPipeline.RPush(cacheKey, cacheNilValue)
+					if schema.redisCacheTTL > 0 {
+						redisPipeline.Expire(cacheKey, schema.redisCacheTTL)
+					}
 					execRedisPipeline = true
 				}
 			}
@@ -31,6 +31,16 @@ func GetByReference[E any](orm ORM, referenceName string, id uint64) EntityItera
 }
 
 func getCachedList[E any](orm ORM, referenceName string, id uint64, hasLocalCache bool, lc LocalCache, schema, resultSchema *entitySchema) EntityIterator[E] {
+	var where Where
+	if id > 0 {
+		where = NewWhere("`"+referenceName+"` = ?", id)
+	} else {
+		where = allEntitiesWhere
+	}
+	if orm.(*ormImplementation).transaction != nil {
+		return Search[E](orm, where, nil)
+	}
+	where = withPrimary(where)
 	if hasLocalCache {
 		fromCache, hasInCache := lc.getReference(orm, referenceName, id)
 		if hasInCache {
@@ -39,7 +49,7 @@ func getCachedList[E any](orm ORM, referenceName string, id uint64, hasLocalCach
 			}
 
 			if resultSchema.hasLocalCache {
-				results := &entityIterator[E]{index: -1}
+				results := &entityIterator[E]{orm: orm.(*ormImplementation), schema: getEntitySchema[E](orm), index: -1}
 				results.rows = fromCache.([]*E)
 				return results
 			}
@@ -92,12 +102,6 @@ func getCachedList[E any](orm ORM, referenceName string, id uint64, hasLocalCach
 		}
 	}
 	if hasLocalCache {
-		var where Where
-		if id > 0 {
-			where = NewWhere("`"+referenceName+"` = ?", id)
-		} else {
-			where = allEntitiesWhere
-		}
 		ids := SearchIDs[E](orm, where, nil)
 		if len(ids) == 0 {
 			lc.setReference(orm, referenceName, id, cacheNilValue)
@@ -121,12 +125,6 @@ func getCachedList[E any](orm ORM, referenceName string, id uint64, hasLocalCach
 		}
 		return values
 	}
-	var where Where
-	if id > 0 {
-		where = NewWhere("`"+referenceName+"` = ?", id)
-	} else {
-		where = allEntitiesWhere
-	}
 	values := Search[E](orm, where, nil)
 	if values.Len() == 0 {
 		rc.SAdd(orm, redisSetKey, redisValidSetValue, cacheNilValue)
@@ -0,0 +1,173 @@
+package beeorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const defaultIterateBatchSize = 1000
+
+var errCursorClosed = errors.New("cursor closed")
+
+type IterateProgress struct {
+	Processed int
+	LastID    uint64
+}
+
+type Cursor[E any] struct {
+	entities  chan *E
+	done      chan struct{}
+	closeOnce sync.Once
+	mutex     sync.Mutex
+	progress  IterateProgress
+	err       error
+}
+
+func IterateAll[E any](orm ORM, where Where, batchSize int, f func(entity *E) error) error {
+	return IterateAllWithProgress[E](orm, where, batchSize, f, nil)
+}
+
+func IterateAllWithProgress[E any](orm ORM, where Where, batchSize int, f func(entity *E) error, progress func(progress IterateProgress)) error {
+	err := checkWhereWithoutTail(where, "IterateAll")
+	if err != nil {
+		return err
+	}
+	return iterateAll(orm, getEntitySchema[E](orm), where, batchSize, func(value any) error {
+		return f(value.(*E))
+	}, progress)
+}
+
+func NewCursor[E any](orm ORM, where Where, batchSize int) *Cursor[E] {
+	if batchSize <= 0 {
+		batchSize = defaultIterateBatchSize
+	}
+	schema := getEntitySchema[E](orm)
+	c := &Cursor[E]{entities: make(chan *E, batchSize), done: make(chan struct{})}
+	c.err = checkWhereWithoutTail(where, "NewCursor")
+	if c.err != nil {
+		close(c.entities)
+		return c
+	}
+	go func() {
+		defer close(c.entities)
+		defer func() {
+			if rec := recover(); rec != nil {
+				asErr, isError := rec.(error)
+				if !isError {
+					asErr = fmt.Errorf("%v", rec)
+				}
+				c.setError(asErr)
+			}
+		}()
+		err := iterateAll(orm, schema, where, batchSize, func(value any) error {
+			select {
+			case c.entities <- value.(*E):
+				return nil
+			case <-c.done:
+				return errCursorClosed
+			case <-orm.Context().Done():
+				return orm.Context().Err()
+			}
+		}, func(progress IterateProgress) {
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+			c.progress = progress
+		})
+		if err != nil && err != errCursorClosed {
+			c.setError(err)
+		}
+	}()
+	return c
+}
+
+func (c *Cursor[E]) Entities() <-chan *E {
+	return c.entities
+}
+
+func (c *Cursor[E]) Progress() IterateProgress {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.progress
+}
+
+func (c *Cursor[E]) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.err
+}
+
+func (c *Cursor[E]) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	for range c.entities {
+	}
+}
+
+func (c *Cursor[E]) setError(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.err = err
+}
+
+func iterateAll(orm ORM, schema *entitySchema, where Where, batchSize int, f func(value any) error, progress func(progress IterateProgress)) error {
+	if batchSize <= 0 {
+		batchSize = defaultIterateBatchSize
+	}
+	if where == nil {
+		where = NewWhere("1")
+	}
+	shards := schema.getWhereShards(where)
+	where = schema.applySoftDelete(where)
+	conditions := where.String()
+	if strings.TrimSpace(conditions) == "" {
+		conditions = "1"
+	}
+	/* #nosec */
+	query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.GetTableName() + "` WHERE `ID` > ? AND (" +
+		conditions + ") ORDER BY `ID` LIMIT " + strconv.Itoa(batchSize)
+	ctx := orm.Context()
+	state := IterateProgress{}
+	for _, shard := range shards {
+		lastID := uint64(0)
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			entities := make([]any, 0, batchSize)
+			func() {
+				rows, closeRows := shard.GetDB().Query(orm, query, append([]any{lastID}, where.GetParameters()...)...)
+				defer closeRows()
+				for rows.Next() {
+					pointers := prepareScan(schema)
+					rows.Scan(pointers...)
+					value := reflect.New(schema.t)
+					deserializeFromDB(schema.fields, value.Elem(), pointers)
+					entities = append(entities, value.Interface())
+					lastID = *pointers[0].(*uint64)
+				}
+			}()
+			for _, entity := range entities {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := f(entity); err != nil {
+					return err
+				}
+				state.Processed++
+				state.LastID = reflect.ValueOf(entity).Elem().Field(0).Uint()
+			}
+			if progress != nil && len(entities) > 0 {
+				progress(state)
+			}
+			if len(entities) < batchSize {
+				break
+			}
+		}
+	}
+	return nil
+}
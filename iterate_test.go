@@ -0,0 +1,109 @@
+package beeorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type iterateEntity struct {
+	ID   uint64 `orm:"localCache"`
+	Name string
+	Age  uint8
+}
+
+func TestIterateAll(t *testing.T) {
+	var entity *iterateEntity
+	orm := PrepareTables(t, NewRegistry(), entity)
+	schema := GetEntitySchema[iterateEntity](orm)
+
+	for i := 1; i <= 25; i++ {
+		entity = NewEntity[iterateEntity](orm)
+		entity.Name = fmt.Sprintf("Name %d", i)
+		entity.Age = uint8(i % 2)
+	}
+	assert.NoError(t, orm.Flush())
+	lc, _ := schema.GetLocalCache()
+	lc.Clear(orm)
+
+	var names []string
+	var progress []IterateProgress
+	err := IterateAllWithProgress[iterateEntity](orm, nil, 10, func(entity *iterateEntity) error {
+		names = append(names, entity.Name)
+		return nil
+	}, func(p IterateProgress) {
+		progress = append(progress, p)
+	})
+	assert.NoError(t, err)
+	assert.Len(t, names, 25)
+	assert.Equal(t, "Name 1", names[0])
+	assert.Equal(t, "Name 25", names[24])
+	assert.Len(t, progress, 3)
+	assert.Equal(t, 25, progress[2].Processed)
+	_, hit := lc.getEntity(orm, progress[2].LastID)
+	assert.False(t, hit)
+
+	count := 0
+	err = IterateAll[iterateEntity](orm, NewWhere("`Age` = ?", 1), 5, func(_ *iterateEntity) error {
+		count++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 13, count)
+
+	stop := errors.New("stop")
+	count = 0
+	err = IterateAll[iterateEntity](orm, nil, 5, func(_ *iterateEntity) error {
+		count++
+		if count == 7 {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 7, count)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := orm.CloneWithContext(ctx)
+	count = 0
+	err = IterateAll[iterateEntity](cancelled, nil, 5, func(_ *iterateEntity) error {
+		count++
+		if count == 3 {
+			cancel()
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 3, count)
+
+	cursor := NewCursor[iterateEntity](orm, NewWhere("`Age` = ?", 0), 4)
+	count = 0
+	for range cursor.Entities() {
+		count++
+	}
+	assert.NoError(t, cursor.Err())
+	assert.Equal(t, 12, count)
+	assert.Equal(t, 12, cursor.Progress().Processed)
+
+	cursor = NewCursor[iterateEntity](orm, nil, 2)
+	<-cursor.Entities()
+	cursor.Close()
+	assert.NoError(t, cursor.Err())
+
+	count = 0
+	err = IterateAll[iterateEntity](orm, NewWhere("`Age` = ? LIMIT 5", 1), 10, func(_ *iterateEntity) error {
+		count++
+		return nil
+	})
+	assert.EqualError(t, err, "IterateAll doesn't support ORDER BY, GROUP BY or LIMIT in where: 'LIMIT 5'")
+	assert.Equal(t, 0, count)
+	cursor = NewCursor[iterateEntity](orm, NewWhere("1 ORDER BY `Name`"), 10)
+	for range cursor.Entities() {
+		count++
+	}
+	assert.EqualError(t, cursor.Err(), "NewCursor doesn't support ORDER BY, GROUP BY or LIMIT in where: 'ORDER BY `Name`'")
+	assert.Equal(t, 0, count)
+}
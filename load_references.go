@@ -0,0 +1,86 @@
+package beeorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+func loadReferences(orm *ormImplementation, schema *entitySchema, entities []reflect.Value, paths []string) {
+	if len(entities) == 0 {
+		return
+	}
+	var columns []string
+	nested := make(map[string][]string)
+	for _, path := range paths {
+		parts := strings.SplitN(path, "/", 2)
+		if _, has := schema.references[parts[0]]; !has {
+			panic(fmt.Errorf("invalid reference name %s", path))
+		}
+		if _, has := nested[parts[0]]; !has {
+			columns = append(columns, parts[0])
+			nested[parts[0]] = nil
+		}
+		if len(parts) > 1 && parts[1] != "" {
+			nested[parts[0]] = append(nested[parts[0]], parts[1])
+		}
+	}
+	for _, column := range columns {
+		ids := getReferenceIDs(schema, column, entities)
+		if len(ids) == 0 {
+			continue
+		}
+		refSchema := orm.Engine().Registry().EntitySchema(schema.references[column].Type).(*entitySchema)
+		if len(nested[column]) == 0 {
+			if refSchema.hasLocalCache || refSchema.hasRedisCache {
+				warmup(orm, refSchema, ids)
+			}
+			continue
+		}
+		loadReferences(orm, refSchema, getReferencedEntities(orm, refSchema, ids), nested[column])
+	}
+}
+
+func getReferenceIDs(schema *entitySchema, column string, entities []reflect.Value) []uint64 {
+	getter := schema.fieldGetters[column]
+	unique := make(map[uint64]bool)
+	var ids []uint64
+	appendID := func(value reflect.Value) {
+		id := value.Uint()
+		if id > 0 && !unique[id] {
+			unique[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, entity := range entities {
+		value := reflect.ValueOf(getter(entity))
+		if value.Kind() == reflect.Array {
+			for i := 0; i < value.Len(); i++ {
+				appendID(value.Index(i))
+			}
+		} else {
+			appendID(value)
+		}
+	}
+	return ids
+}
+
+func getReferencedEntities(orm *ormImplementation, schema *entitySchema, ids []uint64) []reflect.Value {
+	entities := make([]reflect.Value, 0, len(ids))
+	if !schema.hasLocalCache {
+		for _, entity := range getByIDsWithoutLocalCache(orm, schema, ids) {
+			if entity != nil {
+				entities = append(entities, reflect.ValueOf(entity).Elem())
+			}
+		}
+		return entities
+	}
+	warmup(orm, schema, ids)
+	for _, id := range ids {
+		entity, found := getByID(orm, id, schema)
+		if found {
+			entities = append(entities, reflect.ValueOf(entity).Elem())
+		}
+	}
+	return entities
+}
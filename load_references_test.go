@@ -105,3 +105,67 @@ func testLoadReferences(t *testing.T, local, redis bool) {
 	assert.Equal(t, 10, i)
 
 }
+
+func TestLoadReferencesNestedLocal(t *testing.T) {
+	testLoadReferencesNested(t, true, false)
+}
+
+func TestLoadReferencesNestedRedis(t *testing.T) {
+	testLoadReferencesNested(t, false, true)
+}
+
+func testLoadReferencesNested(t *testing.T, local, redis bool) {
+	var entity *loadReferenceEntity
+	var ref1 *loadSubReferenceEntity1
+	var ref2 *loadSubReferenceEntity2
+	orm := PrepareTables(t, NewRegistry(), entity, ref1, ref2)
+	schema := GetEntitySchema[loadReferenceEntity](orm)
+	schema.DisableCache(!local, !redis)
+	GetEntitySchema[loadSubReferenceEntity1](orm).DisableCache(!local, !redis)
+	GetEntitySchema[loadSubReferenceEntity2](orm).DisableCache(!local, !redis)
+
+	var ids []uint64
+	for i := 1; i <= 10; i++ {
+		entity = NewEntity[loadReferenceEntity](orm)
+		entity.Name = fmt.Sprintf("Entity %d", i)
+		ref1 = NewEntity[loadSubReferenceEntity1](orm)
+		ref1.Name = fmt.Sprintf("Ref1 %d", i)
+		ref2 = NewEntity[loadSubReferenceEntity2](orm)
+		ref2.Name = fmt.Sprintf("Ref2 %d", i)
+		ref1.SubRef2 = Reference[loadSubReferenceEntity2](ref2.ID)
+		entity.Ref1a = Reference[loadSubReferenceEntity1](ref1.ID)
+		entity.Ref1Array[1] = Reference[loadSubReferenceEntity1](ref1.ID)
+		ids = append(ids, entity.ID)
+	}
+	assert.NoError(t, orm.Flush())
+	if local {
+		schema.(*entitySchema).localCache.Clear(orm)
+		GetEntitySchema[loadSubReferenceEntity1](orm).(*entitySchema).localCache.Clear(orm)
+		GetEntitySchema[loadSubReferenceEntity2](orm).(*entitySchema).localCache.Clear(orm)
+	}
+	if redis {
+		orm.Engine().Redis(DefaultPoolCode).FlushDB(orm)
+	}
+
+	iterator := GetByIDs[loadReferenceEntity](orm, ids...)
+	iterator.All()
+	loggerDB := &MockLogHandler{}
+	orm.RegisterQueryLogger(loggerDB, true, false, false)
+	iterator.LoadReference("Ref1a/SubRef2", "Ref1Array_1")
+	assert.Len(t, loggerDB.Logs, 2)
+	loggerDB.Clear()
+	i := 0
+	for iterator.Next() {
+		entity = iterator.Entity()
+		ref1 = entity.Ref1a.GetEntity(orm)
+		assert.Equal(t, fmt.Sprintf("Ref1 %d", i+1), ref1.Name)
+		assert.Equal(t, fmt.Sprintf("Ref2 %d", i+1), ref1.SubRef2.GetEntity(orm).Name)
+		i++
+	}
+	assert.Equal(t, 10, i)
+	assert.Len(t, loggerDB.Logs, 0)
+
+	assert.PanicsWithError(t, "invalid reference name Invalid/SubRef2", func() {
+		iterator.LoadReference("Invalid/SubRef2")
+	})
+}
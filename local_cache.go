@@ -6,6 +6,7 @@ import (
 	"hash/maphash"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/puzpuzpuz/xsync/v2"
 )
@@ -13,12 +14,14 @@ import (
 type LocalCacheConfig interface {
 	GetCode() string
 	GetLimit() int
+	GetTTL() time.Duration
 	GetSchema() EntitySchema
 }
 
 type localCacheConfig struct {
 	code   string
 	limit  int
+	ttl    time.Duration
 	schema EntitySchema
 }
 
@@ -30,20 +33,26 @@ func (c *localCacheConfig) GetLimit() int {
 	return c.limit
 }
 
+func (c *localCacheConfig) GetTTL() time.Duration {
+	return c.ttl
+}
+
 func (c *localCacheConfig) GetSchema() EntitySchema {
 	return c.schema
 }
 
 type LocalCacheUsage struct {
-	Type      string
-	Limit     uint64
-	Used      uint64
-	Evictions uint64
+	Type        string
+	Limit       uint64
+	Used        uint64
+	Evictions   uint64
+	Expirations uint64
 }
 
 type localCacheElement struct {
 	value      any
 	lruElement *list.Element
+	expires    int64
 }
 
 type LocalCache interface {
@@ -76,10 +85,15 @@ type localCache struct {
 	evictions              uint64
 	evictionsEntities      uint64
 	evictionsReferences    map[string]*uint64
+	expirationsEntities    uint64
+	expirationsReferences  map[string]*uint64
 }
 
 func newLocalCache(code string, limit int, schema *entitySchema) *localCache {
 	c := &localCache{config: &localCacheConfig{code: code, limit: limit, schema: schema}}
+	if schema != nil {
+		c.config.ttl = schema.localCacheTTL
+	}
 	if limit > 0 {
 		c.cacheLimit = xsync.NewMapOf[*localCacheElement]()
 		c.cacheLRU = list.New()
@@ -109,7 +123,10 @@ func newLocalCache(code string, limit int, schema *entitySchema) *localCache {
 			} else {
 				c.cacheReferencesNoLimit = make(map[string]*xsync.MapOf[uint64, any])
 			}
+			c.expirationsReferences = make(map[string]*uint64)
 			for reference := range schema.cachedReferences {
+				expirations := uint64(0)
+				c.expirationsReferences[reference] = &expirations
 				if limit > 0 {
 					c.cacheReferencesLimit[reference] = xsync.NewTypedMapOf[uint64, *localCacheElement](func(seed maphash.Seed, u uint64) uint64 {
 						return u
@@ -125,6 +142,8 @@ func newLocalCache(code string, limit int, schema *entitySchema) *localCache {
 				}
 			}
 			if schema.cacheAll {
+				expirations := uint64(0)
+				c.expirationsReferences[cacheAllFakeReferenceKey] = &expirations
 				if limit > 0 {
 					c.cacheReferencesLimit[cacheAllFakeReferenceKey] = xsync.NewTypedMapOf[uint64, *localCacheElement](func(seed maphash.Seed, u uint64) uint64 {
 						return u
@@ -174,6 +193,14 @@ func (lc *localCache) Get(orm ORM, key string) (value any, ok bool) {
 func (lc *localCache) getEntity(orm ORM, id uint64) (value any, ok bool) {
 	if lc.config.limit > 0 {
 		val, has := lc.cacheEntitiesLimit.Load(id)
+		if has && lc.isExpired(val) {
+			expired, deleted := lc.cacheEntitiesLimit.LoadAndDelete(id)
+			if deleted {
+				lc.cacheEntitiesLRU.Remove(expired.lruElement)
+				atomic.AddUint64(&lc.expirationsEntities, 1)
+			}
+			has = false
+		}
 		hasLog, _ := orm.getLocalCacheLoggers()
 		if hasLog {
 			lc.fillLogFields(orm, "GET", fmt.Sprintf("GET ENTITY %d", id), !has)
@@ -187,6 +214,16 @@ func (lc *localCache) getEntity(orm ORM, id uint64) (value any, ok bool) {
 		return nil, false
 	}
 	value, ok = lc.cacheEntitiesNoLimit.Load(id)
+	if ok && lc.config.ttl > 0 {
+		element := value.(*localCacheElement)
+		value = element.value
+		if lc.isExpired(element) {
+			if _, deleted := lc.cacheEntitiesNoLimit.LoadAndDelete(id); deleted {
+				atomic.AddUint64(&lc.expirationsEntities, 1)
+			}
+			value, ok = nil, false
+		}
+	}
 	hasLog, _ := orm.getLocalCacheLoggers()
 	if hasLog {
 		lc.fillLogFields(orm, "GET", fmt.Sprintf("GET ENTITY %d", id), !ok)
@@ -198,6 +235,14 @@ func (lc *localCache) getReference(orm ORM, reference string, id uint64) (value
 	if lc.config.limit > 0 {
 		c := lc.cacheReferencesLimit[reference]
 		val, has := c.Load(id)
+		if has && lc.isExpired(val) {
+			expired, deleted := c.LoadAndDelete(id)
+			if deleted {
+				lc.cacheReferencesLRU[reference].Remove(expired.lruElement)
+				atomic.AddUint64(lc.expirationsReferences[reference], 1)
+			}
+			has = false
+		}
 		hasLog, _ := orm.getLocalCacheLoggers()
 		if hasLog {
 			lc.fillLogFields(orm, "GET", fmt.Sprintf("GET REFERENCE %s %d", reference, id), !has)
@@ -211,6 +256,16 @@ func (lc *localCache) getReference(orm ORM, reference string, id uint64) (value
 		return nil, false
 	}
 	value, ok = lc.cacheReferencesNoLimit[reference].Load(id)
+	if ok && lc.config.ttl > 0 {
+		element := value.(*localCacheElement)
+		value = element.value
+		if lc.isExpired(element) {
+			if _, deleted := lc.cacheReferencesNoLimit[reference].LoadAndDelete(id); deleted {
+				atomic.AddUint64(lc.expirationsReferences[reference], 1)
+			}
+			value, ok = nil, false
+		}
+	}
 	hasLog, _ := orm.getLocalCacheLoggers()
 	if hasLog {
 		lc.fillLogFields(orm, "GET", fmt.Sprintf("GET REFERENCE %s %d", reference, id), !ok)
@@ -245,7 +300,7 @@ func (lc *localCache) Set(orm ORM, key string, value any) {
 func (lc *localCache) setEntity(orm ORM, id uint64, value any) {
 	if lc.config.limit > 0 {
 		element := lc.cacheEntitiesLRU.PushFront(id)
-		lc.cacheEntitiesLimit.Store(id, &localCacheElement{lruElement: element, value: value})
+		lc.cacheEntitiesLimit.Store(id, &localCacheElement{lruElement: element, value: value, expires: lc.expiresAt()})
 		if lc.cacheEntitiesLimit.Size() > lc.config.limit {
 			toRemove := lc.cacheEntitiesLRU.Back()
 			if toRemove != nil {
@@ -259,7 +314,11 @@ func (lc *localCache) setEntity(orm ORM, id uint64, value any) {
 		}
 		return
 	}
-	lc.cacheEntitiesNoLimit.Store(id, value)
+	if lc.config.ttl > 0 {
+		lc.cacheEntitiesNoLimit.Store(id, &localCacheElement{value: value, expires: lc.expiresAt()})
+	} else {
+		lc.cacheEntitiesNoLimit.Store(id, value)
+	}
 	hasLog, _ := orm.getLocalCacheLoggers()
 	if hasLog {
 		lc.fillLogFields(orm, "SET", fmt.Sprintf("SET ENTITY %d [entity value]", id), false)
@@ -270,7 +329,7 @@ func (lc *localCache) setReference(orm ORM, reference string, id uint64, value a
 	if lc.config.limit > 0 {
 		element := lc.cacheEntitiesLRU.PushFront(id)
 		c := lc.cacheReferencesLimit[reference]
-		c.Store(id, &localCacheElement{lruElement: element, value: value})
+		c.Store(id, &localCacheElement{lruElement: element, value: value, expires: lc.expiresAt()})
 		lru := lc.cacheReferencesLRU[reference]
 		if c.Size() > lc.config.limit {
 			toRemove := lru.Back()
@@ -285,7 +344,11 @@ func (lc *localCache) setReference(orm ORM, reference string, id uint64, value a
 		}
 		return
 	}
-	lc.cacheReferencesNoLimit[reference].Store(id, value)
+	if lc.config.ttl > 0 {
+		lc.cacheReferencesNoLimit[reference].Store(id, &localCacheElement{value: value, expires: lc.expiresAt()})
+	} else {
+		lc.cacheReferencesNoLimit[reference].Store(id, value)
+	}
 	hasLog, _ := orm.getLocalCacheLoggers()
 	if hasLog {
 		lc.fillLogFields(orm, "SET", fmt.Sprintf("SET REFERENCE %s %d %v", reference, id, value), false)
@@ -376,10 +439,10 @@ func (lc *localCache) GetUsage() []LocalCacheUsage {
 			return []LocalCacheUsage{{Type: "Global", Used: uint64(lc.cacheLimit.Size()), Limit: uint64(lc.config.limit), Evictions: lc.evictions}}
 		}
 		usage := make([]LocalCacheUsage, len(lc.cacheReferencesLimit)+1)
-		usage[0] = LocalCacheUsage{Type: "Entities " + lc.config.schema.GetType().String(), Used: uint64(lc.cacheEntitiesLimit.Size()), Limit: uint64(lc.config.limit), Evictions: lc.evictionsEntities}
+		usage[0] = LocalCacheUsage{Type: "Entities " + lc.config.schema.GetType().String(), Used: uint64(lc.cacheEntitiesLimit.Size()), Limit: uint64(lc.config.limit), Evictions: lc.evictionsEntities, Expirations: lc.expirationsEntities}
 		i := 1
 		for refName, references := range lc.cacheReferencesLimit {
-			usage[i] = LocalCacheUsage{Type: "Reference " + refName + " of " + lc.config.schema.GetType().String(), Used: uint64(references.Size()), Limit: uint64(lc.config.limit), Evictions: *lc.evictionsReferences[refName]}
+			usage[i] = LocalCacheUsage{Type: "Reference " + refName + " of " + lc.config.schema.GetType().String(), Used: uint64(references.Size()), Limit: uint64(lc.config.limit), Evictions: *lc.evictionsReferences[refName], Expirations: *lc.expirationsReferences[refName]}
 			i++
 		}
 		return usage
@@ -388,15 +451,26 @@ func (lc *localCache) GetUsage() []LocalCacheUsage {
 		return []LocalCacheUsage{{Type: "Global", Used: uint64(lc.cacheNoLimit.Size()), Limit: 0, Evictions: 0}}
 	}
 	usage := make([]LocalCacheUsage, len(lc.cacheReferencesNoLimit)+1)
-	usage[0] = LocalCacheUsage{Type: "Entities " + lc.config.schema.GetType().String(), Used: uint64(lc.cacheEntitiesNoLimit.Size()), Limit: 0, Evictions: 0}
+	usage[0] = LocalCacheUsage{Type: "Entities " + lc.config.schema.GetType().String(), Used: uint64(lc.cacheEntitiesNoLimit.Size()), Limit: 0, Evictions: 0, Expirations: lc.expirationsEntities}
 	i := 1
 	for refName, references := range lc.cacheReferencesNoLimit {
-		usage[i] = LocalCacheUsage{Type: "Reference " + refName + " of " + lc.config.schema.GetType().String(), Used: uint64(references.Size()), Limit: 0, Evictions: 0}
+		usage[i] = LocalCacheUsage{Type: "Reference " + refName + " of " + lc.config.schema.GetType().String(), Used: uint64(references.Size()), Limit: 0, Evictions: 0, Expirations: *lc.expirationsReferences[refName]}
 		i++
 	}
 	return usage
 }
 
+func (lc *localCache) expiresAt() int64 {
+	if lc.config.ttl > 0 {
+		return time.Now().Add(lc.config.ttl).UnixNano()
+	}
+	return 0
+}
+
+func (lc *localCache) isExpired(element *localCacheElement) bool {
+	return element.expires > 0 && element.expires <= time.Now().UnixNano()
+}
+
 func (lc *localCache) fillLogFields(orm ORM, operation, query string, cacheMiss bool) {
 	_, loggers := orm.getLocalCacheLoggers()
 	fillLogFields(orm, loggers, lc.config.code, sourceLocalCache, operation, query, nil, cacheMiss, nil)
@@ -0,0 +1,191 @@
+package beeorm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/redis/go-redis/v9"
+)
+
+const localCacheInvalidationChannel = "beeorm_local_cache_invalidation"
+const asyncEventLocalCacheInvalidation = "beeorm:local_cache_invalidation"
+
+type localCacheInvalidation struct {
+	Node       string                         `json:"n"`
+	Entities   map[string][]uint64            `json:"e,omitempty"`
+	References map[string]map[string][]uint64 `json:"r,omitempty"`
+}
+
+func getLocalCacheInvalidationChannel(config RedisPoolConfig) string {
+	return localCacheInvalidationChannel + ":" + strconv.Itoa(config.GetDatabaseNumber())
+}
+
+func (orm *ormImplementation) getLocalCacheInvalidation(schema *entitySchema) *localCacheInvalidation {
+	code := schema.getForcedRedisCode()
+	if !orm.engine.Redis(code).GetConfig().HasLocalCacheInvalidation() {
+		return nil
+	}
+	if orm.localCacheInvalidations == nil {
+		orm.localCacheInvalidations = make(map[string]*localCacheInvalidation)
+	}
+	invalidation, has := orm.localCacheInvalidations[code]
+	if !has {
+		invalidation = &localCacheInvalidation{Node: orm.engine.nodeID}
+		orm.localCacheInvalidations[code] = invalidation
+	}
+	return invalidation
+}
+
+func (orm *ormImplementation) invalidateLocalCacheEntity(schema *entitySchema, id uint64) {
+	invalidation := orm.getLocalCacheInvalidation(schema)
+	if invalidation == nil {
+		return
+	}
+	if invalidation.Entities == nil {
+		invalidation.Entities = make(map[string][]uint64)
+	}
+	invalidation.Entities[schema.cacheKey] = append(invalidation.Entities[schema.cacheKey], id)
+}
+
+func (orm *ormImplementation) invalidateLocalCacheReference(schema *entitySchema, reference string, id uint64) {
+	invalidation := orm.getLocalCacheInvalidation(schema)
+	if invalidation == nil {
+		return
+	}
+	if invalidation.References == nil {
+		invalidation.References = make(map[string]map[string][]uint64)
+	}
+	references, has := invalidation.References[schema.cacheKey]
+	if !has {
+		references = make(map[string][]uint64)
+		invalidation.References[schema.cacheKey] = references
+	}
+	references[reference] = append(references[reference], id)
+}
+
+func (orm *ormImplementation) publishLocalCacheInvalidations() {
+	for code, invalidation := range orm.localCacheInvalidations {
+		asJSON, _ := jsoniter.ConfigFastest.MarshalToString(invalidation)
+		channel := getLocalCacheInvalidationChannel(orm.engine.Redis(code).GetConfig())
+		orm.RedisPipeLine(code).Publish(channel, asJSON)
+	}
+	orm.localCacheInvalidations = nil
+}
+
+func (orm *ormImplementation) publishAsyncLocalCacheInvalidations() {
+	for code, invalidation := range orm.localCacheInvalidations {
+		bySchema := make(map[*entitySchema]*localCacheInvalidation)
+		getPart := func(cacheKey string) *localCacheInvalidation {
+			schema := orm.engine.registry.localCacheSchemas[cacheKey]
+			part, has := bySchema[schema]
+			if !has {
+				part = &localCacheInvalidation{Node: invalidation.Node}
+				bySchema[schema] = part
+			}
+			return part
+		}
+		for cacheKey, ids := range invalidation.Entities {
+			part := getPart(cacheKey)
+			if part.Entities == nil {
+				part.Entities = make(map[string][]uint64)
+			}
+			part.Entities[cacheKey] = ids
+		}
+		for cacheKey, references := range invalidation.References {
+			part := getPart(cacheKey)
+			if part.References == nil {
+				part.References = make(map[string]map[string][]uint64)
+			}
+			part.References[cacheKey] = references
+		}
+		for schema, part := range bySchema {
+			if schema == nil {
+				continue
+			}
+			asJSON, _ := jsoniter.ConfigFastest.MarshalToString(part)
+			orm.publishAsyncEvent(schema, asyncTemporaryQueueEvent{asyncEventLocalCacheInvalidation, code, asJSON})
+		}
+	}
+	orm.localCacheInvalidations = nil
+}
+
+func publishAsyncEventLocalCacheInvalidation(orm ORM, data []any) {
+	if len(data) != 3 {
+		return
+	}
+	code, _ := data[1].(string)
+	payload, _ := data[2].(string)
+	r := orm.Engine().Redis(code)
+	if r == nil || payload == "" {
+		return
+	}
+	r.Publish(orm, getLocalCacheInvalidationChannel(r.GetConfig()), payload)
+}
+
+func (e *engineImplementation) subscribeLocalCacheInvalidation() error {
+	nodeID := make([]byte, 16)
+	_, _ = rand.Read(nodeID)
+	e.nodeID = hex.EncodeToString(nodeID)
+	if len(e.registry.localCacheSchemas) == 0 {
+		return nil
+	}
+	for _, r := range e.redisServers {
+		config := r.GetConfig()
+		if !config.HasLocalCacheInvalidation() {
+			continue
+		}
+		pubSub := config.getClient().Subscribe(context.Background(), getLocalCacheInvalidationChannel(config))
+		_, err := pubSub.Receive(context.Background())
+		if err != nil {
+			_ = pubSub.Close()
+			return err
+		}
+		e.closers = append(e.closers, func() {
+			_ = pubSub.Close()
+		})
+		go e.consumeLocalCacheInvalidation(pubSub)
+	}
+	return nil
+}
+
+func (e *engineImplementation) consumeLocalCacheInvalidation(pubSub *redis.PubSub) {
+	orm := e.NewORM(context.Background())
+	for message := range pubSub.Channel() {
+		invalidation := &localCacheInvalidation{}
+		err := jsoniter.ConfigFastest.UnmarshalFromString(message.Payload, invalidation)
+		if err != nil || invalidation.Node == e.nodeID {
+			continue
+		}
+		e.handleLocalCacheInvalidation(orm, invalidation)
+	}
+}
+
+func (e *engineImplementation) handleLocalCacheInvalidation(orm ORM, invalidation *localCacheInvalidation) {
+	for cacheKey, ids := range invalidation.Entities {
+		schema, has := e.registry.localCacheSchemas[cacheKey]
+		if !has {
+			continue
+		}
+		for _, id := range ids {
+			schema.localCache.removeEntity(orm, id)
+		}
+	}
+	for cacheKey, references := range invalidation.References {
+		schema, has := e.registry.localCacheSchemas[cacheKey]
+		if !has {
+			continue
+		}
+		for reference, ids := range references {
+			_, isReference := schema.cachedReferences[reference]
+			if !isReference && !(reference == cacheAllFakeReferenceKey && schema.cacheAll) {
+				continue
+			}
+			for _, id := range ids {
+				schema.localCache.removeReference(orm, reference, id)
+			}
+		}
+	}
+}
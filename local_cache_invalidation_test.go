@@ -0,0 +1,105 @@
+package beeorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type localCacheInvalidationEntity struct {
+	ID   uint64 `orm:"localCache"`
+	Name string
+	Ref  Reference[localCacheInvalidationReference] `orm:"index=Ref;cached"`
+}
+
+type localCacheInvalidationReference struct {
+	ID   uint64
+	Name string
+}
+
+func TestLocalCacheInvalidation(t *testing.T) {
+	var entity *localCacheInvalidationEntity
+	var reference *localCacheInvalidationReference
+	PrepareTables(t, NewRegistry(), entity, reference)
+
+	newEngine := func() Engine {
+		registry := NewRegistry()
+		registry.RegisterMySQL("root:root@tcp(localhost:3377)/test", DefaultPoolCode, &MySQLOptions{})
+		registry.RegisterRedis("localhost:6385", 0, DefaultPoolCode, &RedisOptions{LocalCacheInvalidation: true})
+		registry.RegisterLocalCache(DefaultPoolCode, 0)
+		registry.RegisterEntity(entity, reference)
+		engine, err := registry.Validate()
+		assert.NoError(t, err)
+		assert.True(t, engine.Redis(DefaultPoolCode).GetConfig().HasLocalCacheInvalidation())
+		return engine
+	}
+	engine1 := newEngine()
+	engine2 := newEngine()
+	defer engine1.Close()
+	defer engine2.Close()
+	orm1 := engine1.NewORM(context.Background())
+	orm2 := engine2.NewORM(context.Background())
+
+	ref := NewEntity[localCacheInvalidationReference](orm1)
+	ref.Name = "Ref"
+	entity = NewEntity[localCacheInvalidationEntity](orm1)
+	entity.Name = "Before"
+	entity.Ref = Reference[localCacheInvalidationReference](ref.ID)
+	assert.NoError(t, orm1.Flush())
+
+	loaded, found := GetByID[localCacheInvalidationEntity](orm2, entity.ID)
+	assert.True(t, found)
+	assert.Equal(t, "Before", loaded.Name)
+	assert.Equal(t, 1, GetByReference[localCacheInvalidationEntity](orm2, "Ref", ref.ID).Len())
+
+	entity = EditEntity(orm1, entity)
+	entity.Name = "After"
+	assert.NoError(t, orm1.Flush())
+	assert.Eventually(t, func() bool {
+		loaded, found = GetByID[localCacheInvalidationEntity](orm2, entity.ID)
+		return found && loaded.Name == "After"
+	}, time.Second, time.Millisecond*10)
+
+	firstID := entity.ID
+	entity = NewEntity[localCacheInvalidationEntity](orm1)
+	entity.Name = "Second"
+	entity.Ref = Reference[localCacheInvalidationReference](ref.ID)
+	assert.NoError(t, orm1.Flush())
+	assert.Eventually(t, func() bool {
+		return GetByReference[localCacheInvalidationEntity](orm2, "Ref", ref.ID).Len() == 2
+	}, time.Second, time.Millisecond*10)
+
+	DeleteEntity(orm1, entity)
+	assert.NoError(t, orm1.Flush())
+	assert.Eventually(t, func() bool {
+		_, found = GetByID[localCacheInvalidationEntity](orm2, entity.ID)
+		return !found
+	}, time.Second, time.Millisecond*10)
+
+	loaded, _ = GetByID[localCacheInvalidationEntity](orm1, firstID)
+	entity = EditEntity(orm1, loaded)
+	entity.Name = "Async"
+	assert.NoError(t, orm1.FlushAsync())
+	time.Sleep(time.Millisecond * 100)
+	loaded, found = GetByID[localCacheInvalidationEntity](orm2, firstID)
+	assert.True(t, found)
+	assert.Equal(t, "After", loaded.Name)
+	assert.NoError(t, ConsumeAsyncFlushEvents(orm1, false))
+	assert.Eventually(t, func() bool {
+		loaded, found = GetByID[localCacheInvalidationEntity](orm2, firstID)
+		return found && loaded.Name == "Async"
+	}, time.Second, time.Millisecond*10)
+
+	engine2.Close()
+	engine2.Close()
+	loaded, _ = GetByID[localCacheInvalidationEntity](orm1, firstID)
+	entity = EditEntity(orm1, loaded)
+	entity.Name = "Closed"
+	assert.NoError(t, orm1.Flush())
+	time.Sleep(time.Millisecond * 100)
+	loaded, found = GetByID[localCacheInvalidationEntity](orm2, firstID)
+	assert.True(t, found)
+	assert.Equal(t, "Async", loaded.Name)
+}
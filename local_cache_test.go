@@ -2,8 +2,11 @@ package beeorm
 
 import (
 	"context"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -57,3 +60,49 @@ func TestLocalCache(t *testing.T) {
 	assert.True(t, has)
 	assert.Equal(t, "hello", val)
 }
+
+type localCacheTTLEntity struct {
+	ID   uint64 `orm:"localCache;localCacheTTL=50ms;redisCache;redisCacheTTL=1h"`
+	Name string
+}
+
+func TestLocalCacheTTL(t *testing.T) {
+	var entity *localCacheTTLEntity
+	orm := PrepareTables(t, NewRegistry(), entity)
+	schema := GetEntitySchema[localCacheTTLEntity](orm)
+	lc, _ := schema.GetLocalCache()
+	rc, _ := schema.GetRedisCache()
+	assert.Equal(t, time.Millisecond*50, lc.GetConfig().GetTTL())
+
+	entity = NewEntity[localCacheTTLEntity](orm)
+	entity.Name = "Test"
+	assert.NoError(t, orm.Flush())
+	redisKey := schema.(*entitySchema).getCacheKey() + ":" + strconv.FormatUint(entity.ID, 10)
+	ttl := redis.NewIntCmd(orm.Context(), "PTTL", redisKey)
+	assert.NoError(t, rc.Process(orm, ttl))
+	assert.Greater(t, ttl.Val(), int64(0))
+
+	loggerDB := &MockLogHandler{}
+	orm.RegisterQueryLogger(loggerDB, true, false, false)
+	entity, found := GetByID[localCacheTTLEntity](orm, entity.ID)
+	assert.True(t, found)
+	assert.Len(t, loggerDB.Logs, 0)
+	assert.Equal(t, uint64(0), lc.GetUsage()[0].Expirations)
+
+	time.Sleep(time.Millisecond * 60)
+	rc.Del(orm, redisKey)
+	entity, found = GetByID[localCacheTTLEntity](orm, entity.ID)
+	assert.True(t, found)
+	assert.Equal(t, "Test", entity.Name)
+	assert.Len(t, loggerDB.Logs, 1)
+	assert.Equal(t, uint64(1), lc.GetUsage()[0].Expirations)
+	assert.Equal(t, uint64(0), lc.GetUsage()[0].Evictions)
+	ttl = redis.NewIntCmd(orm.Context(), "PTTL", redisKey)
+	assert.NoError(t, rc.Process(orm, ttl))
+	assert.Greater(t, ttl.Val(), int64(0))
+
+	entity = EditEntity(orm, entity)
+	entity.Name = "Changed"
+	assert.NoError(t, orm.Flush())
+	assert.Equal(t, int64(0), rc.Exists(orm, redisKey))
+}
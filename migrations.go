@@ -0,0 +1,285 @@
+package beeorm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const migrationsTableName = "_beeorm_migrations"
+const migrationsLockTTL = time.Minute * 10
+const migrationsLockWait = time.Second * 30
+const migrationUpSuffix = ".up.sql"
+const migrationDownSuffix = ".down.sql"
+const migrationVersionFormat = "20060102150405"
+const migrationStatementSeparator = "\n-- beeorm:statement\n"
+const migrationIrreversiblePrefix = "-- beeorm:irreversible "
+
+var migrationNameRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+var migrationFileRegexp = regexp.MustCompile(`^(\d{14})_([a-z0-9_]+)\.up\.sql$`)
+
+type Migration struct {
+	Version      string
+	Name         string
+	Pool         string
+	Up           []string
+	Down         []string
+	Irreversible bool
+	AppliedAt    *time.Time
+}
+
+func (m *Migration) ID() string {
+	return m.Version + "_" + m.Name
+}
+
+func GenerateMigration(orm ORM, dir, name string) []*Migration {
+	migrations := newMigrations(time.Now().UTC().Format(migrationVersionFormat), name, GetAlters(orm))
+	for _, migration := range migrations {
+		writeMigration(dir, migration)
+	}
+	return migrations
+}
+
+func LoadMigrations(dir string) []*Migration {
+	pools, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	checkError(err)
+	migrations := make([]*Migration, 0)
+	for _, pool := range pools {
+		if !pool.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(dir, pool.Name()))
+		checkError(err)
+		for _, file := range files {
+			parts := migrationFileRegexp.FindStringSubmatch(file.Name())
+			if parts == nil {
+				continue
+			}
+			migration := &Migration{Version: parts[1], Name: parts[2], Pool: pool.Name()}
+			upSQL, err := os.ReadFile(filepath.Join(dir, pool.Name(), file.Name()))
+			checkError(err)
+			migration.Up, _ = parseMigrationStatements(string(upSQL))
+			downSQL, err := os.ReadFile(filepath.Join(dir, pool.Name(), migration.ID()+migrationDownSuffix))
+			if os.IsNotExist(err) {
+				migration.Irreversible = true
+			} else {
+				checkError(err)
+				migration.Down, migration.Irreversible = parseMigrationStatements(string(downSQL))
+			}
+			migrations = append(migrations, migration)
+		}
+	}
+	sortMigrations(migrations)
+	return migrations
+}
+
+func GetMigrations(orm ORM, dir string) []*Migration {
+	migrations := LoadMigrations(dir)
+	applied := make(map[string]map[string]*time.Time)
+	for _, migration := range migrations {
+		appliedInPool, has := applied[migration.Pool]
+		if !has {
+			appliedInPool = getAppliedMigrations(orm, getMigrationsDB(orm, migration.Pool))
+			applied[migration.Pool] = appliedInPool
+		}
+		migration.AppliedAt = appliedInPool[migration.Version]
+	}
+	return migrations
+}
+
+func GetPendingMigrations(orm ORM, dir string) []*Migration {
+	pending := make([]*Migration, 0)
+	for _, migration := range GetMigrations(orm, dir) {
+		if migration.AppliedAt == nil {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
+
+func ApplyMigrations(orm ORM, dir string) []*Migration {
+	migrationsInPools := make(map[string][]*Migration)
+	var pools []string
+	for _, migration := range LoadMigrations(dir) {
+		if _, has := migrationsInPools[migration.Pool]; !has {
+			pools = append(pools, migration.Pool)
+		}
+		migrationsInPools[migration.Pool] = append(migrationsInPools[migration.Pool], migration)
+	}
+	applied := make([]*Migration, 0)
+	for _, pool := range pools {
+		db := getMigrationsDB(orm, pool)
+		lock := obtainMigrationsLock(orm, pool)
+		func() {
+			defer lock.Release(orm)
+			appliedInPool := getAppliedMigrations(orm, db)
+			for _, migration := range migrationsInPools[pool] {
+				if appliedInPool[migration.Version] != nil {
+					continue
+				}
+				for _, statement := range migration.Up {
+					db.Exec(orm, statement)
+					lock.Refresh(orm, migrationsLockTTL)
+				}
+				now := time.Now().UTC()
+				db.Exec(orm, "INSERT INTO `"+migrationsTableName+"`(`Version`,`Name`,`AppliedAt`) VALUES(?,?,?)",
+					migration.Version, migration.Name, now.Unix())
+				migration.AppliedAt = &now
+				applied = append(applied, migration)
+			}
+		}()
+	}
+	return applied
+}
+
+func RollbackMigrations(orm ORM, dir, pool string, steps int) []*Migration {
+	migrations := make(map[string]*Migration)
+	for _, migration := range LoadMigrations(dir) {
+		if migration.Pool == pool {
+			migrations[migration.Version] = migration
+		}
+	}
+	db := getMigrationsDB(orm, pool)
+	lock := obtainMigrationsLock(orm, pool)
+	defer lock.Release(orm)
+	appliedInPool := getAppliedMigrations(orm, db)
+	versions := make([]string, 0, len(appliedInPool))
+	for version := range appliedInPool {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	if steps < 0 {
+		steps = 0
+	}
+	if steps < len(versions) {
+		versions = versions[0:steps]
+	}
+	rolledBack := make([]*Migration, 0, len(versions))
+	for _, version := range versions {
+		migration, has := migrations[version]
+		if !has {
+			panic(fmt.Errorf("missing migration file for version %s in pool %s", version, pool))
+		}
+		if migration.Irreversible {
+			panic(fmt.Errorf("migration %s in pool %s is irreversible", migration.ID(), pool))
+		}
+		for _, statement := range migration.Down {
+			db.Exec(orm, statement)
+			lock.Refresh(orm, migrationsLockTTL)
+		}
+		db.Exec(orm, "DELETE FROM `"+migrationsTableName+"` WHERE `Version` = ?", migration.Version)
+		migration.AppliedAt = nil
+		rolledBack = append(rolledBack, migration)
+	}
+	return rolledBack
+}
+
+func newMigrations(version, name string, alters []Alter) []*Migration {
+	name = strings.Trim(migrationNameRegexp.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		name = "migration"
+	}
+	migrationsInPools := make(map[string]*Migration)
+	migrations := make([]*Migration, 0)
+	for _, alter := range alters {
+		migration, has := migrationsInPools[alter.Pool]
+		if !has {
+			migration = &Migration{Version: version, Name: name, Pool: alter.Pool}
+			migrationsInPools[alter.Pool] = migration
+			migrations = append(migrations, migration)
+		}
+		migration.Up = append(migration.Up, alter.SQL)
+		if alter.Down == "" {
+			migration.Irreversible = true
+			migration.Down = append([]string{migrationIrreversiblePrefix + strings.Split(alter.SQL, "\n")[0]}, migration.Down...)
+			continue
+		}
+		migration.Down = append([]string{alter.Down}, migration.Down...)
+	}
+	sortMigrations(migrations)
+	return migrations
+}
+
+func writeMigration(dir string, migration *Migration) {
+	poolDir := filepath.Join(dir, migration.Pool)
+	checkError(os.MkdirAll(poolDir, 0755))
+	header := fmt.Sprintf("-- beeorm migration %s pool %s\n", migration.ID(), migration.Pool)
+	upSQL := header + strings.Join(migration.Up, migrationStatementSeparator) + "\n"
+	checkError(os.WriteFile(filepath.Join(poolDir, migration.ID()+migrationUpSuffix), []byte(upSQL), 0644))
+	downSQL := header + strings.Join(migration.Down, migrationStatementSeparator) + "\n"
+	checkError(os.WriteFile(filepath.Join(poolDir, migration.ID()+migrationDownSuffix), []byte(downSQL), 0644))
+}
+
+func parseMigrationStatements(content string) (statements []string, irreversible bool) {
+	for _, chunk := range strings.Split(content, migrationStatementSeparator) {
+		lines := make([]string, 0)
+		for _, line := range strings.Split(chunk, "\n") {
+			if strings.HasPrefix(line, migrationIrreversiblePrefix) {
+				irreversible = true
+			}
+			if strings.HasPrefix(strings.TrimSpace(line), "--") {
+				continue
+			}
+			lines = append(lines, line)
+		}
+		statement := strings.TrimSpace(strings.Join(lines, "\n"))
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements, irreversible
+}
+
+func sortMigrations(migrations []*Migration) {
+	sort.SliceStable(migrations, func(i, j int) bool {
+		if migrations[i].Version != migrations[j].Version {
+			return migrations[i].Version < migrations[j].Version
+		}
+		return migrations[i].Pool < migrations[j].Pool
+	})
+}
+
+func getMigrationsDB(orm ORM, pool string) DB {
+	db := orm.Engine().DB(pool)
+	if db == nil {
+		panic(fmt.Errorf("DB pool '%s' is not registered", pool))
+	}
+	return db
+}
+
+func obtainMigrationsLock(orm ORM, pool string) *Lock {
+	r := orm.Engine().Redis(DefaultPoolCode)
+	if r == nil {
+		panic(errors.New("default redis pool is required to run migrations"))
+	}
+	lock, has := r.GetLocker().Obtain(orm, migrationsTableName+":"+pool, migrationsLockTTL, migrationsLockWait)
+	if !has {
+		panic(fmt.Errorf("migrations in pool %s are locked by another process", pool))
+	}
+	return lock
+}
+
+func getAppliedMigrations(orm ORM, db DB) map[string]*time.Time {
+	db.Exec(orm, "CREATE TABLE IF NOT EXISTS `"+migrationsTableName+"` (`Version` varchar(14) NOT NULL, "+
+		"`Name` varchar(255) NOT NULL, `AppliedAt` bigint NOT NULL, PRIMARY KEY (`Version`))")
+	applied := make(map[string]*time.Time)
+	results, def := db.Primary().Query(orm, "SELECT `Version`, `AppliedAt` FROM `"+migrationsTableName+"`")
+	defer def()
+	for results.Next() {
+		var version string
+		var appliedAt int64
+		results.Scan(&version, &appliedAt)
+		t := time.Unix(appliedAt, 0).UTC()
+		applied[version] = &t
+	}
+	return applied
+}
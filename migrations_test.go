@@ -0,0 +1,100 @@
+package beeorm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type migrationEntity struct {
+	ID   uint64 `orm:"localCache;redisCache"`
+	Name string `orm:"required"`
+}
+
+func TestMigrationFiles(t *testing.T) {
+	dir := t.TempDir()
+	alters := []Alter{
+		{SQL: "CREATE TABLE `a` (`ID` int);", Down: "DROP TABLE IF EXISTS `a`;", Pool: DefaultPoolCode},
+		{SQL: "ALTER TABLE `b`\n    ADD COLUMN `Age` int;", Down: "ALTER TABLE `b`\n    DROP COLUMN `Age`;", Pool: DefaultPoolCode},
+		{SQL: "CREATE TABLE `c` (`ID` int);", Down: "DROP TABLE IF EXISTS `c`;", Pool: "second"},
+	}
+	migrations := newMigrations("20240101120000", "Add Users table!", alters)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, "20240101120000_add_users_table", migrations[0].ID())
+	assert.Equal(t, DefaultPoolCode, migrations[0].Pool)
+	assert.Equal(t, []string{alters[0].SQL, alters[1].SQL}, migrations[0].Up)
+	assert.Equal(t, []string{alters[1].Down, alters[0].Down}, migrations[0].Down)
+	assert.False(t, migrations[0].Irreversible)
+	assert.Equal(t, "second", migrations[1].Pool)
+	for _, migration := range migrations {
+		writeMigration(dir, migration)
+	}
+	assert.FileExists(t, filepath.Join(dir, DefaultPoolCode, "20240101120000_add_users_table.up.sql"))
+	assert.FileExists(t, filepath.Join(dir, DefaultPoolCode, "20240101120000_add_users_table.down.sql"))
+
+	irreversible := newMigrations("20240102120000", "drop", []Alter{{SQL: "DROP TABLE IF EXISTS `d`;", Pool: DefaultPoolCode}})
+	assert.True(t, irreversible[0].Irreversible)
+	writeMigration(dir, irreversible[0])
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("test"), 0644))
+
+	loaded := LoadMigrations(dir)
+	assert.Len(t, loaded, 3)
+	assert.Equal(t, migrations[0].ID(), loaded[0].ID())
+	assert.Equal(t, migrations[0].Up, loaded[0].Up)
+	assert.Equal(t, migrations[0].Down, loaded[0].Down)
+	assert.False(t, loaded[0].Irreversible)
+	assert.Equal(t, "second", loaded[1].Pool)
+	assert.Equal(t, "20240102120000_drop", loaded[2].ID())
+	assert.Equal(t, []string{"DROP TABLE IF EXISTS `d`;"}, loaded[2].Up)
+	assert.Nil(t, loaded[2].Down)
+	assert.True(t, loaded[2].Irreversible)
+	assert.Nil(t, LoadMigrations(filepath.Join(dir, "missing")))
+}
+
+func TestMigrations(t *testing.T) {
+	registry := NewRegistry()
+	orm := PrepareTables(t, registry, migrationEntity{})
+	db := orm.Engine().DB(DefaultPoolCode)
+	db.Exec(orm, "DROP TABLE IF EXISTS `"+migrationsTableName+"`")
+	db.Exec(orm, "DROP TABLE `migrationEntity`")
+
+	dir := t.TempDir()
+	migrations := GenerateMigration(orm, dir, "init")
+	assert.Len(t, migrations, 1)
+	assert.Len(t, migrations[0].Up, 1)
+	assert.Contains(t, migrations[0].Up[0], "CREATE TABLE")
+	assert.Equal(t, []string{"DROP TABLE IF EXISTS `test`.`migrationEntity`;"}, migrations[0].Down)
+	pending := GetPendingMigrations(orm, dir)
+	assert.Len(t, pending, 1)
+	assert.Nil(t, pending[0].AppliedAt)
+
+	applied := ApplyMigrations(orm, dir)
+	assert.Len(t, applied, 1)
+	assert.NotNil(t, applied[0].AppliedAt)
+	assert.Len(t, GetAlters(orm), 0)
+	assert.Len(t, GetPendingMigrations(orm, dir), 0)
+	assert.Len(t, ApplyMigrations(orm, dir), 0)
+	all := GetMigrations(orm, dir)
+	assert.Len(t, all, 1)
+	assert.NotNil(t, all[0].AppliedAt)
+
+	db.Exec(orm, "ALTER TABLE `migrationEntity` ADD COLUMN `Age` int, ADD INDEX `Age` (`Age`)")
+	alters := GetAlters(orm)
+	assert.Len(t, alters, 1)
+	assert.Equal(t, "ALTER TABLE `test`.`migrationEntity`\n    ADD COLUMN `Age` int DEFAULT NULL AFTER `Name`,\n    "+
+		"ADD INDEX `Age` (`Age`);", alters[0].Down)
+
+	rolledBack := RollbackMigrations(orm, dir, DefaultPoolCode, 1)
+	assert.Len(t, rolledBack, 1)
+	assert.Nil(t, rolledBack[0].AppliedAt)
+	assert.Len(t, GetPendingMigrations(orm, dir), 1)
+	assert.Len(t, RollbackMigrations(orm, dir, DefaultPoolCode, 1), 0)
+	ApplyMigrations(orm, dir)
+	assert.Len(t, GetPendingMigrations(orm, dir), 0)
+
+	assert.PanicsWithError(t, "DB pool 'missing' is not registered", func() {
+		RollbackMigrations(orm, dir, "missing", 1)
+	})
+}
@@ -0,0 +1,181 @@
+package beeorm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/puzpuzpuz/xsync/v2"
+)
+
+const (
+	onDeleteCascade  = "cascade"
+	onDeleteSetNull  = "setNull"
+	onDeleteRestrict = "restrict"
+)
+
+type onDeleteChild struct {
+	schema *entitySchema
+	column string
+	action string
+}
+
+type DeleteRestrictedError struct {
+	EntitySchema     EntitySchema
+	ID               uint64
+	ReferencedSchema EntitySchema
+	ReferencedID     uint64
+	Field            string
+}
+
+func (e *DeleteRestrictedError) Error() string {
+	return fmt.Sprintf("entity '%s' with ID %d can't be deleted because it is referenced by '%s' with ID %d in field '%s'",
+		e.EntitySchema.GetType().String(), e.ID, e.ReferencedSchema.GetType().String(), e.ReferencedID, e.Field)
+}
+
+func (e *entitySchema) initOnDeleteReferences(registry *engineRegistryImplementation) error {
+	columns := make([]string, 0)
+	for column := range e.references {
+		if _, has := e.tags[column]["onDelete"]; has {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		action := e.tags[column]["onDelete"]
+		switch action {
+		case onDeleteCascade, onDeleteRestrict:
+		case onDeleteSetNull:
+			if e.tags[column]["required"] == "true" {
+				return fmt.Errorf("onDelete setNull in field '%s' can't be used with required reference", column)
+			}
+		default:
+			return fmt.Errorf("invalid onDelete value '%s' in field '%s'", action, column)
+		}
+		if _, hasForeignKey := e.tags[column]["fk"]; hasForeignKey {
+			return fmt.Errorf("field '%s' can't use both fk and onDelete", column)
+		}
+		parent := registry.entitySchemas[e.references[column].Type]
+		if parent == nil {
+			return fmt.Errorf("entity '%s' referenced in field '%s' is not registered", e.references[column].Type.String(), column)
+		}
+		parent.onDeleteChildren = append(parent.onDeleteChildren, onDeleteChild{schema: e, column: column, action: action})
+	}
+	return nil
+}
+
+func (orm *ormImplementation) applyOnDeleteReferences() error {
+	deleted := make(map[*entitySchema][]uint64)
+	orm.trackedEntities.Range(func(_ uint64, value *xsync.MapOf[uint64, EntityFlush]) bool {
+		value.Range(func(_ uint64, flush EntityFlush) bool {
+			schema := flush.Schema()
+			if len(schema.onDeleteChildren) > 0 && isHardDelete(flush) {
+				deleted[schema] = append(deleted[schema], flush.ID())
+			}
+			return true
+		})
+		return true
+	})
+	staged := make(map[*entitySchema]map[uint64]EntityFlush)
+	var edits []func()
+	stage := func(flush EntityFlush) {
+		if staged[flush.Schema()] == nil {
+			staged[flush.Schema()] = make(map[uint64]EntityFlush)
+		}
+		staged[flush.Schema()][flush.ID()] = flush
+	}
+	for len(deleted) > 0 {
+		next := make(map[*entitySchema][]uint64)
+		for schema, ids := range deleted {
+			for _, child := range schema.onDeleteChildren {
+				for _, value := range searchOnDeleteChildren(orm, child, ids) {
+					childID := value.Elem().Field(0).Uint()
+					tracked := staged[child.schema][childID]
+					if tracked == nil {
+						tracked = orm.getTrackedEntity(child.schema, childID)
+					}
+					if tracked != nil && tracked.flushType() == Delete {
+						continue
+					}
+					entity := value.Interface()
+					elem := value.Elem()
+					switch child.action {
+					case onDeleteRestrict:
+						parentID := reflect.ValueOf(child.schema.fieldGetters[child.column](elem)).Uint()
+						return &DeleteRestrictedError{EntitySchema: schema, ID: parentID, ReferencedSchema: child.schema,
+							ReferencedID: childID, Field: child.column}
+					case onDeleteCascade:
+						toRemove := &removableEntity{id: childID, value: elem, source: entity}
+						toRemove.orm = orm
+						toRemove.schema = child.schema
+						stage(toRemove)
+						if len(child.schema.onDeleteChildren) > 0 && child.schema.softDeleteColumn == "" {
+							next[child.schema] = append(next[child.schema], childID)
+						}
+					case onDeleteSetNull:
+						fSetter := child.schema.fieldSetters[child.column]
+						column := child.column
+						switch editable := tracked.(type) {
+						case *editableEntity:
+							edits = append(edits, func() {
+								fSetter(nil, editable.value.Elem())
+							})
+						case *editableFields:
+							oldValue := reflect.ValueOf(child.schema.fieldGetters[column](elem)).Uint()
+							edits = append(edits, func() {
+								if _, has := editable.oldBind[column]; !has {
+									editable.oldBind[column] = oldValue
+								}
+								editable.newBind[column] = nil
+							})
+						default:
+							writable := copyToEdit(orm, entity)
+							writable.id = childID
+							writable.source = entity
+							fSetter(nil, writable.value.Elem())
+							stage(writable)
+						}
+					}
+				}
+			}
+		}
+		deleted = next
+	}
+	for _, edit := range edits {
+		edit()
+	}
+	for _, flushes := range staged {
+		for _, flush := range flushes {
+			orm.storeTrackedEntity(flush)
+		}
+	}
+	return nil
+}
+
+func searchOnDeleteChildren(orm ORM, child onDeleteChild, ids []uint64) []reflect.Value {
+	var children []reflect.Value
+	for _, shard := range child.schema.getShards() {
+		where := shard.applySoftDelete(NewWhere("`"+child.column+"` IN ?", ids))
+		/* #nosec */
+		query := "SELECT " + shard.fieldsQuery + " FROM `" + shard.GetTableName() + "` WHERE " + where.String()
+		func() {
+			results, def := shard.GetDB().Primary().Query(orm, query, where.GetParameters()...)
+			defer def()
+			for results.Next() {
+				pointers := prepareScan(shard)
+				results.Scan(pointers...)
+				value := reflect.New(shard.t)
+				deserializeFromDB(shard.fields, value.Elem(), pointers)
+				children = append(children, value)
+			}
+		}()
+	}
+	return children
+}
+
+func isHardDelete(flush EntityFlush) bool {
+	if flush.flushType() != Delete {
+		return false
+	}
+	return flush.Schema().softDeleteColumn == "" || flush.(entityFlushDelete).isForced()
+}
@@ -0,0 +1,127 @@
+package beeorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type onDeleteParent struct {
+	ID   uint64 `orm:"localCache;redisCache"`
+	Name string `orm:"required"`
+}
+
+type onDeleteCascadeChild struct {
+	ID     uint64                    `orm:"localCache;redisCache"`
+	Parent Reference[onDeleteParent] `orm:"onDelete=cascade;required;index=Parent;cached"`
+}
+
+type onDeleteCascadeGrandChild struct {
+	ID    uint64                          `orm:"redisCache"`
+	Child Reference[onDeleteCascadeChild] `orm:"onDelete=cascade;index=Child"`
+}
+
+type onDeleteSetNullChild struct {
+	ID     uint64                    `orm:"localCache;redisCache"`
+	Parent Reference[onDeleteParent] `orm:"onDelete=setNull;index=Parent;cached"`
+}
+
+type onDeleteRestrictParent struct {
+	ID uint64
+}
+
+type onDeleteRestrictChild struct {
+	ID     uint64
+	Parent Reference[onDeleteRestrictParent] `orm:"onDelete=restrict"`
+}
+
+type onDeleteInvalid struct {
+	ID     uint64
+	Parent Reference[onDeleteParent] `orm:"onDelete=setNull;required"`
+}
+
+func TestOnDelete(t *testing.T) {
+	registry := NewRegistry()
+	orm := PrepareTables(t, registry, onDeleteParent{}, onDeleteCascadeChild{}, onDeleteCascadeGrandChild{},
+		onDeleteSetNullChild{}, onDeleteRestrictParent{}, onDeleteRestrictChild{})
+
+	parent := NewEntity[onDeleteParent](orm)
+	parent.Name = "parent"
+	cascadeChild := NewEntity[onDeleteCascadeChild](orm)
+	cascadeChild.Parent = Reference[onDeleteParent](parent.ID)
+	grandChild := NewEntity[onDeleteCascadeGrandChild](orm)
+	grandChild.Child = Reference[onDeleteCascadeChild](cascadeChild.ID)
+	setNullChild := NewEntity[onDeleteSetNullChild](orm)
+	setNullChild.Parent = Reference[onDeleteParent](parent.ID)
+	assert.NoError(t, orm.Flush())
+	assert.Equal(t, 1, GetByReference[onDeleteCascadeChild](orm, "Parent", parent.ID).Len())
+	assert.Equal(t, 1, GetByReference[onDeleteSetNullChild](orm, "Parent", parent.ID).Len())
+
+	DeleteEntity(orm, parent)
+	assert.NoError(t, orm.Flush())
+	_, found := GetByID[onDeleteCascadeChild](orm, cascadeChild.ID)
+	assert.False(t, found)
+	_, found = GetByID[onDeleteCascadeGrandChild](orm, grandChild.ID)
+	assert.False(t, found)
+	loaded, found := GetByID[onDeleteSetNullChild](orm, setNullChild.ID)
+	assert.True(t, found)
+	assert.Equal(t, uint64(0), uint64(loaded.Parent))
+	assert.Equal(t, 0, GetByReference[onDeleteCascadeChild](orm, "Parent", parent.ID).Len())
+	assert.Equal(t, 0, GetByReference[onDeleteSetNullChild](orm, "Parent", parent.ID).Len())
+
+	restrictParent := NewEntity[onDeleteRestrictParent](orm)
+	restrictChild := NewEntity[onDeleteRestrictChild](orm)
+	restrictChild.Parent = Reference[onDeleteRestrictParent](restrictParent.ID)
+	assert.NoError(t, orm.Flush())
+	DeleteEntity(orm, restrictParent)
+	err := orm.Flush()
+	assert.IsType(t, &DeleteRestrictedError{}, err)
+	assert.Equal(t, restrictParent.ID, err.(*DeleteRestrictedError).ID)
+	assert.Equal(t, restrictChild.ID, err.(*DeleteRestrictedError).ReferencedID)
+	orm.ClearFlush()
+	_, found = GetByID[onDeleteRestrictParent](orm, restrictParent.ID)
+	assert.True(t, found)
+
+	parent = NewEntity[onDeleteParent](orm)
+	parent.Name = "parent 2"
+	setNullChild = NewEntity[onDeleteSetNullChild](orm)
+	setNullChild.Parent = Reference[onDeleteParent](parent.ID)
+	assert.NoError(t, orm.Flush())
+	DeleteEntity(orm, parent)
+	DeleteEntity(orm, restrictParent)
+	assert.IsType(t, &DeleteRestrictedError{}, orm.Flush())
+	assert.Nil(t, orm.(*ormImplementation).getTrackedEntity(GetEntitySchema[onDeleteSetNullChild](orm).(*entitySchema), setNullChild.ID))
+	orm.ClearFlush()
+	loaded, found = GetByID[onDeleteSetNullChild](orm, setNullChild.ID)
+	assert.True(t, found)
+	assert.Equal(t, parent.ID, uint64(loaded.Parent))
+
+	DeleteEntity(orm, restrictChild)
+	DeleteEntity(orm, restrictParent)
+	assert.NoError(t, orm.Flush())
+	_, found = GetByID[onDeleteRestrictParent](orm, restrictParent.ID)
+	assert.False(t, found)
+
+	parent = NewEntity[onDeleteParent](orm)
+	parent.Name = "parent 3"
+	assert.NoError(t, orm.Flush())
+	missingChildID := cascadeChild.ID + 1000
+	_, found = GetByID[onDeleteCascadeChild](orm, missingChildID)
+	assert.False(t, found)
+	db := orm.Engine().DB(DefaultPoolCode)
+	db.Exec(orm, "INSERT INTO `onDeleteCascadeChild` (`ID`, `Parent`) VALUES (?, ?)", missingChildID, parent.ID)
+	DeleteEntity(orm, parent)
+	assert.NoError(t, orm.Flush())
+	total := -1
+	db.QueryRow(orm, NewWhere("SELECT COUNT(1) FROM `onDeleteCascadeChild` WHERE `ID` = ?", missingChildID), &total)
+	assert.Equal(t, 0, total)
+
+	assert.EqualError(t, DeleteWhere[onDeleteParent](orm, NewWhere("`Name` = ?", "parent")),
+		"entity 'beeorm.onDeleteParent' referenced with onDelete can't be deleted with DeleteWhere")
+
+	registry = NewRegistry()
+	registry.RegisterMySQL("root:root@tcp(localhost:3377)/test", DefaultPoolCode, &MySQLOptions{})
+	registry.RegisterEntity(onDeleteParent{}, onDeleteInvalid{})
+	_, err = registry.Validate()
+	assert.EqualError(t, err, "invalid entity struct 'beeorm.onDeleteInvalid': onDelete setNull in field 'Parent' can't be used with required reference")
+}
@@ -0,0 +1,189 @@
+package beeorm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const onlineAlterDefaultChunkSize = 1000
+const onlineAlterDefaultThrottleInterval = time.Second
+
+type OnlineAlterOptions struct {
+	ChunkSize        int
+	MaxReplicaLag    time.Duration
+	ThrottleInterval time.Duration
+	KeepOldTable     bool
+	Progress         func(progress OnlineAlterProgress)
+}
+
+type OnlineAlterProgress struct {
+	Table      string
+	Copied     uint64
+	Estimated  uint64
+	LastID     uint64
+	MaxID      uint64
+	ReplicaLag time.Duration
+	Throttled  bool
+}
+
+type onlineAlter struct {
+	database       string
+	table          string
+	createTableSQL string
+	columns        []string
+}
+
+func newOnlineAlter(sqlSchema *TableSQLSchemaDefinition) *onlineAlter {
+	pool := sqlSchema.EntitySchema.GetDB()
+	alter := &onlineAlter{database: pool.GetConfig().GetDatabaseName(), table: sqlSchema.EntitySchema.GetTableName()}
+	alter.createTableSQL = strings.Replace(sqlSchema.CreateTableSQL(), alter.identifier(alter.table),
+		alter.identifier(alter.shadowTable()), 1)
+	for _, column := range sqlSchema.EntityColumns {
+		for _, dbColumn := range sqlSchema.DBTableColumns {
+			if dbColumn.ColumnName == column.ColumnName {
+				alter.columns = append(alter.columns, column.ColumnName)
+				break
+			}
+		}
+	}
+	return alter
+}
+
+func getMySQLEstimatedRows(orm ORM, pool DB, tableName string) uint64 {
+	var rows uint64
+	pool.QueryRow(orm, NewWhere("SELECT IFNULL(TABLE_ROWS, 0) FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+		pool.GetConfig().GetDatabaseName(), tableName), &rows)
+	return rows
+}
+
+func (a Alter) CanExecOnline() bool {
+	return a.online != nil
+}
+
+func (a Alter) ExecOnline(orm ORM, options *OnlineAlterOptions) {
+	if a.online == nil {
+		a.Exec(orm)
+		return
+	}
+	if options == nil {
+		options = &OnlineAlterOptions{}
+	}
+	chunkSize := options.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = onlineAlterDefaultChunkSize
+	}
+	throttleInterval := options.ThrottleInterval
+	if throttleInterval <= 0 {
+		throttleInterval = onlineAlterDefaultThrottleInterval
+	}
+	o := a.online
+	pool := orm.Engine().DB(a.Pool)
+	db := pool.Primary()
+	o.dropTriggers(orm, db)
+	db.Exec(orm, "DROP TABLE IF EXISTS "+o.identifier(o.shadowTable()))
+	db.Exec(orm, o.createTableSQL)
+	completed := false
+	defer func() {
+		if !completed {
+			o.dropTriggers(orm, db)
+			db.Exec(orm, "DROP TABLE IF EXISTS "+o.identifier(o.shadowTable()))
+		}
+	}()
+	o.createTriggers(orm, db)
+
+	var minID, maxID uint64
+	db.QueryRow(orm, NewWhere(fmt.Sprintf("SELECT IFNULL(MIN(`ID`), 0), IFNULL(MAX(`ID`), 0) FROM %s",
+		o.identifier(o.table))), &minID, &maxID)
+	progress := OnlineAlterProgress{Table: o.table, Estimated: a.EstimatedRows, MaxID: maxID}
+	columns := "`" + strings.Join(o.columns, "`,`") + "`"
+	copySQL := fmt.Sprintf("INSERT IGNORE INTO %s (%s) SELECT %s FROM %s WHERE `ID` BETWEEN ? AND ? LOCK IN SHARE MODE",
+		o.identifier(o.shadowTable()), columns, columns, o.identifier(o.table))
+	for from := minID; maxID > 0 && from <= maxID; from += uint64(chunkSize) {
+		checkError(orm.Context().Err())
+		to := from + uint64(chunkSize) - 1
+		progress.Copied += o.copyChunk(orm, db, copySQL, from, to)
+		progress.LastID = min(to, maxID)
+		progress.Throttled = false
+		for options.MaxReplicaLag > 0 {
+			lag, err := pool.(*dbImplementation).replicaLag()
+			checkError(err)
+			progress.ReplicaLag = lag
+			if progress.ReplicaLag <= options.MaxReplicaLag {
+				break
+			}
+			progress.Throttled = true
+			if options.Progress != nil {
+				options.Progress(progress)
+			}
+			select {
+			case <-orm.Context().Done():
+				checkError(orm.Context().Err())
+			case <-time.After(throttleInterval):
+			}
+		}
+		if options.Progress != nil {
+			options.Progress(progress)
+		}
+	}
+	db.Exec(orm, fmt.Sprintf("RENAME TABLE %s TO %s, %s TO %s", o.identifier(o.table), o.identifier(o.oldTable()),
+		o.identifier(o.shadowTable()), o.identifier(o.table)))
+	completed = true
+	o.dropTriggers(orm, db)
+	if !options.KeepOldTable {
+		db.Exec(orm, "DROP TABLE IF EXISTS "+o.identifier(o.oldTable()))
+	}
+}
+
+func (o *onlineAlter) copyChunk(orm ORM, db DB, copySQL string, from, to uint64) uint64 {
+	tx := db.Begin(orm)
+	defer tx.Rollback(orm)
+	copied := tx.Exec(orm, copySQL, from, to).RowsAffected()
+	rows, closeRows := tx.Query(orm, "SHOW WARNINGS")
+	var level, message string
+	var code uint16
+	for rows.Next() {
+		rows.Scan(&level, &code, &message)
+		if code != 1062 || !strings.HasSuffix(message, "PRIMARY'") {
+			closeRows()
+			panic(fmt.Errorf("online alter of table '%s' aborted: %s", o.table, message))
+		}
+	}
+	closeRows()
+	tx.Commit(orm)
+	return copied
+}
+
+func (o *onlineAlter) createTriggers(orm ORM, db DB) {
+	columns := "`" + strings.Join(o.columns, "`,`") + "`"
+	values := "NEW.`" + strings.Join(o.columns, "`,NEW.`") + "`"
+	replaceSQL := fmt.Sprintf("REPLACE INTO %s (%s) VALUES (%s)", o.identifier(o.shadowTable()), columns, values)
+	db.Exec(orm, fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT ON %s FOR EACH ROW %s",
+		o.identifier(o.triggerName("ins")), o.identifier(o.table), replaceSQL))
+	db.Exec(orm, fmt.Sprintf("CREATE TRIGGER %s AFTER UPDATE ON %s FOR EACH ROW %s",
+		o.identifier(o.triggerName("upd")), o.identifier(o.table), replaceSQL))
+	db.Exec(orm, fmt.Sprintf("CREATE TRIGGER %s AFTER DELETE ON %s FOR EACH ROW DELETE IGNORE FROM %s WHERE `ID` = OLD.`ID`",
+		o.identifier(o.triggerName("del")), o.identifier(o.table), o.identifier(o.shadowTable())))
+}
+
+func (o *onlineAlter) dropTriggers(orm ORM, db DB) {
+	for _, event := range []string{"ins", "upd", "del"} {
+		db.Exec(orm, "DROP TRIGGER IF EXISTS "+o.identifier(o.triggerName(event)))
+	}
+}
+
+func (o *onlineAlter) identifier(name string) string {
+	return fmt.Sprintf("`%s`.`%s`", o.database, name)
+}
+
+func (o *onlineAlter) shadowTable() string {
+	return "_" + o.table + "_new"
+}
+
+func (o *onlineAlter) oldTable() string {
+	return "_" + o.table + "_old"
+}
+
+func (o *onlineAlter) triggerName(event string) string {
+	return "_" + o.table + "_" + event
+}
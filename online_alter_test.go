@@ -0,0 +1,76 @@
+package beeorm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type onlineAlterEntity struct {
+	ID   uint64
+	Name string `orm:"required"`
+	Age  uint16
+}
+
+func TestOnlineAlter(t *testing.T) {
+	registry := NewRegistry()
+	orm := PrepareTables(t, registry, onlineAlterEntity{})
+	for i := 1; i <= 25; i++ {
+		entity := NewEntity[onlineAlterEntity](orm)
+		entity.Name = fmt.Sprintf("name %d", i)
+		entity.Age = uint16(i)
+	}
+	assert.NoError(t, orm.Flush())
+
+	db := orm.Engine().DB(DefaultPoolCode)
+	db.Exec(orm, "ALTER TABLE `onlineAlterEntity` DROP COLUMN `Age`, ADD COLUMN `Old` int DEFAULT NULL")
+	alters := GetAlters(orm)
+	assert.Len(t, alters, 1)
+	assert.True(t, alters[0].CanExecOnline())
+	assert.Equal(t, []string{"ID", "Name"}, alters[0].online.columns)
+
+	var progress []OnlineAlterProgress
+	alters[0].ExecOnline(orm, &OnlineAlterOptions{ChunkSize: 10, MaxReplicaLag: time.Second, Progress: func(p OnlineAlterProgress) {
+		progress = append(progress, p)
+		if len(progress) == 1 {
+			db.Exec(orm, "INSERT INTO `onlineAlterEntity` (`ID`, `Name`) VALUES (26, 'name 26')")
+			db.Exec(orm, "UPDATE `onlineAlterEntity` SET `Name` = 'updated 5' WHERE `ID` = 5")
+			db.Exec(orm, "UPDATE `onlineAlterEntity` SET `Name` = 'updated 15' WHERE `ID` = 15")
+			db.Exec(orm, "DELETE FROM `onlineAlterEntity` WHERE `ID` IN (3, 20)")
+		}
+	}})
+	assert.Len(t, progress, 3)
+	assert.Equal(t, uint64(10), progress[0].Copied)
+	assert.Equal(t, uint64(23), progress[2].Copied)
+	assert.Equal(t, uint64(25), progress[2].LastID)
+	assert.Equal(t, uint64(25), progress[2].MaxID)
+	assert.False(t, progress[2].Throttled)
+	assert.Len(t, GetAlters(orm), 0)
+	entity, found := GetByID[onlineAlterEntity](orm, 25)
+	assert.True(t, found)
+	assert.Equal(t, "name 25", entity.Name)
+	assert.Equal(t, uint16(0), entity.Age)
+	total := 0
+	db.QueryRow(orm, NewWhere("SELECT COUNT(*) FROM `onlineAlterEntity`"), &total)
+	assert.Equal(t, 24, total)
+	names := map[uint64]string{5: "updated 5", 15: "updated 15", 26: "name 26"}
+	for id, name := range names {
+		entity, found = GetByID[onlineAlterEntity](orm, id)
+		assert.True(t, found)
+		assert.Equal(t, name, entity.Name)
+	}
+	for _, id := range []uint64{3, 20} {
+		_, found = GetByID[onlineAlterEntity](orm, id)
+		assert.False(t, found)
+	}
+	var skip string
+	assert.False(t, db.QueryRow(orm, NewWhere("SHOW TABLES LIKE '_onlineAlterEntity_old'"), &skip))
+	assert.False(t, db.QueryRow(orm, NewWhere("SHOW TRIGGERS LIKE 'onlineAlterEntity'"), &skip))
+
+	alter := Alter{SQL: "ALTER TABLE `onlineAlterEntity` ADD COLUMN `Old` int DEFAULT NULL;", Pool: DefaultPoolCode}
+	assert.False(t, alter.CanExecOnline())
+	alter.ExecOnline(orm, nil)
+	assert.Len(t, GetAlters(orm), 1)
+}
@@ -0,0 +1,125 @@
+package beeorm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type optimisticLockEntity struct {
+	ID      uint64 `orm:"localCache;redisCache"`
+	Name    string
+	Version uint32 `orm:"version"`
+}
+
+func TestOptimisticLock(t *testing.T) {
+	var entity *optimisticLockEntity
+	orm := PrepareTables(t, NewRegistry(), entity)
+	schema := GetEntitySchema[optimisticLockEntity](orm)
+
+	entity = NewEntity[optimisticLockEntity](orm)
+	entity.Name = "Start"
+	assert.NoError(t, orm.Flush())
+	assert.Equal(t, uint32(0), entity.Version)
+	snapshot := *entity
+
+	first := EditEntity(orm, entity)
+	first.Name = "First"
+	assert.NoError(t, orm.Flush())
+	assert.Equal(t, uint32(1), first.Version)
+	loaded, _ := GetByID[optimisticLockEntity](orm, entity.ID)
+	assert.Equal(t, uint32(1), loaded.Version)
+	assert.Equal(t, "First", loaded.Name)
+
+	orm2 := orm.Engine().NewORM(orm.Context())
+	stale := EditEntity(orm2, &snapshot)
+	stale.Name = "Stale"
+	err := orm2.Flush()
+	assert.IsType(t, &OptimisticLockError{}, err)
+	lockErr := err.(*OptimisticLockError)
+	assert.Equal(t, entity.ID, lockErr.ID)
+	assert.Equal(t, uint64(0), lockErr.Version)
+	assert.Equal(t, uint32(0), stale.Version)
+	assert.NoError(t, orm2.Flush())
+	retry := EditEntity(orm2, stale)
+	retry.Name = "Retry"
+	assert.IsType(t, &OptimisticLockError{}, orm2.Flush())
+	assert.Equal(t, uint32(0), retry.Version)
+
+	loaded, _ = GetByID[optimisticLockEntity](orm, entity.ID)
+	assert.Equal(t, "First", loaded.Name)
+	assert.Equal(t, uint32(1), loaded.Version)
+
+	assert.NoError(t, EditEntityField(orm, loaded, "Name", "Field"))
+	assert.NoError(t, orm.Flush())
+	lc, _ := schema.GetLocalCache()
+	lc.Clear(orm)
+	loaded, _ = GetByID[optimisticLockEntity](orm, entity.ID)
+	assert.Equal(t, "Field", loaded.Name)
+	assert.Equal(t, uint32(2), loaded.Version)
+
+	async := EditEntity(orm, loaded)
+	async.Name = "Async"
+	assert.EqualError(t, orm.FlushAsync(), "entity 'beeorm.optimisticLockEntity' with version field can't be updated with FlushAsync")
+	orm.ClearFlush()
+
+	other := NewEntity[optimisticLockEntity](orm)
+	other.Name = "Other"
+	assert.NoError(t, orm.Flush())
+	otherSnapshot := *other
+	bumped := EditEntity(orm2, &otherSnapshot)
+	bumped.Name = "Bumped"
+	assert.NoError(t, orm2.Flush())
+	together := EditEntity(orm, loaded)
+	together.Name = "Together"
+	conflict := EditEntity(orm, other)
+	conflict.Name = "Conflict"
+	err = orm.Flush()
+	assert.IsType(t, &OptimisticLockError{}, err)
+	assert.Equal(t, other.ID, err.(*OptimisticLockError).ID)
+	assert.Equal(t, uint32(2), together.Version)
+	loaded, _ = GetByID[optimisticLockEntity](orm, entity.ID)
+	assert.Equal(t, "Field", loaded.Name)
+	assert.Equal(t, uint32(2), loaded.Version)
+	together = EditEntity(orm, together)
+	together.Name = "Together retry"
+	assert.NoError(t, orm.Flush())
+	assert.Equal(t, uint32(3), together.Version)
+
+	var inTransaction *optimisticLockEntity
+	err = orm.Transaction(func(tx ORM) error {
+		inTransaction = EditEntity(tx, together)
+		inTransaction.Name = "Transaction"
+		if err := tx.Flush(); err != nil {
+			return err
+		}
+		assert.Equal(t, uint32(4), inTransaction.Version)
+		again := EditEntity(tx, inTransaction)
+		again.Name = "Transaction again"
+		if err := tx.Flush(); err != nil {
+			return err
+		}
+		assert.Equal(t, uint32(5), again.Version)
+		return errors.New("rollback")
+	})
+	assert.EqualError(t, err, "rollback")
+	assert.Equal(t, uint32(3), inTransaction.Version)
+	assert.Equal(t, uint32(3), together.Version)
+	loaded, _ = GetByID[optimisticLockEntity](orm, entity.ID)
+	assert.Equal(t, "Together retry", loaded.Name)
+	assert.Equal(t, uint32(3), loaded.Version)
+	assert.NoError(t, EditEntityField(orm, loaded, "Name", "After rollback"))
+	assert.NoError(t, orm.Flush())
+	assert.Equal(t, uint32(4), loaded.Version)
+
+	type invalidVersionEntity struct {
+		ID      uint64
+		Version string `orm:"version"`
+	}
+	registry := NewRegistry()
+	registry.RegisterMySQL("root:root@tcp(localhost:3377)/test", DefaultPoolCode, &MySQLOptions{})
+	registry.RegisterEntity(&invalidVersionEntity{})
+	_, err = registry.Validate()
+	assert.EqualError(t, err, "version field 'Version' must be unsigned integer")
+}
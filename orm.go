@@ -5,6 +5,7 @@ import (
 	"hash/maphash"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/puzpuzpuz/xsync/v2"
 )
@@ -23,6 +24,7 @@ type ORM interface {
 	Flush() error
 	FlushAsync() error
 	ClearFlush()
+	Transaction(f func(tx ORM) error) error
 	RedisPipeLine(pool string) *RedisPipeLine
 	RegisterQueryLogger(handler LogHandler, mysql, redis, local bool)
 	EnableQueryDebug()
@@ -32,27 +34,34 @@ type ORM interface {
 	getDBLoggers() (bool, []LogHandler)
 	getLocalCacheLoggers() (bool, []LogHandler)
 	getRedisLoggers() (bool, []LogHandler)
+	getDBTransaction(db *dbImplementation, begin bool) DBBase
 	trackEntity(e EntityFlush)
 }
 
 type ormImplementation struct {
-	context                context.Context
-	engine                 *engineImplementation
-	trackedEntities        *xsync.MapOf[uint64, *xsync.MapOf[uint64, EntityFlush]]
-	queryLoggersDB         []LogHandler
-	queryLoggersRedis      []LogHandler
-	queryLoggersLocalCache []LogHandler
-	hasRedisLogger         bool
-	hasDBLogger            bool
-	hasLocalCacheLogger    bool
-	meta                   Meta
-	stringBuilder          *strings.Builder
-	stringBuilder2         *strings.Builder
-	redisPipeLines         map[string]*RedisPipeLine
-	flushDBActions         map[string][]dbAction
-	flushPostActions       []func(orm ORM)
-	mutexFlush             sync.Mutex
-	mutexData              sync.Mutex
+	context                 context.Context
+	engine                  *engineImplementation
+	trackedEntities         *xsync.MapOf[uint64, *xsync.MapOf[uint64, EntityFlush]]
+	queryLoggersDB          []LogHandler
+	queryLoggersRedis       []LogHandler
+	queryLoggersLocalCache  []LogHandler
+	hasRedisLogger          bool
+	hasDBLogger             bool
+	hasLocalCacheLogger     bool
+	meta                    Meta
+	stringBuilder           *strings.Builder
+	stringBuilder2          *strings.Builder
+	redisPipeLines          map[string]*RedisPipeLine
+	flushDBActions          map[string][]dbAction
+	flushPostActions        []func(orm ORM)
+	flushEntityUpdates      []flushEntityUpdate
+	localCacheInvalidations map[string]*localCacheInvalidation
+	bulkOperations          []*bulkOperation
+	asyncEvents             []pendingAsyncEvent
+	transaction             *ormTransaction
+	stickyPools             map[string]time.Time
+	mutexFlush              sync.Mutex
+	mutexData               sync.Mutex
 }
 
 func (orm *ormImplementation) Context() context.Context {
@@ -137,11 +146,11 @@ func (orm *ormImplementation) getLocalCacheLoggers() (bool, []LogHandler) {
 func (orm *ormImplementation) trackEntity(e EntityFlush) {
 	orm.mutexFlush.Lock()
 	defer orm.mutexFlush.Unlock()
-	if orm.trackedEntities == nil {
-		orm.trackedEntities = xsync.NewTypedMapOf[uint64, *xsync.MapOf[uint64, EntityFlush]](func(seed maphash.Seed, u uint64) uint64 {
-			return u
-		})
-	}
+	orm.initTrackedEntities()
+	orm.storeTrackedEntity(e)
+}
+
+func (orm *ormImplementation) storeTrackedEntity(e EntityFlush) {
 	entities, loaded := orm.trackedEntities.LoadOrCompute(e.Schema().index, func() *xsync.MapOf[uint64, EntityFlush] {
 		entities := xsync.NewTypedMapOf[uint64, EntityFlush](func(seed maphash.Seed, u uint64) uint64 {
 			return u
@@ -153,3 +162,20 @@ func (orm *ormImplementation) trackEntity(e EntityFlush) {
 		entities.Store(e.ID(), e)
 	}
 }
+
+func (orm *ormImplementation) getTrackedEntity(schema *entitySchema, id uint64) EntityFlush {
+	entities, has := orm.trackedEntities.Load(schema.index)
+	if !has {
+		return nil
+	}
+	e, _ := entities.Load(id)
+	return e
+}
+
+func (orm *ormImplementation) initTrackedEntities() {
+	if orm.trackedEntities == nil {
+		orm.trackedEntities = xsync.NewTypedMapOf[uint64, *xsync.MapOf[uint64, EntityFlush]](func(seed maphash.Seed, u uint64) uint64 {
+			return u
+		})
+	}
+}
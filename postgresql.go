@@ -0,0 +1,488 @@
+package beeorm
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const postgreSQLWaitTimeout = 28800
+
+var postgreSQLLimitRegexp = regexp.MustCompile(`LIMIT\s+(\d+)\s*,\s*(\d+)`)
+
+var postgreSQLErrorCodesToSkip = []string{
+	"22001", // string_data_right_truncation
+	"22003", // numeric_value_out_of_range
+	"22P02", // invalid_text_representation
+	"23502", // not_null_violation
+	"23503", // foreign_key_violation
+	"23505", // unique_violation
+	"23514", // check_violation
+	"3D000", // invalid_catalog_name
+	"42601", // syntax_error
+	"42703", // undefined_column
+	"42P01", // undefined_table
+}
+
+var postgreSQLErrorCodesToRetry = []string{
+	"08000", // connection_exception
+	"08003", // connection_does_not_exist
+	"08006", // connection_failure
+	"40001", // serialization_failure
+	"40P01", // deadlock_detected
+	"55P03", // lock_not_available
+	"57P01", // admin_shutdown
+}
+
+type postgreSQLError interface {
+	error
+	SQLState() string
+}
+
+type postgreSQLDialect struct{}
+
+func (d *postgreSQLDialect) driverName(options *MySQLOptions) string {
+	if options != nil && options.Driver != "" {
+		return options.Driver
+	}
+	return "postgres"
+}
+
+func (d *postgreSQLDialect) translateQuery(query string) string {
+	var builder strings.Builder
+	builder.Grow(len(query) + 8)
+	parameter := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		char := query[i]
+		if quote != 0 {
+			builder.WriteByte(char)
+			if char == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch char {
+		case '\'', '"':
+			quote = char
+			builder.WriteByte(char)
+		case '`':
+			builder.WriteByte('"')
+		case '?':
+			parameter++
+			builder.WriteString("$" + strconv.Itoa(parameter))
+		case 'I':
+			if strings.HasPrefix(query[i:], "IFNULL(") {
+				builder.WriteString("COALESCE")
+				i += 5
+			} else {
+				builder.WriteByte(char)
+			}
+		default:
+			builder.WriteByte(char)
+		}
+	}
+	return postgreSQLLimitRegexp.ReplaceAllString(builder.String(), "LIMIT ${2} OFFSET ${1}")
+}
+
+func (d *postgreSQLDialect) translateArgs(args []any) []any {
+	var translated []any
+	for i, arg := range args {
+		if value, isBool := arg.(bool); isBool {
+			if translated == nil {
+				translated = slices.Clone(args)
+			}
+			translated[i] = 0
+			if value {
+				translated[i] = 1
+			}
+		}
+	}
+	if translated == nil {
+		return args
+	}
+	return translated
+}
+
+func (d *postgreSQLDialect) loadServerLimits(db *sql.DB) (maxConnections, waitTimeout int, err error) {
+	var value string
+	err = db.QueryRow("SHOW max_connections").Scan(&value)
+	if err != nil {
+		return 0, 0, err
+	}
+	maxConnections, err = strconv.Atoi(value)
+	if err != nil {
+		return 0, 0, err
+	}
+	return maxConnections, postgreSQLWaitTimeout, nil
+}
+
+func (d *postgreSQLDialect) tableIdentifier(_ MySQLConfig, tableName string) string {
+	return `"` + tableName + `"`
+}
+
+func (d *postgreSQLDialect) resetAutoIncrementSQL(_ MySQLConfig, _ string) string {
+	return ""
+}
+
+func (d *postgreSQLDialect) truncateTableSQL(config MySQLConfig, tableName string) string {
+	return "TRUNCATE TABLE " + d.tableIdentifier(config, tableName)
+}
+
+func (d *postgreSQLDialect) columnDefinition(column *columnDefinition) string {
+	definition := fmt.Sprintf(`"%s" %s`, column.name, postgreSQLColumnType(column.columnType))
+	if column.autoIncrement {
+		return definition + " GENERATED BY DEFAULT AS IDENTITY"
+	}
+	if column.notNull {
+		definition += " NOT NULL"
+	}
+	if column.defaultValue != "" {
+		definition += " DEFAULT " + column.defaultValue
+	}
+	return definition
+}
+
+func (d *postgreSQLDialect) upsertClause(schema *entitySchema, columns []string) string {
+	target := []string{"ID"}
+	if len(schema.uniqueIndices) > 0 {
+		indexNames := make([]string, 0, len(schema.uniqueIndices))
+		for indexName := range schema.uniqueIndices {
+			indexNames = append(indexNames, indexName)
+		}
+		sort.Strings(indexNames)
+		target = schema.uniqueIndices[indexNames[0]]
+	}
+	updates := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		updates = append(updates, `"`+column+`"=EXCLUDED."`+column+`"`)
+	}
+	if schema.versionColumn != "" {
+		updates = append(updates, fmt.Sprintf(`"%s"="%s"."%s"+1`, schema.versionColumn, schema.GetTableName(), schema.versionColumn))
+	}
+	if len(updates) == 0 {
+		updates = append(updates, fmt.Sprintf(`"%s"="%s"."%s"`, target[0], schema.GetTableName(), target[0]))
+	}
+	return ` ON CONFLICT ("` + strings.Join(target, `","`) + `") DO UPDATE SET ` + strings.Join(updates, ",") + ` RETURNING "ID"`
+}
+
+func (d *postgreSQLDialect) upsertReturnsID() bool {
+	return true
+}
+
+func (d *postgreSQLDialect) getAllTables(db DB) []string {
+	tables := make([]string, 0)
+	results, err := db.GetDBClient().Query("SELECT table_name FROM information_schema.tables " +
+		"WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'")
+	checkError(err)
+	defer func() {
+		_ = results.Close()
+	}()
+	for results.Next() {
+		var row string
+		err = results.Scan(&row)
+		checkError(err)
+		tables = append(tables, row)
+	}
+	err = results.Err()
+	checkError(err)
+	return tables
+}
+
+func (d *postgreSQLDialect) createTableSQL(_ ORM, _ DB, _ string) string {
+	return ""
+}
+
+func (d *postgreSQLDialect) skippableAsyncError(rec any) (error, bool) {
+	asPostgreSQLError, isPostgreSQLError := rec.(postgreSQLError)
+	if isPostgreSQLError && slices.Contains(postgreSQLErrorCodesToSkip, asPostgreSQLError.SQLState()) {
+		return asPostgreSQLError, true
+	}
+	return nil, false
+}
+
+func (d *postgreSQLDialect) retryableAsyncError(rec any) (error, bool) {
+	asError, isError := rec.(error)
+	if !isError {
+		return nil, false
+	}
+	asPostgreSQLError, isPostgreSQLError := asError.(postgreSQLError)
+	if (isPostgreSQLError && slices.Contains(postgreSQLErrorCodesToRetry, asPostgreSQLError.SQLState())) || isConnectionError(asError) {
+		return asError, true
+	}
+	return nil, false
+}
+
+type postgreSQLColumn struct {
+	name     string
+	dataType string
+	notNull  bool
+}
+
+func (d *postgreSQLDialect) getSchemaChanges(orm ORM, schema *entitySchema) (preAlters, alters, postAlters []Alter) {
+	indexes := make(map[string]*IndexSchemaDefinition)
+	columns, err := checkStruct(orm.Engine(), schema, schema.GetType(), indexes, nil, "", -1)
+	checkError(err)
+	pool := schema.GetDB().Primary()
+	poolCode := pool.GetConfig().GetCode()
+	tableName := schema.GetTableName()
+	dbTableName := tableName
+	var skip string
+	hasTable := pool.QueryRow(orm, NewWhere("SELECT table_name FROM information_schema.tables "+
+		"WHERE table_schema = current_schema() AND table_name = ?", tableName), &skip)
+	if !hasTable && schema.tableRenamedFrom != "" {
+		hasTable = pool.QueryRow(orm, NewWhere("SELECT table_name FROM information_schema.tables "+
+			"WHERE table_schema = current_schema() AND table_name = ?", schema.tableRenamedFrom), &skip)
+		if hasTable {
+			dbTableName = schema.tableRenamedFrom
+			preAlters = append(preAlters, Alter{SQL: fmt.Sprintf(`ALTER TABLE "%s" RENAME TO "%s";`, dbTableName, tableName),
+				Down: fmt.Sprintf(`ALTER TABLE "%s" RENAME TO "%s";`, tableName, dbTableName), Safe: true, Pool: poolCode})
+		}
+	}
+	if !hasTable {
+		createTableSQL := fmt.Sprintf("CREATE TABLE \"%s\" (\n", tableName)
+		for _, column := range columns {
+			createTableSQL += fmt.Sprintf("  %s,\n", column.Definition)
+		}
+		createTableSQL += "  PRIMARY KEY (\"ID\")\n);"
+		alters = append(alters, Alter{SQL: createTableSQL, Down: fmt.Sprintf(`DROP TABLE IF EXISTS "%s";`, tableName), Safe: true, Pool: poolCode})
+		for _, index := range sortedIndexes(indexes) {
+			postAlters = append(postAlters, Alter{SQL: buildPostgreSQLCreateIndexSQL(tableName, index),
+				Down: fmt.Sprintf(`DROP INDEX IF EXISTS "%s";`, tableIndexName(tableName, index.Name)), Safe: true, Pool: poolCode})
+		}
+		return
+	}
+
+	dbColumns := make([]*postgreSQLColumn, 0)
+	func() {
+		results, def := pool.Query(orm, "SELECT column_name, data_type, character_maximum_length, numeric_precision, "+
+			"numeric_scale, is_nullable FROM information_schema.columns WHERE table_schema = current_schema() "+
+			"AND table_name = ? ORDER BY ordinal_position", dbTableName)
+		defer def()
+		for results.Next() {
+			var name, dataType, nullable string
+			var length, precision, scale sql.NullInt64
+			results.Scan(&name, &dataType, &length, &precision, &scale, &nullable)
+			switch dataType {
+			case "character varying", "character":
+				if length.Valid {
+					dataType += fmt.Sprintf("(%d)", length.Int64)
+				}
+			case "numeric":
+				if precision.Valid {
+					dataType += fmt.Sprintf("(%d,%d)", precision.Int64, scale.Int64)
+				}
+			}
+			dbColumns = append(dbColumns, &postgreSQLColumn{name: name, dataType: dataType, notNull: nullable == "NO"})
+		}
+	}()
+	renamedTo := schema.getColumnsRenamedTo()
+	for _, dbColumn := range dbColumns {
+		newName, isRenamed := renamedTo[dbColumn.name]
+		if !isRenamed || slices.ContainsFunc(dbColumns, func(c *postgreSQLColumn) bool { return c.name == newName }) {
+			continue
+		}
+		preAlters = append(preAlters, Alter{SQL: fmt.Sprintf(`ALTER TABLE "%s" RENAME COLUMN "%s" TO "%s";`, tableName, dbColumn.name, newName),
+			Down: fmt.Sprintf(`ALTER TABLE "%s" RENAME COLUMN "%s" TO "%s";`, tableName, newName, dbColumn.name), Safe: true, Pool: poolCode})
+		dbColumn.name = newName
+	}
+
+	var changes []string
+	var downChanges []string
+	destructive := false
+	for _, column := range columns {
+		expectedType, expectedNotNull := parsePostgreSQLColumnDefinition(column.Definition)
+		var current *postgreSQLColumn
+		for _, dbColumn := range dbColumns {
+			if dbColumn.name == column.ColumnName {
+				current = dbColumn
+				break
+			}
+		}
+		if current == nil {
+			changes = append(changes, "ADD COLUMN "+column.Definition)
+			downChanges = append(downChanges, fmt.Sprintf(`DROP COLUMN "%s"`, column.ColumnName))
+			continue
+		}
+		if postgreSQLInformationSchemaType(expectedType) != current.dataType {
+			changes = append(changes, fmt.Sprintf(`ALTER COLUMN "%s" TYPE %s USING "%s"::%s`,
+				column.ColumnName, expectedType, column.ColumnName, expectedType))
+			downChanges = append(downChanges, fmt.Sprintf(`ALTER COLUMN "%s" TYPE %s USING "%s"::%s`,
+				column.ColumnName, current.dataType, column.ColumnName, current.dataType))
+			destructive = true
+		}
+		if expectedNotNull != current.notNull {
+			if expectedNotNull {
+				changes = append(changes, fmt.Sprintf(`ALTER COLUMN "%s" SET NOT NULL`, column.ColumnName))
+				downChanges = append(downChanges, fmt.Sprintf(`ALTER COLUMN "%s" DROP NOT NULL`, column.ColumnName))
+				destructive = true
+			} else {
+				changes = append(changes, fmt.Sprintf(`ALTER COLUMN "%s" DROP NOT NULL`, column.ColumnName))
+				downChanges = append(downChanges, fmt.Sprintf(`ALTER COLUMN "%s" SET NOT NULL`, column.ColumnName))
+			}
+		}
+	}
+OUTER:
+	for _, dbColumn := range dbColumns {
+		for _, column := range columns {
+			if column.ColumnName == dbColumn.name {
+				continue OUTER
+			}
+		}
+		changes = append(changes, fmt.Sprintf(`DROP COLUMN "%s"`, dbColumn.name))
+		downChanges = append(downChanges, fmt.Sprintf(`ADD COLUMN "%s" %s`, dbColumn.name, dbColumn.dataType))
+		destructive = true
+	}
+	if len(changes) > 0 {
+		alterSQL := fmt.Sprintf("ALTER TABLE \"%s\"\n    %s;", tableName, strings.Join(changes, ",\n    "))
+		downSQL := fmt.Sprintf("ALTER TABLE \"%s\"\n    %s;", tableName, strings.Join(downChanges, ",\n    "))
+		safe := !destructive || isTableEmpty(pool, dbTableName)
+		alters = append(alters, Alter{SQL: alterSQL, Down: downSQL, Safe: safe, Pool: poolCode})
+	}
+
+	dbIndexes := make(map[string]string)
+	func() {
+		results, def := pool.Query(orm, "SELECT indexname, indexdef FROM pg_indexes "+
+			"WHERE schemaname = current_schema() AND tablename = ?", dbTableName)
+		defer def()
+		for results.Next() {
+			var name, definition string
+			results.Scan(&name, &definition)
+			if name != dbTableName+"_pkey" {
+				dbIndexes[name] = definition
+			}
+		}
+	}()
+	for _, index := range sortedIndexes(indexes) {
+		indexName := tableIndexName(tableName, index.Name)
+		definition, has := dbIndexes[indexName]
+		delete(dbIndexes, indexName)
+		if has && postgreSQLIndexMatches(definition, index) {
+			continue
+		}
+		if has {
+			alters = append(alters, Alter{SQL: fmt.Sprintf(`DROP INDEX "%s";`, indexName), Down: definition + ";", Safe: true, Pool: poolCode})
+		}
+		postAlters = append(postAlters, Alter{SQL: buildPostgreSQLCreateIndexSQL(tableName, index),
+			Down: fmt.Sprintf(`DROP INDEX IF EXISTS "%s";`, indexName), Safe: true, Pool: poolCode})
+	}
+	droppedIndexes := make([]string, 0, len(dbIndexes))
+	for name := range dbIndexes {
+		droppedIndexes = append(droppedIndexes, name)
+	}
+	sort.Strings(droppedIndexes)
+	for _, name := range droppedIndexes {
+		alters = append(alters, Alter{SQL: fmt.Sprintf(`DROP INDEX "%s";`, name), Down: dbIndexes[name] + ";", Safe: true, Pool: poolCode})
+	}
+	return
+}
+
+func postgreSQLColumnType(mysqlType string) string {
+	mysqlType = strings.ToLower(mysqlType)
+	if index := strings.Index(mysqlType, " character set"); index > 0 {
+		mysqlType = mysqlType[0:index]
+	}
+	unsigned := strings.HasSuffix(mysqlType, " unsigned")
+	mysqlType = strings.TrimSuffix(mysqlType, " unsigned")
+	parts := columnTypeRegexp.FindStringSubmatch(mysqlType)
+	if parts == nil {
+		return "text"
+	}
+	switch parts[1] {
+	case "tinyint", "smallint":
+		if unsigned && parts[1] == "smallint" {
+			return "integer"
+		}
+		return "smallint"
+	case "mediumint", "int":
+		if unsigned {
+			return "bigint"
+		}
+		return "integer"
+	case "bigint":
+		return "bigint"
+	case "float":
+		return "real"
+	case "double":
+		return "double precision"
+	case "decimal":
+		return "numeric" + parts[2]
+	case "varchar", "char":
+		return parts[1] + parts[2]
+	case "datetime":
+		return "timestamp"
+	case "date":
+		return "date"
+	case "blob", "mediumblob", "longblob":
+		return "bytea"
+	default:
+		return "text"
+	}
+}
+
+func postgreSQLInformationSchemaType(columnType string) string {
+	switch {
+	case strings.HasPrefix(columnType, "varchar"):
+		return "character varying" + strings.TrimPrefix(columnType, "varchar")
+	case strings.HasPrefix(columnType, "char"):
+		return "character" + strings.TrimPrefix(columnType, "char")
+	case columnType == "timestamp":
+		return "timestamp without time zone"
+	default:
+		return columnType
+	}
+}
+
+func parsePostgreSQLColumnDefinition(definition string) (columnType string, notNull bool) {
+	definition = definition[strings.Index(definition[1:], `"`)+3:]
+	end := len(definition)
+	for _, keyword := range []string{" NOT NULL", " DEFAULT ", " GENERATED "} {
+		if index := strings.Index(definition, keyword); index >= 0 && index < end {
+			end = index
+		}
+	}
+	return definition[0:end], strings.Contains(definition, " NOT NULL") || strings.Contains(definition, " GENERATED ")
+}
+
+func buildPostgreSQLCreateIndexSQL(tableName string, index *IndexSchemaDefinition) string {
+	indexType := "INDEX"
+	if index.Unique {
+		indexType = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf(`CREATE %s "%s" ON "%s" ("%s");`, indexType, tableIndexName(tableName, index.Name),
+		tableName, strings.Join(index.GetColumns(), `","`))
+}
+
+func postgreSQLIndexMatches(definition string, index *IndexSchemaDefinition) bool {
+	if strings.Contains(definition, "UNIQUE INDEX") != index.Unique {
+		return false
+	}
+	start := strings.LastIndex(definition, "(")
+	end := strings.LastIndex(definition, ")")
+	if start < 0 || end < start {
+		return false
+	}
+	columns := strings.Split(definition[start+1:end], ",")
+	for i, column := range columns {
+		columns[i] = strings.Trim(strings.TrimSpace(column), `"`)
+	}
+	return slices.Equal(columns, index.GetColumns())
+}
+
+func getPostgreSQLDatabaseName(dataSourceName string) string {
+	for _, part := range strings.Fields(dataSourceName) {
+		if strings.HasPrefix(part, "dbname=") {
+			return strings.Trim(part[7:], "'")
+		}
+	}
+	parsed, err := url.Parse(dataSourceName)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(parsed.Path, "/")
+}
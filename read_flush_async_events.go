@@ -2,18 +2,47 @@ package beeorm
 
 import (
 	"slices"
+	"strings"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 )
 
+const asyncMoveErrorsScript = `
+local total = tonumber(ARGV[1])
+local current = redis.call("LRANGE", KEYS[1], 0, total - 1)
+if #current ~= total then return 0 end
+for i = 1, total do
+	if current[i] ~= ARGV[i + 3] then return 0 end
+end
+for i = total + 4, #ARGV do
+	if ARGV[2] == "stream" then
+		redis.call("XADD", KEYS[2], "*", ARGV[3], ARGV[i])
+	else
+		redis.call("RPUSH", KEYS[2], ARGV[i])
+	end
+end
+redis.call("LTRIM", KEYS[1], total, -1)
+return total
+`
+
 type AsyncFlushEvents interface {
 	EntitySchemas() []EntitySchema
 	EventsCount() uint64
+	Lag() uint64
+	PendingCount() uint64
 	ErrorsCount() uint64
+	ArchivedErrorsCount() uint64
 	Events(total int) []FlushEvent
 	Errors(total int, last bool) []FlushEventWithError
+	ArchivedErrors(total int, last bool) []FlushEventWithError
 	TrimEvents(total int)
 	TrimErrors(total int)
+	TrimArchivedErrors(total int)
+	RetryErrors(total int)
+	RetryAllErrors()
+	ArchiveErrors(total int)
+	Consumer() (consumer AsyncConsumerInfo, has bool)
 	RedilPool() string
 	RedisList() string
 }
@@ -32,7 +61,10 @@ type FlushEvent struct {
 
 type FlushEventWithError struct {
 	FlushEvent
-	Error string
+	Error        string
+	EntitySchema EntitySchema
+	EntityID     uint64
+	Time         time.Time
 }
 
 func (s *asyncFlushEvents) EntitySchemas() []EntitySchema {
@@ -47,14 +79,56 @@ func (s *asyncFlushEvents) RedisList() string {
 	return s.listName
 }
 
+func (s *asyncFlushEvents) Consumer() (consumer AsyncConsumerInfo, has bool) {
+	r := s.orm.Engine().Redis(s.redisPoolName)
+	value, has := r.Get(s.orm, getAsyncConsumerOwnerKey(s.listName))
+	if !has {
+		return consumer, false
+	}
+	_ = jsoniter.ConfigFastest.UnmarshalFromString(value, &consumer)
+	return consumer, true
+}
+
 func (s *asyncFlushEvents) EventsCount() uint64 {
 	r := s.orm.Engine().Redis(s.redisPoolName)
-	return uint64(r.LLen(s.orm, s.listName))
+	total := uint64(r.LLen(s.orm, s.listName))
+	if isAsyncFlushStreamsEnabled(s.orm) {
+		total += uint64(r.XLen(s.orm, getAsyncFlushStream(s.listName)))
+	}
+	return total
+}
+
+func (s *asyncFlushEvents) Lag() uint64 {
+	if !isAsyncFlushStreamsEnabled(s.orm) {
+		return s.EventsCount()
+	}
+	r := s.orm.Engine().Redis(s.redisPoolName)
+	group, has := readAsyncStreamGroup(s.orm, r, getAsyncFlushStream(s.listName))
+	if !has {
+		return s.EventsCount()
+	}
+	return uint64(r.LLen(s.orm, s.listName)) + uint64(group.Lag)
+}
+
+func (s *asyncFlushEvents) PendingCount() uint64 {
+	if !isAsyncFlushStreamsEnabled(s.orm) {
+		return 0
+	}
+	group, has := readAsyncStreamGroup(s.orm, s.orm.Engine().Redis(s.redisPoolName), getAsyncFlushStream(s.listName))
+	if !has {
+		return 0
+	}
+	return uint64(group.Pending)
 }
 
 func (s *asyncFlushEvents) ErrorsCount() uint64 {
 	r := s.orm.Engine().Redis(s.redisPoolName)
-	return uint64(r.LLen(s.orm, s.listName+flushAsyncEventsListErrorSuffix)) / 2
+	return uint64(r.LLen(s.orm, s.listName+flushAsyncEventsListErrorSuffix))
+}
+
+func (s *asyncFlushEvents) ArchivedErrorsCount() uint64 {
+	r := s.orm.Engine().Redis(s.redisPoolName)
+	return uint64(r.LLen(s.orm, s.listName+flushAsyncEventsListArchiveSuffix))
 }
 
 func (s *asyncFlushEvents) Events(total int) []FlushEvent {
@@ -62,42 +136,134 @@ func (s *asyncFlushEvents) Events(total int) []FlushEvent {
 	events := r.LRange(s.orm, s.listName, 0, int64(total-1))
 	results := make([]FlushEvent, len(events))
 	for i, event := range events {
-		var data []string
-		_ = jsoniter.ConfigFastest.UnmarshalFromString(event, &data)
-		if len(data) > 0 {
-			results[i].SQL = data[0]
-			if len(data) > 1 {
-				results[i].QueryAttributes = data[1:]
-			}
+		results[i] = parseFlushEvent(event)
+	}
+	if isAsyncFlushStreamsEnabled(s.orm) && len(results) < total {
+		messages := r.XRange(s.orm, getAsyncFlushStream(s.listName), "-", "+", int64(total-len(results)))
+		for _, message := range messages {
+			event, _ := message.Values[flushAsyncEventsStreamField].(string)
+			results = append(results, parseFlushEvent(event))
 		}
 	}
 	return results
 }
 
 func (s *asyncFlushEvents) Errors(total int, last bool) []FlushEventWithError {
+	return s.readDeadLetters(s.listName+flushAsyncEventsListErrorSuffix, total, last)
+}
+
+func (s *asyncFlushEvents) ArchivedErrors(total int, last bool) []FlushEventWithError {
+	return s.readDeadLetters(s.listName+flushAsyncEventsListArchiveSuffix, total, last)
+}
+
+func (s *asyncFlushEvents) TrimEvents(total int) {
+	r := s.orm.Engine().Redis(s.redisPoolName)
+	if isAsyncFlushStreamsEnabled(s.orm) {
+		inList := int(r.LLen(s.orm, s.listName))
+		if inList > 0 {
+			r.Ltrim(s.orm, s.listName, int64(total), -1)
+			if total <= inList {
+				return
+			}
+			total -= inList
+		}
+		stream := getAsyncFlushStream(s.listName)
+		messages := r.XRange(s.orm, stream, "-", "+", int64(total))
+		if len(messages) > 0 {
+			ids := make([]string, len(messages))
+			for i, message := range messages {
+				ids[i] = message.ID
+			}
+			r.XDel(s.orm, stream, ids...)
+		}
+		return
+	}
+	r.Ltrim(s.orm, s.listName, int64(total), int64(-total))
+}
+
+func (s *asyncFlushEvents) TrimErrors(total int) {
 	r := s.orm.Engine().Redis(s.redisPoolName)
-	var events []string
+	r.Ltrim(s.orm, s.listName+flushAsyncEventsListErrorSuffix, int64(total), -1)
+}
 
+func (s *asyncFlushEvents) TrimArchivedErrors(total int) {
+	r := s.orm.Engine().Redis(s.redisPoolName)
+	r.Ltrim(s.orm, s.listName+flushAsyncEventsListArchiveSuffix, int64(total), -1)
+}
+
+func (s *asyncFlushEvents) RetryErrors(total int) {
+	s.moveErrors(total, false)
+}
+
+func (s *asyncFlushEvents) RetryAllErrors() {
+	total := int(s.ErrorsCount())
+	for total > 0 {
+		total -= s.moveErrors(min(total, asyncConsumerPage), false)
+	}
+}
+
+func (s *asyncFlushEvents) ArchiveErrors(total int) {
+	s.moveErrors(total, true)
+}
+
+func (s *asyncFlushEvents) moveErrors(total int, archive bool) int {
+	if total <= 0 {
+		return 0
+	}
+	r := s.orm.Engine().Redis(s.redisPoolName)
+	errorsList := s.listName + flushAsyncEventsListErrorSuffix
+	destination := s.listName + flushAsyncEventsListArchiveSuffix
+	mode := "list"
+	if !archive && isAsyncFlushStreamsEnabled(s.orm) {
+		destination = getAsyncFlushStream(s.listName)
+		mode = "stream"
+	} else if !archive {
+		destination = s.listName
+	}
+	for {
+		deadLetters, consumed := parseAsyncDeadLetters(r.LRange(s.orm, errorsList, 0, int64(total*2-1)), total)
+		if len(deadLetters) == 0 {
+			return total
+		}
+		args := []any{len(consumed), mode, flushAsyncEventsStreamField}
+		for _, value := range consumed {
+			args = append(args, value)
+		}
+		for _, deadLetter := range deadLetters {
+			if archive {
+				asJSON, _ := jsoniter.ConfigFastest.MarshalToString(deadLetter)
+				args = append(args, asJSON)
+				continue
+			}
+			args = append(args, deadLetter.Event)
+		}
+		moved, _ := r.Eval(s.orm, asyncMoveErrorsScript, []string{errorsList, destination}, args...).(int64)
+		if moved > 0 {
+			return int(moved)
+		}
+	}
+}
+
+func (s *asyncFlushEvents) readDeadLetters(list string, total int, last bool) []FlushEventWithError {
+	r := s.orm.Engine().Redis(s.redisPoolName)
+	var values []string
 	if last {
-		events = r.LRange(s.orm, s.listName+flushAsyncEventsListErrorSuffix, int64(-total)*2, -1)
+		values = r.LRange(s.orm, list, int64(-total), -1)
 	} else {
-		events = r.LRange(s.orm, s.listName+flushAsyncEventsListErrorSuffix, 0, int64(total*2-1))
+		values = r.LRange(s.orm, list, 0, int64(total-1))
 	}
-	results := make([]FlushEventWithError, len(events)/2)
-	k := 0
-	for i, event := range events {
-		if i%2 == 0 {
-			var data []string
-			_ = jsoniter.ConfigFastest.UnmarshalFromString(event, &data)
-			if len(data) > 0 {
-				results[k].SQL = data[0]
-				if len(data) > 1 {
-					results[k].QueryAttributes = data[1:]
-				}
+	deadLetters, _ := parseAsyncDeadLetters(values, len(values))
+	results := make([]FlushEventWithError, len(deadLetters))
+	for i, deadLetter := range deadLetters {
+		results[i].FlushEvent = parseFlushEvent(deadLetter.Event)
+		results[i].Error = deadLetter.Error
+		results[i].EntityID = deadLetter.EntityID
+		results[i].Time = time.Unix(deadLetter.Time, 0)
+		for _, schema := range s.schemas {
+			if schema.GetTableName() == deadLetter.Table {
+				results[i].EntitySchema = schema
+				break
 			}
-		} else {
-			results[k].Error = event
-			k++
 		}
 	}
 	if last {
@@ -106,21 +272,42 @@ func (s *asyncFlushEvents) Errors(total int, last bool) []FlushEventWithError {
 	return results
 }
 
-func (s *asyncFlushEvents) TrimEvents(total int) {
-	r := s.orm.Engine().Redis(s.redisPoolName)
-	r.Ltrim(s.orm, s.listName, int64(total), int64(-total))
+func parseAsyncDeadLetters(values []string, total int) (deadLetters []asyncFlushDeadLetter, consumed []string) {
+	i := 0
+	for ; i < len(values) && len(deadLetters) < total; i++ {
+		var deadLetter asyncFlushDeadLetter
+		if strings.HasPrefix(values[i], "[") {
+			event, errorMessage := values[i], ""
+			if i+1 < len(values) {
+				i++
+				errorMessage = values[i]
+			}
+			deadLetter = buildAsyncFlushDeadLetter(event, errorMessage)
+		} else {
+			_ = jsoniter.ConfigFastest.UnmarshalFromString(values[i], &deadLetter)
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	return deadLetters, values[:i]
 }
 
-func (s *asyncFlushEvents) TrimErrors(total int) {
-	total = total * 2
-	r := s.orm.Engine().Redis(s.redisPoolName)
-	r.Ltrim(s.orm, s.listName+flushAsyncEventsListErrorSuffix, int64(total), int64(-total))
+func parseFlushEvent(event string) FlushEvent {
+	var data []string
+	_ = jsoniter.ConfigFastest.UnmarshalFromString(event, &data)
+	result := FlushEvent{}
+	if len(data) > 0 {
+		result.SQL = data[0]
+		if len(data) > 1 {
+			result.QueryAttributes = data[1:]
+		}
+	}
+	return result
 }
 
 func ReadAsyncFlushEvents(orm ORM) []AsyncFlushEvents {
 	stats := make([]AsyncFlushEvents, 0)
 	mapped := make(map[string]*asyncFlushEvents)
-	for _, schema := range orm.Engine().Registry().(*engineRegistryImplementation).entitySchemas {
+	for _, schema := range getAllShardSchemas(orm.Engine().Registry()) {
 		stat, has := mapped[schema.asyncCacheKey]
 		if has {
 			stat.schemas = append(stat.schemas, schema)
@@ -1,9 +1,14 @@
 package beeorm
 
 import (
+	"context"
+	"errors"
 	"strconv"
 	"testing"
+	"time"
 
+	jsoniter "github.com/json-iterator/go"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -84,11 +89,83 @@ func TestAsync(t *testing.T) {
 	assert.Len(t, errors[1].QueryAttributes, 2)
 	assert.Contains(t, errors[1].QueryAttributes[1], "test 1")
 	assert.Equal(t, "Error 1054 (42S22): Unknown column 'Name' in 'field list'", errors[1].Error)
+	assert.Equal(t, schema, errors[0].EntitySchema)
+	assert.Equal(t, errors[0].QueryAttributes[0], strconv.FormatUint(errors[0].EntityID, 10))
+	assert.NotEqual(t, errors[0].EntityID, errors[1].EntityID)
+	assert.WithinDuration(t, time.Now(), errors[0].Time, time.Minute)
+	errors = stat.Errors(1, true)
+	assert.Len(t, errors, 1)
+	assert.Contains(t, errors[0].QueryAttributes[1], "test "+strconv.Itoa(asyncConsumerPage+9))
 
 	stat.TrimErrors(1)
 	errors = stat.Errors(10, false)
 	assert.Len(t, errors, 10)
 	assert.Contains(t, errors[0].QueryAttributes[1], "test 1")
+	assert.Equal(t, uint64(asyncConsumerPage+9), stat.ErrorsCount())
+
+	stat.ArchiveErrors(9)
+	assert.Equal(t, uint64(asyncConsumerPage), stat.ErrorsCount())
+	assert.Equal(t, uint64(9), stat.ArchivedErrorsCount())
+	archived := stat.ArchivedErrors(10, false)
+	assert.Len(t, archived, 9)
+	assert.Contains(t, archived[0].QueryAttributes[1], "test 1")
+	assert.Equal(t, schema, archived[0].EntitySchema)
+	stat.TrimArchivedErrors(9)
+	assert.Equal(t, uint64(0), stat.ArchivedErrorsCount())
+
+	stat.RetryErrors(1)
+	assert.Equal(t, uint64(asyncConsumerPage-1), stat.ErrorsCount())
+	assert.Equal(t, uint64(1), stat.EventsCount())
+	events = stat.Events(1)
+	assert.Contains(t, events[0].QueryAttributes[1], "test 10")
+
+	schema.GetDB().Exec(orm, "ALTER TABLE flushEntityAsyncStats ADD COLUMN Name varchar(255) NOT NULL")
+	stat.RetryAllErrors()
+	assert.Equal(t, uint64(0), stat.ErrorsCount())
+	assert.Equal(t, uint64(asyncConsumerPage), stat.EventsCount())
+	err = runAsyncConsumer(orm, false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), stat.EventsCount())
+	assert.Equal(t, uint64(0), stat.ErrorsCount())
+	total := 0
+	iterator := Search[flushEntityAsyncStats](orm, NewWhere("1"), nil)
+	for iterator.Next() {
+		total++
+	}
+	assert.Equal(t, asyncConsumerPage, total)
+}
+
+func TestAsyncEventTarget(t *testing.T) {
+	table, id := parseAsyncEventTarget("INSERT INTO `Table`(`ID`,`Name`) VALUES(?,?)", []any{"12", "a"})
+	assert.Equal(t, "Table", table)
+	assert.Equal(t, uint64(12), id)
+	table, id = parseAsyncEventTarget("UPDATE `Table` SET `Name`=?,`Age`=? WHERE ID = ? AND `Version` = ?", []any{"a", "2", "13", "1"})
+	assert.Equal(t, "Table", table)
+	assert.Equal(t, uint64(13), id)
+	table, id = parseAsyncEventTarget("DELETE FROM `Table` WHERE ID IN (14)", nil)
+	assert.Equal(t, "Table", table)
+	assert.Equal(t, uint64(14), id)
+	table, id = parseAsyncEventTarget("UPDATE `Table` SET `Name`=? WHERE `ID` = ?", []any{"a", "15"})
+	assert.Equal(t, "Table", table)
+	assert.Equal(t, uint64(15), id)
+	table, id = parseAsyncEventTarget("DELETE FROM `Table` WHERE `ID` IN (16)", nil)
+	assert.Equal(t, "Table", table)
+	assert.Equal(t, uint64(16), id)
+	table, id = parseAsyncEventTarget("DELETE FROM `Table` WHERE ID IN (14,15)", nil)
+	assert.Equal(t, "Table", table)
+	assert.Equal(t, uint64(0), id)
+	table, id = parseAsyncEventTarget("SELECT 1", nil)
+	assert.Equal(t, "", table)
+	assert.Equal(t, uint64(0), id)
+
+	var deadLetter asyncFlushDeadLetter
+	err := jsoniter.ConfigFastest.UnmarshalFromString(newAsyncFlushDeadLetter(`["UPDATE `+"`Table`"+` SET `+"`Name`"+`=? WHERE ID = ?","a","7"]`,
+		errors.New("failed")), &deadLetter)
+	assert.NoError(t, err)
+	assert.Equal(t, "Table", deadLetter.Table)
+	assert.Equal(t, uint64(7), deadLetter.EntityID)
+	assert.Equal(t, "failed", deadLetter.Error)
+	assert.Greater(t, deadLetter.Time, int64(0))
 }
 
 func TestAsyncGrouped(t *testing.T) {
@@ -130,3 +207,103 @@ func TestAsyncGrouped(t *testing.T) {
 
 	}
 }
+
+func TestAsyncStreamsStats(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterRedisInMemory(DefaultPoolCode)
+	registry.EnableAsyncFlushStreams()
+	validatedRegistry, err := registry.Validate()
+	assert.NoError(t, err)
+	orm := validatedRegistry.NewORM(context.Background())
+	r := orm.Engine().Redis(DefaultPoolCode)
+	stat := &asyncFlushEvents{orm: orm, listName: flushAsyncEventsList, redisPoolName: DefaultPoolCode}
+	stream := getAsyncFlushStream(flushAsyncEventsList)
+
+	pushAsyncEvents(orm, r, flushAsyncEventsList, []any{`["INSERT INTO ` + "`Table`" + `(ID,Name) VALUES(?,?)","1","a"]`,
+		`["INSERT INTO ` + "`Table`" + `(ID,Name) VALUES(?,?)","2","b"]`})
+	assert.Equal(t, int64(0), r.LLen(orm, flushAsyncEventsList))
+	assert.Equal(t, uint64(2), stat.EventsCount())
+	assert.Equal(t, uint64(2), stat.Lag())
+	assert.Equal(t, uint64(0), stat.PendingCount())
+	events := stat.Events(10)
+	assert.Len(t, events, 2)
+	assert.Equal(t, []string{"1", "a"}, events[0].QueryAttributes)
+
+	r.XGroupCreateMkStream(orm, stream, flushAsyncEventsStreamGroup, "0")
+	streams := r.XReadGroup(orm, &redis.XReadGroupArgs{Group: flushAsyncEventsStreamGroup, Consumer: "dead", Streams: []string{stream, ">"}, Count: 1, Block: -1})
+	assert.Len(t, streams, 1)
+	assert.Equal(t, uint64(1), stat.Lag())
+	assert.Equal(t, uint64(1), stat.PendingCount())
+	assert.False(t, claimAsyncStreamPending(orm, r, stream, orm.Engine().NodeID()))
+
+	stat.TrimEvents(1)
+	assert.Equal(t, uint64(1), stat.EventsCount())
+	assert.Equal(t, []string{"2", "b"}, stat.Events(10)[0].QueryAttributes)
+
+	r.RPush(orm, flushAsyncEventsList+flushAsyncEventsListErrorSuffix, newAsyncFlushDeadLetter(`["UPDATE `+"`Table`"+` SET Name=? WHERE ID = ?","c","3"]`, errors.New("failed")))
+	assert.Equal(t, uint64(1), stat.ErrorsCount())
+	stat.RetryAllErrors()
+	assert.Equal(t, uint64(0), stat.ErrorsCount())
+	assert.Equal(t, uint64(2), stat.EventsCount())
+	assert.Equal(t, []string{"c", "3"}, stat.Events(10)[1].QueryAttributes)
+	assert.Equal(t, int64(0), r.LLen(orm, flushAsyncEventsList))
+
+	stat.TrimEvents(2)
+	r.RPush(orm, flushAsyncEventsList, `["legacy"]`)
+	pushAsyncEvents(orm, r, flushAsyncEventsList, []any{`["stream"]`})
+	assert.Equal(t, uint64(2), stat.EventsCount())
+	assert.Equal(t, uint64(2), stat.Lag())
+	events = stat.Events(10)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "legacy", events[0].SQL)
+	assert.Equal(t, "stream", events[1].SQL)
+	stat.TrimEvents(2)
+	assert.Equal(t, uint64(0), stat.EventsCount())
+
+	r.RPush(orm, flushAsyncEventsList, "[]")
+	pushAsyncEvents(orm, r, flushAsyncEventsList, []any{"[]"})
+	assert.Equal(t, uint64(2), stat.EventsCount())
+	lock, obtained := obtainAsyncConsumerLock(orm, flushAsyncEventsList, r)
+	assert.True(t, obtained)
+	stop := uint32(0)
+	lockLost := uint32(0)
+	consumeAsyncStreamEventsWithLock(context.Background(), orm, flushAsyncEventsList, nil, r, false, &stop, lock, &lockLost)
+	assert.Equal(t, uint64(0), stat.EventsCount())
+	assert.Equal(t, int64(0), r.LLen(orm, flushAsyncEventsList))
+}
+
+func TestAsyncLegacyDeadLetters(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterRedisInMemory(DefaultPoolCode)
+	validatedRegistry, err := registry.Validate()
+	assert.NoError(t, err)
+	orm := validatedRegistry.NewORM(context.Background())
+	r := orm.Engine().Redis(DefaultPoolCode)
+	stat := &asyncFlushEvents{orm: orm, listName: flushAsyncEventsList, redisPoolName: DefaultPoolCode}
+	errorsList := flushAsyncEventsList + flushAsyncEventsListErrorSuffix
+
+	r.RPush(orm, errorsList, `["UPDATE `+"`Table`"+` SET Name=? WHERE ID = ?","a","1"]`, "legacy error 1",
+		`["UPDATE `+"`Table`"+` SET Name=? WHERE ID = ?","b","2"]`, "legacy error 2")
+	r.RPush(orm, errorsList, newAsyncFlushDeadLetter(`["UPDATE `+"`Table`"+` SET Name=? WHERE ID = ?","c","3"]`, errors.New("failed")))
+	deadLetters := stat.Errors(10, false)
+	assert.Len(t, deadLetters, 3)
+	assert.Equal(t, "legacy error 1", deadLetters[0].Error)
+	assert.Equal(t, []string{"a", "1"}, deadLetters[0].QueryAttributes)
+	assert.Equal(t, uint64(1), deadLetters[0].EntityID)
+	assert.Equal(t, "legacy error 2", deadLetters[1].Error)
+	assert.Equal(t, "failed", deadLetters[2].Error)
+
+	stat.ArchiveErrors(1)
+	assert.Equal(t, uint64(3), stat.ErrorsCount())
+	archived := stat.ArchivedErrors(10, false)
+	assert.Len(t, archived, 1)
+	assert.Equal(t, "legacy error 1", archived[0].Error)
+	assert.Equal(t, []string{"a", "1"}, archived[0].QueryAttributes)
+
+	stat.RetryAllErrors()
+	assert.Equal(t, uint64(0), stat.ErrorsCount())
+	events := stat.Events(10)
+	assert.Len(t, events, 2)
+	assert.Equal(t, []string{"b", "2"}, events[0].QueryAttributes)
+	assert.Equal(t, []string{"c", "3"}, events[1].QueryAttributes)
+}
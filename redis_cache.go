@@ -82,6 +82,7 @@ type RedisCache interface {
 	XClaim(orm ORM, a *redis.XClaimArgs) []redis.XMessage
 	XClaimJustID(orm ORM, a *redis.XClaimArgs) []string
 	XAck(orm ORM, stream, group string, ids ...string) int64
+	Publish(orm ORM, channel string, message any) int64
 	FlushAll(orm ORM)
 	FlushDB(orm ORM)
 	GetLocker() *Locker
@@ -1014,6 +1015,18 @@ func (r *redisCache) XAck(orm ORM, stream, group string, ids ...string) int64 {
 	return res
 }
 
+func (r *redisCache) Publish(orm ORM, channel string, message any) int64 {
+	hasLogger, _ := orm.getRedisLoggers()
+	start := getNow(hasLogger)
+	res, err := r.client.Publish(orm.Context(), channel, message).Result()
+	if hasLogger {
+		message := fmt.Sprintf("PUBLISH %s %v", channel, message)
+		r.fillLogFields(orm, "PUBLISH", message, start, false, err)
+	}
+	checkError(err)
+	return res
+}
+
 func (r *redisCache) FlushAll(orm ORM) {
 	hasLogger, _ := orm.getRedisLoggers()
 	start := getNow(hasLogger)
@@ -0,0 +1,1449 @@
+package beeorm
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const memoryRedisObtainLockScript = `
+if redis.call("set", KEYS[1], ARGV[1], "NX", "PX", ARGV[3]) then return redis.status_reply("OK") end
+
+local offset = tonumber(ARGV[2])
+if redis.call("getrange", KEYS[1], 0, offset-1) == string.sub(ARGV[1], 1, offset) then return redis.call("set", KEYS[1], ARGV[1], "PX", ARGV[3]) end
+`
+const memoryRedisRefreshLockScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+const memoryRedisReleaseLockScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+const memoryRedisLockTTLScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pttl", KEYS[1]) else return -3 end`
+
+var errMemoryRedisWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+var errMemoryRedisNotInteger = errors.New("ERR value is not an integer or out of range")
+var errMemoryRedisSyntax = errors.New("ERR syntax error")
+
+type memoryRedisScript func(s *memoryRedisServer, keys, args []string) any
+
+var memoryRedisScripts = map[string]memoryRedisScript{
+	memoryRedisScriptSHA(memoryRedisObtainLockScript):  (*memoryRedisServer).obtainLockScript,
+	memoryRedisScriptSHA(memoryRedisRefreshLockScript): (*memoryRedisServer).refreshLockScript,
+	memoryRedisScriptSHA(memoryRedisReleaseLockScript): (*memoryRedisServer).releaseLockScript,
+	memoryRedisScriptSHA(memoryRedisLockTTLScript):     (*memoryRedisServer).lockTTLScript,
+	memoryRedisScriptSHA(asyncMoveErrorsScript):        (*memoryRedisServer).moveAsyncErrorsScript,
+	memoryRedisScriptSHA(asyncConsumerTrimScript):      (*memoryRedisServer).asyncConsumerTrimScript,
+}
+
+type memoryRedisStatus string
+
+type memoryRedisNilArray struct{}
+
+type memoryRedisList struct {
+	values []string
+}
+
+type memoryRedisHash map[string]string
+
+type memoryRedisSet map[string]struct{}
+
+type memoryRedisSortedSet map[string]float64
+
+type memoryRedisKey struct {
+	value    any
+	expireAt time.Time
+}
+
+type memoryRedisServer struct {
+	address string
+	mutex   sync.Mutex
+	data    map[string]*memoryRedisKey
+	scripts map[string]bool
+	changed chan struct{}
+}
+
+func newMemoryRedisServer(address string) *memoryRedisServer {
+	return &memoryRedisServer{
+		address: address,
+		data:    make(map[string]*memoryRedisKey),
+		scripts: make(map[string]bool),
+		changed: make(chan struct{}),
+	}
+}
+
+func memoryRedisScriptSHA(script string) string {
+	hash := sha1.Sum([]byte(script))
+	return hex.EncodeToString(hash[:])
+}
+
+func (s *memoryRedisServer) dial(_ context.Context, _, _ string) (net.Conn, error) {
+	client, server := newMemoryRedisConnPair(s.address)
+	go s.serve(server)
+	return client, nil
+}
+
+func (s *memoryRedisServer) serve(conn *memoryRedisConn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	for {
+		args, err := readMemoryRedisCommand(reader)
+		if err != nil {
+			return
+		}
+		writeMemoryRedisReply(writer, s.execute(args, conn.reader.done))
+		if reader.Buffered() == 0 && writer.Flush() != nil {
+			return
+		}
+	}
+}
+
+func (s *memoryRedisServer) execute(args []string, closed <-chan struct{}) any {
+	if len(args) == 0 {
+		return errors.New("ERR empty command")
+	}
+	name := strings.ToLower(args[0])
+	switch name {
+	case "blmove":
+		if len(args) != 6 {
+			return memoryRedisArgumentsError(name)
+		}
+		timeout, err := strconv.ParseFloat(args[5], 64)
+		if err != nil {
+			return errors.New("ERR timeout is not a float or out of range")
+		}
+		return s.wait(time.Duration(timeout*float64(time.Second)), closed, nil, func() (any, bool) {
+			reply := s.lmove(args[1:5])
+			return reply, reply != nil
+		})
+	case "xread":
+		return s.xread(args[1:], closed)
+	case "xreadgroup":
+		return s.xreadgroup(args[1:], closed)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	reply := s.call(name, args[1:])
+	s.notify()
+	return reply
+}
+
+func (s *memoryRedisServer) wait(timeout time.Duration, closed <-chan struct{}, timeoutReply any, try func() (any, bool)) any {
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+	for {
+		s.mutex.Lock()
+		reply, done := try()
+		if done {
+			s.notify()
+		}
+		changed := s.changed
+		s.mutex.Unlock()
+		if done || timeout < 0 {
+			return reply
+		}
+		select {
+		case <-changed:
+		case <-closed:
+			return timeoutReply
+		case <-timer:
+			return timeoutReply
+		}
+	}
+}
+
+func (s *memoryRedisServer) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *memoryRedisServer) call(name string, args []string) any {
+	switch name {
+	case "ping":
+		if len(args) > 0 {
+			return args[0]
+		}
+		return memoryRedisStatus("PONG")
+	case "select", "auth", "readonly":
+		return memoryRedisStatus("OK")
+	case "client":
+		if len(args) > 0 && strings.ToLower(args[0]) == "getname" {
+			return nil
+		}
+		return memoryRedisStatus("OK")
+	case "info":
+		return fmt.Sprintf("# Server\r\nredis_version:7.2.0\r\nredis_mode:standalone\r\n\r\n# Keyspace\r\ndb0:keys=%d,expires=0\r\n", len(s.data))
+	case "dbsize":
+		return int64(len(s.data))
+	case "flushdb", "flushall":
+		s.data = make(map[string]*memoryRedisKey)
+		return memoryRedisStatus("OK")
+	case "publish":
+		return int64(0)
+	case "get":
+		return s.getCommand(args)
+	case "set":
+		return s.setCommand(args)
+	case "setnx":
+		if len(args) != 2 {
+			return memoryRedisArgumentsError(name)
+		}
+		if s.get(args[0]) != nil {
+			return int64(0)
+		}
+		s.put(args[0], args[1])
+		return int64(1)
+	case "getrange":
+		return s.getRangeCommand(args)
+	case "mset":
+		if len(args) == 0 || len(args)%2 != 0 {
+			return memoryRedisArgumentsError(name)
+		}
+		for i := 0; i < len(args); i += 2 {
+			s.put(args[i], args[i+1])
+		}
+		return memoryRedisStatus("OK")
+	case "mget":
+		values := make([]any, len(args))
+		for i, key := range args {
+			if value, isString := s.get(key).(string); isString {
+				values[i] = value
+			}
+		}
+		return values
+	case "del", "unlink":
+		deleted := int64(0)
+		for _, key := range args {
+			if s.get(key) != nil {
+				delete(s.data, key)
+				deleted++
+			}
+		}
+		return deleted
+	case "exists":
+		found := int64(0)
+		for _, key := range args {
+			if s.get(key) != nil {
+				found++
+			}
+		}
+		return found
+	case "type":
+		if len(args) != 1 {
+			return memoryRedisArgumentsError(name)
+		}
+		return memoryRedisStatus(memoryRedisType(s.get(args[0])))
+	case "expire", "pexpire":
+		return s.expireCommand(name, args)
+	case "ttl", "pttl":
+		return s.ttlCommand(name, args)
+	case "incr", "decr", "incrby", "decrby":
+		return s.incrCommand(name, args)
+	case "lpush", "rpush":
+		return s.pushCommand(name, args)
+	case "lpop", "rpop":
+		return s.popCommand(name, args)
+	case "llen":
+		list, err := s.getList(args, false)
+		if err != nil {
+			return err
+		}
+		return int64(len(list.values))
+	case "lrange":
+		return s.lrangeCommand(args)
+	case "lset":
+		return s.lsetCommand(args)
+	case "lrem":
+		return s.lremCommand(args)
+	case "ltrim":
+		return s.ltrimCommand(args)
+	case "lmove":
+		if len(args) != 4 {
+			return memoryRedisArgumentsError(name)
+		}
+		return s.lmove(args)
+	case "hset", "hmset":
+		return s.hsetCommand(name, args)
+	case "hsetnx":
+		return s.hsetnxCommand(args)
+	case "hdel":
+		return s.hdelCommand(args)
+	case "hget":
+		return s.hgetCommand(args)
+	case "hmget":
+		return s.hmgetCommand(args)
+	case "hgetall":
+		return s.hgetallCommand(args)
+	case "hlen":
+		hash, err := s.getHash(args, false)
+		if err != nil {
+			return err
+		}
+		return int64(len(hash))
+	case "hincrby":
+		return s.hincrbyCommand(args)
+	case "sadd":
+		return s.saddCommand(args)
+	case "srem":
+		return s.sremCommand(args)
+	case "smembers":
+		return s.smembersCommand(args)
+	case "sismember":
+		return s.sismemberCommand(args)
+	case "scard":
+		set, err := s.getSet(args, false)
+		if err != nil {
+			return err
+		}
+		return int64(len(set))
+	case "spop":
+		return s.spopCommand(args)
+	case "zadd":
+		return s.zaddCommand(args)
+	case "zrem":
+		return s.zremCommand(args)
+	case "zrange", "zrevrange":
+		return s.zrangeCommand(name, args)
+	case "zcard":
+		sortedSet, err := s.getSortedSet(args, false)
+		if err != nil {
+			return err
+		}
+		return int64(len(sortedSet))
+	case "zcount":
+		return s.zcountCommand(args)
+	case "zscore":
+		return s.zscoreCommand(args)
+	case "xadd":
+		return s.xaddCommand(args)
+	case "xlen":
+		return s.xlenCommand(args)
+	case "xrange", "xrevrange":
+		return s.xrangeCommand(name, args)
+	case "xdel":
+		return s.xdelCommand(args)
+	case "xtrim":
+		return s.xtrimCommand(args)
+	case "xgroup":
+		return s.xgroupCommand(args)
+	case "xack":
+		return s.xackCommand(args)
+	case "xpending":
+		return s.xpendingCommand(args)
+	case "xclaim":
+		return s.xclaimCommand(args)
+	case "xinfo":
+		return s.xinfoCommand(args)
+	case "eval", "evalsha":
+		return s.evalCommand(name, args)
+	case "script":
+		return s.scriptCommand(args)
+	}
+	return fmt.Errorf("ERR unknown command '%s'", name)
+}
+
+func memoryRedisArgumentsError(name string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", name)
+}
+
+func memoryRedisType(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case *memoryRedisList:
+		return "list"
+	case memoryRedisHash:
+		return "hash"
+	case memoryRedisSet:
+		return "set"
+	case memoryRedisSortedSet:
+		return "zset"
+	case *memoryRedisStream:
+		return "stream"
+	}
+	return "none"
+}
+
+func (s *memoryRedisServer) get(key string) any {
+	entry, has := s.data[key]
+	if !has {
+		return nil
+	}
+	if !entry.expireAt.IsZero() && !time.Now().Before(entry.expireAt) {
+		delete(s.data, key)
+		return nil
+	}
+	return entry.value
+}
+
+func (s *memoryRedisServer) put(key string, value any) {
+	s.data[key] = &memoryRedisKey{value: value}
+}
+
+func (s *memoryRedisServer) update(key string, value any) {
+	if entry, has := s.data[key]; has {
+		entry.value = value
+		return
+	}
+	s.put(key, value)
+}
+
+func (s *memoryRedisServer) deleteIfEmpty(key string, size int) {
+	if size == 0 {
+		delete(s.data, key)
+	}
+}
+
+func getMemoryRedisValue[T any](s *memoryRedisServer, args []string, create func() T) (value T, err error) {
+	if len(args) == 0 {
+		return value, errMemoryRedisSyntax
+	}
+	current := s.get(args[0])
+	if current == nil {
+		if create != nil {
+			value = create()
+			s.put(args[0], value)
+		}
+		return value, nil
+	}
+	value, valid := current.(T)
+	if !valid {
+		return value, errMemoryRedisWrongType
+	}
+	return value, nil
+}
+
+func (s *memoryRedisServer) getList(args []string, create bool) (*memoryRedisList, error) {
+	var creator func() *memoryRedisList
+	if create {
+		creator = func() *memoryRedisList {
+			return &memoryRedisList{}
+		}
+	}
+	list, err := getMemoryRedisValue[*memoryRedisList](s, args, creator)
+	if list == nil && err == nil {
+		list = &memoryRedisList{}
+	}
+	return list, err
+}
+
+func (s *memoryRedisServer) getHash(args []string, create bool) (memoryRedisHash, error) {
+	var creator func() memoryRedisHash
+	if create {
+		creator = func() memoryRedisHash {
+			return memoryRedisHash{}
+		}
+	}
+	return getMemoryRedisValue[memoryRedisHash](s, args, creator)
+}
+
+func (s *memoryRedisServer) getSet(args []string, create bool) (memoryRedisSet, error) {
+	var creator func() memoryRedisSet
+	if create {
+		creator = func() memoryRedisSet {
+			return memoryRedisSet{}
+		}
+	}
+	return getMemoryRedisValue[memoryRedisSet](s, args, creator)
+}
+
+func (s *memoryRedisServer) getSortedSet(args []string, create bool) (memoryRedisSortedSet, error) {
+	var creator func() memoryRedisSortedSet
+	if create {
+		creator = func() memoryRedisSortedSet {
+			return memoryRedisSortedSet{}
+		}
+	}
+	return getMemoryRedisValue[memoryRedisSortedSet](s, args, creator)
+}
+
+func (s *memoryRedisServer) getCommand(args []string) any {
+	if len(args) != 1 {
+		return memoryRedisArgumentsError("get")
+	}
+	value := s.get(args[0])
+	if value == nil {
+		return nil
+	}
+	asString, isString := value.(string)
+	if !isString {
+		return errMemoryRedisWrongType
+	}
+	return asString
+}
+
+func (s *memoryRedisServer) setCommand(args []string) any {
+	if len(args) < 2 {
+		return memoryRedisArgumentsError("set")
+	}
+	key := args[0]
+	var expireAt time.Time
+	nx, xx, keepTTL, get := false, false, false, false
+	for i := 2; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "nx":
+			nx = true
+		case "xx":
+			xx = true
+		case "keepttl":
+			keepTTL = true
+		case "get":
+			get = true
+		case "ex", "px":
+			if i+1 >= len(args) {
+				return errMemoryRedisSyntax
+			}
+			value, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || value <= 0 {
+				return errors.New("ERR invalid expire time in 'set' command")
+			}
+			unit := time.Second
+			if strings.ToLower(args[i]) == "px" {
+				unit = time.Millisecond
+			}
+			expireAt = time.Now().Add(time.Duration(value) * unit)
+			i++
+		default:
+			return errMemoryRedisSyntax
+		}
+	}
+	current := s.get(key)
+	var previous any
+	if get && current != nil {
+		asString, isString := current.(string)
+		if !isString {
+			return errMemoryRedisWrongType
+		}
+		previous = asString
+	}
+	if (nx && current != nil) || (xx && current == nil) {
+		return previous
+	}
+	if keepTTL && current != nil {
+		expireAt = s.data[key].expireAt
+	}
+	s.data[key] = &memoryRedisKey{value: args[1], expireAt: expireAt}
+	if get {
+		return previous
+	}
+	return memoryRedisStatus("OK")
+}
+
+func (s *memoryRedisServer) getRangeCommand(args []string) any {
+	if len(args) != 3 {
+		return memoryRedisArgumentsError("getrange")
+	}
+	value, err := getMemoryRedisValue[string](s, args, nil)
+	if err != nil {
+		return err
+	}
+	start, stop, valid := parseMemoryRedisRange(args[1], args[2], len(value))
+	if !valid {
+		return ""
+	}
+	return value[start : stop+1]
+}
+
+func (s *memoryRedisServer) expireCommand(name string, args []string) any {
+	if len(args) != 2 {
+		return memoryRedisArgumentsError(name)
+	}
+	value, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return errMemoryRedisNotInteger
+	}
+	if s.get(args[0]) == nil {
+		return int64(0)
+	}
+	unit := time.Second
+	if name == "pexpire" {
+		unit = time.Millisecond
+	}
+	if value <= 0 {
+		delete(s.data, args[0])
+		return int64(1)
+	}
+	s.data[args[0]].expireAt = time.Now().Add(time.Duration(value) * unit)
+	return int64(1)
+}
+
+func (s *memoryRedisServer) ttlCommand(name string, args []string) any {
+	if len(args) != 1 {
+		return memoryRedisArgumentsError(name)
+	}
+	if s.get(args[0]) == nil {
+		return int64(-2)
+	}
+	expireAt := s.data[args[0]].expireAt
+	if expireAt.IsZero() {
+		return int64(-1)
+	}
+	if name == "pttl" {
+		return time.Until(expireAt).Milliseconds()
+	}
+	return int64(math.Ceil(time.Until(expireAt).Seconds()))
+}
+
+func (s *memoryRedisServer) incrCommand(name string, args []string) any {
+	if len(args) == 0 {
+		return memoryRedisArgumentsError(name)
+	}
+	incr := int64(1)
+	if name == "incrby" || name == "decrby" {
+		if len(args) != 2 {
+			return memoryRedisArgumentsError(name)
+		}
+		value, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errMemoryRedisNotInteger
+		}
+		incr = value
+	}
+	if strings.HasPrefix(name, "decr") {
+		incr = -incr
+	}
+	current, err := getMemoryRedisValue[string](s, args, nil)
+	if err != nil {
+		return err
+	}
+	value := int64(0)
+	if current != "" {
+		value, err = strconv.ParseInt(current, 10, 64)
+		if err != nil {
+			return errMemoryRedisNotInteger
+		}
+	}
+	value += incr
+	s.update(args[0], strconv.FormatInt(value, 10))
+	return value
+}
+
+func (s *memoryRedisServer) pushCommand(name string, args []string) any {
+	if len(args) < 2 {
+		return memoryRedisArgumentsError(name)
+	}
+	list, err := s.getList(args, true)
+	if err != nil {
+		return err
+	}
+	for _, value := range args[1:] {
+		if name == "lpush" {
+			list.values = append([]string{value}, list.values...)
+		} else {
+			list.values = append(list.values, value)
+		}
+	}
+	return int64(len(list.values))
+}
+
+func (s *memoryRedisServer) popCommand(name string, args []string) any {
+	if len(args) == 0 || len(args) > 2 {
+		return memoryRedisArgumentsError(name)
+	}
+	list, err := s.getList(args, false)
+	if err != nil {
+		return err
+	}
+	count := 1
+	if len(args) == 2 {
+		count, err = strconv.Atoi(args[1])
+		if err != nil || count < 0 {
+			return errMemoryRedisNotInteger
+		}
+	}
+	if len(list.values) == 0 {
+		if len(args) == 2 {
+			return memoryRedisNilArray{}
+		}
+		return nil
+	}
+	if count > len(list.values) {
+		count = len(list.values)
+	}
+	var popped []string
+	if name == "lpop" {
+		popped = append(popped, list.values[0:count]...)
+		list.values = list.values[count:]
+	} else {
+		for i := 0; i < count; i++ {
+			popped = append(popped, list.values[len(list.values)-1-i])
+		}
+		list.values = list.values[0 : len(list.values)-count]
+	}
+	s.deleteIfEmpty(args[0], len(list.values))
+	if len(args) == 2 {
+		return popped
+	}
+	return popped[0]
+}
+
+func (s *memoryRedisServer) lrangeCommand(args []string) any {
+	if len(args) != 3 {
+		return memoryRedisArgumentsError("lrange")
+	}
+	list, err := s.getList(args, false)
+	if err != nil {
+		return err
+	}
+	start, stop, valid := parseMemoryRedisRange(args[1], args[2], len(list.values))
+	if !valid {
+		return []string{}
+	}
+	return append([]string{}, list.values[start:stop+1]...)
+}
+
+func (s *memoryRedisServer) lsetCommand(args []string) any {
+	if len(args) != 3 {
+		return memoryRedisArgumentsError("lset")
+	}
+	list, err := s.getList(args, false)
+	if err != nil {
+		return err
+	}
+	if len(list.values) == 0 {
+		return errors.New("ERR no such key")
+	}
+	index, err := strconv.Atoi(args[1])
+	if err != nil {
+		return errMemoryRedisNotInteger
+	}
+	if index < 0 {
+		index += len(list.values)
+	}
+	if index < 0 || index >= len(list.values) {
+		return errors.New("ERR index out of range")
+	}
+	list.values[index] = args[2]
+	return memoryRedisStatus("OK")
+}
+
+func (s *memoryRedisServer) lremCommand(args []string) any {
+	if len(args) != 3 {
+		return memoryRedisArgumentsError("lrem")
+	}
+	list, err := s.getList(args, false)
+	if err != nil {
+		return err
+	}
+	count, err := strconv.Atoi(args[1])
+	if err != nil {
+		return errMemoryRedisNotInteger
+	}
+	removed := 0
+	values := make([]string, 0, len(list.values))
+	if count >= 0 {
+		for _, value := range list.values {
+			if value == args[2] && (count == 0 || removed < count) {
+				removed++
+				continue
+			}
+			values = append(values, value)
+		}
+	} else {
+		for i := len(list.values) - 1; i >= 0; i-- {
+			if list.values[i] == args[2] && removed < -count {
+				removed++
+				continue
+			}
+			values = append([]string{list.values[i]}, values...)
+		}
+	}
+	list.values = values
+	s.deleteIfEmpty(args[0], len(list.values))
+	return int64(removed)
+}
+
+func (s *memoryRedisServer) ltrimCommand(args []string) any {
+	if len(args) != 3 {
+		return memoryRedisArgumentsError("ltrim")
+	}
+	list, err := s.getList(args, false)
+	if err != nil {
+		return err
+	}
+	start, stop, valid := parseMemoryRedisRange(args[1], args[2], len(list.values))
+	if !valid {
+		list.values = nil
+	} else {
+		list.values = append([]string{}, list.values[start:stop+1]...)
+	}
+	s.deleteIfEmpty(args[0], len(list.values))
+	return memoryRedisStatus("OK")
+}
+
+func (s *memoryRedisServer) lmove(args []string) any {
+	source, err := s.getList(args[0:1], false)
+	if err != nil {
+		return err
+	}
+	fromLeft := strings.ToLower(args[2]) == "left"
+	toLeft := strings.ToLower(args[3]) == "left"
+	if len(source.values) == 0 {
+		return nil
+	}
+	destination, err := s.getList(args[1:2], true)
+	if err != nil {
+		return err
+	}
+	var value string
+	if fromLeft {
+		value = source.values[0]
+		source.values = source.values[1:]
+	} else {
+		value = source.values[len(source.values)-1]
+		source.values = source.values[0 : len(source.values)-1]
+	}
+	if toLeft {
+		destination.values = append([]string{value}, destination.values...)
+	} else {
+		destination.values = append(destination.values, value)
+	}
+	s.deleteIfEmpty(args[0], len(source.values))
+	return value
+}
+
+func (s *memoryRedisServer) hsetCommand(name string, args []string) any {
+	if len(args) < 3 || len(args)%2 != 1 {
+		return memoryRedisArgumentsError(name)
+	}
+	hash, err := s.getHash(args, true)
+	if err != nil {
+		return err
+	}
+	added := int64(0)
+	for i := 1; i < len(args); i += 2 {
+		if _, has := hash[args[i]]; !has {
+			added++
+		}
+		hash[args[i]] = args[i+1]
+	}
+	if name == "hmset" {
+		return memoryRedisStatus("OK")
+	}
+	return added
+}
+
+func (s *memoryRedisServer) hsetnxCommand(args []string) any {
+	if len(args) != 3 {
+		return memoryRedisArgumentsError("hsetnx")
+	}
+	hash, err := s.getHash(args, true)
+	if err != nil {
+		return err
+	}
+	if _, has := hash[args[1]]; has {
+		return int64(0)
+	}
+	hash[args[1]] = args[2]
+	return int64(1)
+}
+
+func (s *memoryRedisServer) hdelCommand(args []string) any {
+	if len(args) < 2 {
+		return memoryRedisArgumentsError("hdel")
+	}
+	hash, err := s.getHash(args, false)
+	if err != nil {
+		return err
+	}
+	deleted := int64(0)
+	for _, field := range args[1:] {
+		if _, has := hash[field]; has {
+			delete(hash, field)
+			deleted++
+		}
+	}
+	s.deleteIfEmpty(args[0], len(hash))
+	return deleted
+}
+
+func (s *memoryRedisServer) hgetCommand(args []string) any {
+	if len(args) != 2 {
+		return memoryRedisArgumentsError("hget")
+	}
+	hash, err := s.getHash(args, false)
+	if err != nil {
+		return err
+	}
+	value, has := hash[args[1]]
+	if !has {
+		return nil
+	}
+	return value
+}
+
+func (s *memoryRedisServer) hmgetCommand(args []string) any {
+	if len(args) < 2 {
+		return memoryRedisArgumentsError("hmget")
+	}
+	hash, err := s.getHash(args, false)
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(args)-1)
+	for i, field := range args[1:] {
+		if value, has := hash[field]; has {
+			values[i] = value
+		}
+	}
+	return values
+}
+
+func (s *memoryRedisServer) hgetallCommand(args []string) any {
+	if len(args) != 1 {
+		return memoryRedisArgumentsError("hgetall")
+	}
+	hash, err := s.getHash(args, false)
+	if err != nil {
+		return err
+	}
+	values := make([]string, 0, len(hash)*2)
+	for field, value := range hash {
+		values = append(values, field, value)
+	}
+	return values
+}
+
+func (s *memoryRedisServer) hincrbyCommand(args []string) any {
+	if len(args) != 3 {
+		return memoryRedisArgumentsError("hincrby")
+	}
+	incr, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return errMemoryRedisNotInteger
+	}
+	hash, err := s.getHash(args, true)
+	if err != nil {
+		return err
+	}
+	value := int64(0)
+	if current, has := hash[args[1]]; has {
+		value, err = strconv.ParseInt(current, 10, 64)
+		if err != nil {
+			return errors.New("ERR hash value is not an integer")
+		}
+	}
+	value += incr
+	hash[args[1]] = strconv.FormatInt(value, 10)
+	return value
+}
+
+func (s *memoryRedisServer) saddCommand(args []string) any {
+	if len(args) < 2 {
+		return memoryRedisArgumentsError("sadd")
+	}
+	set, err := s.getSet(args, true)
+	if err != nil {
+		return err
+	}
+	added := int64(0)
+	for _, member := range args[1:] {
+		if _, has := set[member]; !has {
+			set[member] = struct{}{}
+			added++
+		}
+	}
+	return added
+}
+
+func (s *memoryRedisServer) sremCommand(args []string) any {
+	if len(args) < 2 {
+		return memoryRedisArgumentsError("srem")
+	}
+	set, err := s.getSet(args, false)
+	if err != nil {
+		return err
+	}
+	removed := int64(0)
+	for _, member := range args[1:] {
+		if _, has := set[member]; has {
+			delete(set, member)
+			removed++
+		}
+	}
+	s.deleteIfEmpty(args[0], len(set))
+	return removed
+}
+
+func (s *memoryRedisServer) smembersCommand(args []string) any {
+	if len(args) != 1 {
+		return memoryRedisArgumentsError("smembers")
+	}
+	set, err := s.getSet(args, false)
+	if err != nil {
+		return err
+	}
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
+
+func (s *memoryRedisServer) sismemberCommand(args []string) any {
+	if len(args) != 2 {
+		return memoryRedisArgumentsError("sismember")
+	}
+	set, err := s.getSet(args, false)
+	if err != nil {
+		return err
+	}
+	if _, has := set[args[1]]; has {
+		return int64(1)
+	}
+	return int64(0)
+}
+
+func (s *memoryRedisServer) spopCommand(args []string) any {
+	if len(args) == 0 || len(args) > 2 {
+		return memoryRedisArgumentsError("spop")
+	}
+	set, err := s.getSet(args, false)
+	if err != nil {
+		return err
+	}
+	count := 1
+	if len(args) == 2 {
+		count, err = strconv.Atoi(args[1])
+		if err != nil || count < 0 {
+			return errMemoryRedisNotInteger
+		}
+	}
+	popped := make([]string, 0, count)
+	for member := range set {
+		if len(popped) == count {
+			break
+		}
+		popped = append(popped, member)
+		delete(set, member)
+	}
+	s.deleteIfEmpty(args[0], len(set))
+	if len(args) == 2 {
+		return popped
+	}
+	if len(popped) == 0 {
+		return nil
+	}
+	return popped[0]
+}
+
+func (s *memoryRedisServer) zaddCommand(args []string) any {
+	if len(args) < 3 {
+		return memoryRedisArgumentsError("zadd")
+	}
+	nx, xx, changed := false, false, false
+	i := 1
+	for ; i < len(args); i++ {
+		option := strings.ToLower(args[i])
+		if option == "nx" {
+			nx = true
+		} else if option == "xx" {
+			xx = true
+		} else if option == "ch" {
+			changed = true
+		} else {
+			break
+		}
+	}
+	if (len(args)-i)%2 != 0 || i == len(args) {
+		return errMemoryRedisSyntax
+	}
+	sortedSet, err := s.getSortedSet(args, true)
+	if err != nil {
+		return err
+	}
+	total := int64(0)
+	for ; i < len(args); i += 2 {
+		score, err := strconv.ParseFloat(args[i], 64)
+		if err != nil {
+			s.deleteIfEmpty(args[0], len(sortedSet))
+			return errors.New("ERR value is not a valid float")
+		}
+		current, has := sortedSet[args[i+1]]
+		if (nx && has) || (xx && !has) {
+			continue
+		}
+		if !has || (changed && current != score) {
+			total++
+		}
+		sortedSet[args[i+1]] = score
+	}
+	s.deleteIfEmpty(args[0], len(sortedSet))
+	return total
+}
+
+func (s *memoryRedisServer) zremCommand(args []string) any {
+	if len(args) < 2 {
+		return memoryRedisArgumentsError("zrem")
+	}
+	sortedSet, err := s.getSortedSet(args, false)
+	if err != nil {
+		return err
+	}
+	removed := int64(0)
+	for _, member := range args[1:] {
+		if _, has := sortedSet[member]; has {
+			delete(sortedSet, member)
+			removed++
+		}
+	}
+	s.deleteIfEmpty(args[0], len(sortedSet))
+	return removed
+}
+
+func (s *memoryRedisServer) zrangeCommand(name string, args []string) any {
+	if len(args) < 3 {
+		return memoryRedisArgumentsError(name)
+	}
+	reverse := name == "zrevrange"
+	withScores := false
+	for _, option := range args[3:] {
+		switch strings.ToLower(option) {
+		case "withscores":
+			withScores = true
+		case "rev":
+			reverse = true
+		default:
+			return errMemoryRedisSyntax
+		}
+	}
+	sortedSet, err := s.getSortedSet(args, false)
+	if err != nil {
+		return err
+	}
+	members := make([]string, 0, len(sortedSet))
+	for member := range sortedSet {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if sortedSet[members[i]] == sortedSet[members[j]] {
+			return members[i] < members[j] != reverse
+		}
+		return sortedSet[members[i]] < sortedSet[members[j]] != reverse
+	})
+	start, stop, valid := parseMemoryRedisRange(args[1], args[2], len(members))
+	result := make([]string, 0)
+	if !valid {
+		return result
+	}
+	for _, member := range members[start : stop+1] {
+		result = append(result, member)
+		if withScores {
+			result = append(result, strconv.FormatFloat(sortedSet[member], 'f', -1, 64))
+		}
+	}
+	return result
+}
+
+func (s *memoryRedisServer) zcountCommand(args []string) any {
+	if len(args) != 3 {
+		return memoryRedisArgumentsError("zcount")
+	}
+	sortedSet, err := s.getSortedSet(args, false)
+	if err != nil {
+		return err
+	}
+	minScore, minExclusive, err := parseMemoryRedisScore(args[1])
+	if err != nil {
+		return err
+	}
+	maxScore, maxExclusive, err := parseMemoryRedisScore(args[2])
+	if err != nil {
+		return err
+	}
+	total := int64(0)
+	for _, score := range sortedSet {
+		if score < minScore || (minExclusive && score == minScore) || score > maxScore || (maxExclusive && score == maxScore) {
+			continue
+		}
+		total++
+	}
+	return total
+}
+
+func (s *memoryRedisServer) zscoreCommand(args []string) any {
+	if len(args) != 2 {
+		return memoryRedisArgumentsError("zscore")
+	}
+	sortedSet, err := s.getSortedSet(args, false)
+	if err != nil {
+		return err
+	}
+	score, has := sortedSet[args[1]]
+	if !has {
+		return nil
+	}
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+func (s *memoryRedisServer) evalCommand(name string, args []string) any {
+	if len(args) < 2 {
+		return memoryRedisArgumentsError(name)
+	}
+	sha := args[0]
+	if name == "eval" {
+		sha = memoryRedisScriptSHA(args[0])
+		s.scripts[sha] = true
+	} else if !s.scripts[sha] {
+		return errors.New("NOSCRIPT No matching script. Please use EVAL.")
+	}
+	keysCount, err := strconv.Atoi(args[1])
+	if err != nil || keysCount < 0 || keysCount > len(args)-2 {
+		return errors.New("ERR Number of keys can't be greater than number of args")
+	}
+	script, has := memoryRedisScripts[sha]
+	if !has {
+		return errors.New("ERR scripting is not supported by in-memory redis")
+	}
+	return script(s, args[2:2+keysCount], args[2+keysCount:])
+}
+
+func (s *memoryRedisServer) scriptCommand(args []string) any {
+	if len(args) == 0 {
+		return memoryRedisArgumentsError("script")
+	}
+	switch strings.ToLower(args[0]) {
+	case "load":
+		if len(args) != 2 {
+			return memoryRedisArgumentsError("script|load")
+		}
+		sha := memoryRedisScriptSHA(args[1])
+		s.scripts[sha] = true
+		return sha
+	case "exists":
+		exists := make([]any, len(args)-1)
+		for i, sha := range args[1:] {
+			exists[i] = int64(0)
+			if s.scripts[sha] {
+				exists[i] = int64(1)
+			}
+		}
+		return exists
+	case "flush":
+		s.scripts = make(map[string]bool)
+		return memoryRedisStatus("OK")
+	}
+	return errMemoryRedisSyntax
+}
+
+func (s *memoryRedisServer) obtainLockScript(keys, args []string) any {
+	if len(keys) != 1 || len(args) != 3 {
+		return errMemoryRedisSyntax
+	}
+	if s.setCommand([]string{keys[0], args[0], "NX", "PX", args[2]}) != nil {
+		return memoryRedisStatus("OK")
+	}
+	offset, err := strconv.Atoi(args[1])
+	if err != nil || offset > len(args[0]) {
+		return errMemoryRedisNotInteger
+	}
+	current, isString := s.get(keys[0]).(string)
+	if isString && len(current) >= offset && current[0:offset] == args[0][0:offset] {
+		return s.setCommand([]string{keys[0], args[0], "PX", args[2]})
+	}
+	return nil
+}
+
+func (s *memoryRedisServer) refreshLockScript(keys, args []string) any {
+	if len(keys) != 1 || len(args) != 2 {
+		return errMemoryRedisSyntax
+	}
+	if current, isString := s.get(keys[0]).(string); isString && current == args[0] {
+		return s.expireCommand("pexpire", []string{keys[0], args[1]})
+	}
+	return int64(0)
+}
+
+func (s *memoryRedisServer) releaseLockScript(keys, args []string) any {
+	if len(keys) != 1 || len(args) != 1 {
+		return errMemoryRedisSyntax
+	}
+	if current, isString := s.get(keys[0]).(string); isString && current == args[0] {
+		delete(s.data, keys[0])
+		return int64(1)
+	}
+	return int64(0)
+}
+
+func (s *memoryRedisServer) lockTTLScript(keys, args []string) any {
+	if len(keys) != 1 || len(args) != 1 {
+		return errMemoryRedisSyntax
+	}
+	if current, isString := s.get(keys[0]).(string); isString && current == args[0] {
+		return s.ttlCommand("pttl", keys)
+	}
+	return int64(-3)
+}
+
+func (s *memoryRedisServer) asyncConsumerTrimScript(keys, args []string) any {
+	if len(keys) != 2 || len(args) != 2 {
+		return errMemoryRedisSyntax
+	}
+	if current, isString := s.get(keys[0]).(string); !isString || current != args[0] {
+		return int64(0)
+	}
+	if reply, isError := s.ltrimCommand([]string{keys[1], args[1], "-1"}).(error); isError {
+		return reply
+	}
+	return int64(1)
+}
+
+func (s *memoryRedisServer) moveAsyncErrorsScript(keys, args []string) any {
+	if len(keys) != 2 || len(args) < 3 {
+		return errMemoryRedisSyntax
+	}
+	total, err := strconv.Atoi(args[0])
+	if err != nil || total < 0 || len(args) < total+3 {
+		return errMemoryRedisNotInteger
+	}
+	current, isList := s.lrangeCommand([]string{keys[0], "0", strconv.Itoa(total - 1)}).([]string)
+	if !isList || len(current) != total {
+		return int64(0)
+	}
+	for i, value := range current {
+		if value != args[i+3] {
+			return int64(0)
+		}
+	}
+	for _, value := range args[total+3:] {
+		var reply any
+		if args[1] == "stream" {
+			reply = s.xaddCommand([]string{keys[1], "*", args[2], value})
+		} else {
+			reply = s.pushCommand("rpush", []string{keys[1], value})
+		}
+		if replyErr, isError := reply.(error); isError {
+			return replyErr
+		}
+	}
+	s.ltrimCommand([]string{keys[0], strconv.Itoa(total), "-1"})
+	return int64(total)
+}
+
+func parseMemoryRedisRange(startArg, stopArg string, length int) (start, stop int, valid bool) {
+	start, err := strconv.Atoi(startArg)
+	if err != nil {
+		return 0, 0, false
+	}
+	stop, err = strconv.Atoi(stopArg)
+	if err != nil {
+		return 0, 0, false
+	}
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	return start, stop, start <= stop && start < length
+}
+
+func parseMemoryRedisScore(value string) (score float64, exclusive bool, err error) {
+	if strings.HasPrefix(value, "(") {
+		exclusive = true
+		value = value[1:]
+	}
+	switch strings.ToLower(value) {
+	case "-inf":
+		return math.Inf(-1), exclusive, nil
+	case "+inf", "inf":
+		return math.Inf(1), exclusive, nil
+	}
+	score, err = strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false, errors.New("ERR min or max is not a float")
+	}
+	return score, exclusive, nil
+}
+
+func readMemoryRedisLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func readMemoryRedisCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readMemoryRedisLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+	count, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		line, err = readMemoryRedisLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("invalid bulk string header '%s'", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, size+2)
+		_, err = io.ReadFull(reader, value)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = string(value[0:size])
+	}
+	return args, nil
+}
+
+func writeMemoryRedisReply(writer *bufio.Writer, reply any) {
+	switch value := reply.(type) {
+	case nil:
+		_, _ = writer.WriteString("$-1\r\n")
+	case memoryRedisNilArray:
+		_, _ = writer.WriteString("*-1\r\n")
+	case memoryRedisStatus:
+		_, _ = writer.WriteString("+" + string(value) + "\r\n")
+	case error:
+		_, _ = writer.WriteString("-" + value.Error() + "\r\n")
+	case int64:
+		_, _ = writer.WriteString(":" + strconv.FormatInt(value, 10) + "\r\n")
+	case string:
+		_, _ = writer.WriteString("$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n")
+	case []string:
+		_, _ = writer.WriteString("*" + strconv.Itoa(len(value)) + "\r\n")
+		for _, row := range value {
+			writeMemoryRedisReply(writer, row)
+		}
+	case []any:
+		_, _ = writer.WriteString("*" + strconv.Itoa(len(value)) + "\r\n")
+		for _, row := range value {
+			writeMemoryRedisReply(writer, row)
+		}
+	default:
+		_, _ = writer.WriteString(fmt.Sprintf("-ERR unsupported reply %T\r\n", reply))
+	}
+}
@@ -0,0 +1,161 @@
+package beeorm
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+type memoryRedisAddr struct {
+	address string
+}
+
+func (a memoryRedisAddr) Network() string {
+	return "memory"
+}
+
+func (a memoryRedisAddr) String() string {
+	return a.address
+}
+
+type memoryRedisTimeoutError struct{}
+
+func (e memoryRedisTimeoutError) Error() string {
+	return "i/o timeout"
+}
+
+func (e memoryRedisTimeoutError) Timeout() bool {
+	return true
+}
+
+func (e memoryRedisTimeoutError) Temporary() bool {
+	return true
+}
+
+type memoryRedisPipe struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+	notify chan struct{}
+	done   chan struct{}
+	closed bool
+}
+
+func newMemoryRedisPipe() *memoryRedisPipe {
+	return &memoryRedisPipe{notify: make(chan struct{}, 1), done: make(chan struct{})}
+}
+
+func (p *memoryRedisPipe) write(b []byte) (int, error) {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+	n, _ := p.buffer.Write(b)
+	p.mutex.Unlock()
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+	return n, nil
+}
+
+func (p *memoryRedisPipe) read(b []byte, deadline time.Time) (int, error) {
+	for {
+		p.mutex.Lock()
+		if p.buffer.Len() > 0 {
+			n, _ := p.buffer.Read(b)
+			p.mutex.Unlock()
+			return n, nil
+		}
+		if p.closed {
+			p.mutex.Unlock()
+			return 0, io.EOF
+		}
+		p.mutex.Unlock()
+		if deadline.IsZero() {
+			select {
+			case <-p.notify:
+			case <-p.done:
+			}
+			continue
+		}
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return 0, memoryRedisTimeoutError{}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-p.notify:
+		case <-p.done:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (p *memoryRedisPipe) close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.done)
+	}
+}
+
+type memoryRedisConn struct {
+	address      string
+	reader       *memoryRedisPipe
+	writer       *memoryRedisPipe
+	mutex        sync.Mutex
+	readDeadline time.Time
+}
+
+func newMemoryRedisConnPair(address string) (client, server *memoryRedisConn) {
+	requests := newMemoryRedisPipe()
+	responses := newMemoryRedisPipe()
+	client = &memoryRedisConn{address: address, reader: responses, writer: requests}
+	server = &memoryRedisConn{address: address, reader: requests, writer: responses}
+	return client, server
+}
+
+func (c *memoryRedisConn) Read(b []byte) (int, error) {
+	c.mutex.Lock()
+	deadline := c.readDeadline
+	c.mutex.Unlock()
+	return c.reader.read(b, deadline)
+}
+
+func (c *memoryRedisConn) Write(b []byte) (int, error) {
+	return c.writer.write(b)
+}
+
+func (c *memoryRedisConn) Close() error {
+	c.reader.close()
+	c.writer.close()
+	return nil
+}
+
+func (c *memoryRedisConn) LocalAddr() net.Addr {
+	return memoryRedisAddr{address: c.address}
+}
+
+func (c *memoryRedisConn) RemoteAddr() net.Addr {
+	return memoryRedisAddr{address: c.address}
+}
+
+func (c *memoryRedisConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *memoryRedisConn) SetReadDeadline(t time.Time) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.readDeadline = t
+	return nil
+}
+
+func (c *memoryRedisConn) SetWriteDeadline(_ time.Time) error {
+	return nil
+}
@@ -0,0 +1,828 @@
+package beeorm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type memoryRedisStreamID struct {
+	ms  uint64
+	seq uint64
+}
+
+func (id memoryRedisStreamID) String() string {
+	return strconv.FormatUint(id.ms, 10) + "-" + strconv.FormatUint(id.seq, 10)
+}
+
+func (id memoryRedisStreamID) less(other memoryRedisStreamID) bool {
+	return id.ms < other.ms || (id.ms == other.ms && id.seq < other.seq)
+}
+
+type memoryRedisStreamEntry struct {
+	id     memoryRedisStreamID
+	values []string
+}
+
+type memoryRedisPendingEntry struct {
+	id        memoryRedisStreamID
+	consumer  string
+	delivered time.Time
+	count     int64
+}
+
+type memoryRedisStreamGroup struct {
+	lastDelivered memoryRedisStreamID
+	entriesRead   int64
+	consumers     map[string]time.Time
+	pending       []*memoryRedisPendingEntry
+}
+
+type memoryRedisStream struct {
+	entries []memoryRedisStreamEntry
+	lastID  memoryRedisStreamID
+	added   int64
+	groups  map[string]*memoryRedisStreamGroup
+}
+
+func newMemoryRedisStream() *memoryRedisStream {
+	return &memoryRedisStream{groups: make(map[string]*memoryRedisStreamGroup)}
+}
+
+func parseMemoryRedisStreamID(value string, end bool) (memoryRedisStreamID, error) {
+	switch value {
+	case "-":
+		return memoryRedisStreamID{}, nil
+	case "+":
+		return memoryRedisStreamID{ms: math.MaxUint64, seq: math.MaxUint64}, nil
+	}
+	parts := strings.SplitN(value, "-", 2)
+	ms, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return memoryRedisStreamID{}, errors.New("ERR Invalid stream ID specified as stream command argument")
+	}
+	id := memoryRedisStreamID{ms: ms}
+	if len(parts) == 1 {
+		if end {
+			id.seq = math.MaxUint64
+		}
+		return id, nil
+	}
+	id.seq, err = strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return memoryRedisStreamID{}, errors.New("ERR Invalid stream ID specified as stream command argument")
+	}
+	return id, nil
+}
+
+func (s *memoryRedisServer) getStream(key string, create bool) (*memoryRedisStream, error) {
+	var creator func() *memoryRedisStream
+	if create {
+		creator = newMemoryRedisStream
+	}
+	return getMemoryRedisValue[*memoryRedisStream](s, []string{key}, creator)
+}
+
+func (s *memoryRedisServer) getStreamGroup(key, group, command string) (*memoryRedisStream, *memoryRedisStreamGroup, error) {
+	stream, err := s.getStream(key, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stream != nil {
+		if streamGroup, has := stream.groups[group]; has {
+			return stream, streamGroup, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s' in %s with GROUP option", key, group, command)
+}
+
+func (stream *memoryRedisStream) find(id memoryRedisStreamID) (memoryRedisStreamEntry, bool) {
+	for _, entry := range stream.entries {
+		if entry.id == id {
+			return entry, true
+		}
+	}
+	return memoryRedisStreamEntry{}, false
+}
+
+func (stream *memoryRedisStream) trim(maxLen int) int64 {
+	if maxLen < 0 || len(stream.entries) <= maxLen {
+		return 0
+	}
+	deleted := len(stream.entries) - maxLen
+	stream.entries = append([]memoryRedisStreamEntry{}, stream.entries[deleted:]...)
+	return int64(deleted)
+}
+
+func (entry memoryRedisStreamEntry) reply() []any {
+	return []any{entry.id.String(), append([]string{}, entry.values...)}
+}
+
+func (s *memoryRedisServer) xaddCommand(args []string) any {
+	if len(args) < 4 {
+		return memoryRedisArgumentsError("xadd")
+	}
+	key := args[0]
+	noMkStream := false
+	maxLen := -1
+	i := 1
+	for ; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "nomkstream":
+			noMkStream = true
+			continue
+		case "maxlen":
+			i++
+			if i < len(args) && (args[i] == "~" || args[i] == "=") {
+				i++
+			}
+			if i >= len(args) {
+				return errMemoryRedisSyntax
+			}
+			value, err := strconv.Atoi(args[i])
+			if err != nil {
+				return errMemoryRedisNotInteger
+			}
+			maxLen = value
+			continue
+		case "limit":
+			i++
+			continue
+		}
+		break
+	}
+	if i >= len(args) || (len(args)-i-1)%2 != 0 || len(args)-i-1 == 0 {
+		return memoryRedisArgumentsError("xadd")
+	}
+	stream, err := s.getStream(key, false)
+	if err != nil {
+		return err
+	}
+	if stream == nil {
+		if noMkStream {
+			return nil
+		}
+		stream, _ = s.getStream(key, true)
+	}
+	var id memoryRedisStreamID
+	if args[i] == "*" {
+		id.ms = uint64(time.Now().UnixMilli())
+		if id.ms <= stream.lastID.ms {
+			id = memoryRedisStreamID{ms: stream.lastID.ms, seq: stream.lastID.seq + 1}
+		}
+	} else {
+		id, err = parseMemoryRedisStreamID(args[i], false)
+		if err != nil {
+			return err
+		}
+		if !stream.lastID.less(id) {
+			return errors.New("ERR The ID specified in XADD is equal or smaller than the target stream top item")
+		}
+	}
+	stream.entries = append(stream.entries, memoryRedisStreamEntry{id: id, values: append([]string{}, args[i+1:]...)})
+	stream.lastID = id
+	stream.added++
+	stream.trim(maxLen)
+	return id.String()
+}
+
+func (s *memoryRedisServer) xlenCommand(args []string) any {
+	if len(args) != 1 {
+		return memoryRedisArgumentsError("xlen")
+	}
+	stream, err := s.getStream(args[0], false)
+	if err != nil {
+		return err
+	}
+	if stream == nil {
+		return int64(0)
+	}
+	return int64(len(stream.entries))
+}
+
+func (s *memoryRedisServer) xrangeCommand(name string, args []string) any {
+	if len(args) != 3 && len(args) != 5 {
+		return memoryRedisArgumentsError(name)
+	}
+	stream, err := s.getStream(args[0], false)
+	if err != nil {
+		return err
+	}
+	startArg, endArg := args[1], args[2]
+	if name == "xrevrange" {
+		startArg, endArg = endArg, startArg
+	}
+	start, err := parseMemoryRedisStreamRangeID(startArg, false)
+	if err != nil {
+		return err
+	}
+	end, err := parseMemoryRedisStreamRangeID(endArg, true)
+	if err != nil {
+		return err
+	}
+	count := -1
+	if len(args) == 5 {
+		if strings.ToLower(args[3]) != "count" {
+			return errMemoryRedisSyntax
+		}
+		count, err = strconv.Atoi(args[4])
+		if err != nil {
+			return errMemoryRedisNotInteger
+		}
+	}
+	result := make([]any, 0)
+	if stream == nil {
+		return result
+	}
+	for i := range stream.entries {
+		entry := stream.entries[i]
+		if name == "xrevrange" {
+			entry = stream.entries[len(stream.entries)-1-i]
+		}
+		if count >= 0 && len(result) >= count {
+			break
+		}
+		if entry.id.less(start) || end.less(entry.id) {
+			continue
+		}
+		result = append(result, entry.reply())
+	}
+	return result
+}
+
+func parseMemoryRedisStreamRangeID(value string, end bool) (memoryRedisStreamID, error) {
+	if !strings.HasPrefix(value, "(") {
+		return parseMemoryRedisStreamID(value, end)
+	}
+	id, err := parseMemoryRedisStreamID(value[1:], end)
+	if err != nil {
+		return id, err
+	}
+	if end {
+		if id.seq == 0 {
+			return memoryRedisStreamID{ms: id.ms - 1, seq: math.MaxUint64}, nil
+		}
+		id.seq--
+		return id, nil
+	}
+	if id.seq == math.MaxUint64 {
+		return memoryRedisStreamID{ms: id.ms + 1}, nil
+	}
+	id.seq++
+	return id, nil
+}
+
+func (s *memoryRedisServer) xdelCommand(args []string) any {
+	if len(args) < 2 {
+		return memoryRedisArgumentsError("xdel")
+	}
+	stream, err := s.getStream(args[0], false)
+	if err != nil {
+		return err
+	}
+	if stream == nil {
+		return int64(0)
+	}
+	deleted := int64(0)
+	for _, value := range args[1:] {
+		id, err := parseMemoryRedisStreamID(value, false)
+		if err != nil {
+			return err
+		}
+		for i, entry := range stream.entries {
+			if entry.id == id {
+				stream.entries = append(stream.entries[0:i], stream.entries[i+1:]...)
+				deleted++
+				break
+			}
+		}
+	}
+	return deleted
+}
+
+func (s *memoryRedisServer) xtrimCommand(args []string) any {
+	if len(args) < 3 || strings.ToLower(args[1]) != "maxlen" {
+		return errMemoryRedisSyntax
+	}
+	i := 2
+	if args[i] == "~" || args[i] == "=" {
+		i++
+	}
+	if i >= len(args) {
+		return errMemoryRedisSyntax
+	}
+	maxLen, err := strconv.Atoi(args[i])
+	if err != nil {
+		return errMemoryRedisNotInteger
+	}
+	stream, err := s.getStream(args[0], false)
+	if err != nil {
+		return err
+	}
+	if stream == nil {
+		return int64(0)
+	}
+	return stream.trim(maxLen)
+}
+
+func (s *memoryRedisServer) xgroupCommand(args []string) any {
+	if len(args) < 3 {
+		return memoryRedisArgumentsError("xgroup")
+	}
+	key, group := args[1], args[2]
+	switch strings.ToLower(args[0]) {
+	case "create":
+		if len(args) < 4 {
+			return memoryRedisArgumentsError("xgroup|create")
+		}
+		mkStream := len(args) > 4 && strings.ToLower(args[4]) == "mkstream"
+		stream, err := s.getStream(key, mkStream)
+		if err != nil {
+			return err
+		}
+		if stream == nil {
+			return errors.New("ERR The XGROUP subcommand requires the key to exist. " +
+				"Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically.")
+		}
+		if _, has := stream.groups[group]; has {
+			return errors.New("BUSYGROUP Consumer Group name already exists")
+		}
+		lastDelivered := stream.lastID
+		if args[3] != "$" {
+			lastDelivered, err = parseMemoryRedisStreamID(args[3], false)
+			if err != nil {
+				return err
+			}
+		}
+		stream.groups[group] = &memoryRedisStreamGroup{lastDelivered: lastDelivered, consumers: make(map[string]time.Time)}
+		return memoryRedisStatus("OK")
+	case "setid":
+		if len(args) < 4 {
+			return memoryRedisArgumentsError("xgroup|setid")
+		}
+		stream, streamGroup, err := s.getStreamGroup(key, group, "XGROUP")
+		if err != nil {
+			return err
+		}
+		streamGroup.lastDelivered = stream.lastID
+		if args[3] != "$" {
+			streamGroup.lastDelivered, err = parseMemoryRedisStreamID(args[3], false)
+			if err != nil {
+				return err
+			}
+		}
+		return memoryRedisStatus("OK")
+	case "destroy":
+		stream, err := s.getStream(key, false)
+		if err != nil {
+			return err
+		}
+		if stream == nil {
+			return errors.New("ERR no such key")
+		}
+		if _, has := stream.groups[group]; !has {
+			return int64(0)
+		}
+		delete(stream.groups, group)
+		return int64(1)
+	case "createconsumer", "delconsumer":
+		if len(args) != 4 {
+			return memoryRedisArgumentsError("xgroup|" + strings.ToLower(args[0]))
+		}
+		_, streamGroup, err := s.getStreamGroup(key, group, "XGROUP")
+		if err != nil {
+			return err
+		}
+		consumer := args[3]
+		_, has := streamGroup.consumers[consumer]
+		if strings.ToLower(args[0]) == "createconsumer" {
+			if has {
+				return int64(0)
+			}
+			streamGroup.consumers[consumer] = time.Now()
+			return int64(1)
+		}
+		delete(streamGroup.consumers, consumer)
+		deleted := int64(0)
+		pending := make([]*memoryRedisPendingEntry, 0, len(streamGroup.pending))
+		for _, entry := range streamGroup.pending {
+			if entry.consumer == consumer {
+				deleted++
+				continue
+			}
+			pending = append(pending, entry)
+		}
+		streamGroup.pending = pending
+		return deleted
+	}
+	return errMemoryRedisSyntax
+}
+
+type memoryRedisReadArgs struct {
+	group    string
+	consumer string
+	count    int
+	block    time.Duration
+	noAck    bool
+	streams  []string
+	ids      []string
+}
+
+func parseMemoryRedisReadArgs(args []string, withGroup bool) (*memoryRedisReadArgs, error) {
+	read := &memoryRedisReadArgs{block: -1}
+	i := 0
+	if withGroup {
+		if len(args) < 3 || strings.ToLower(args[0]) != "group" {
+			return nil, errMemoryRedisSyntax
+		}
+		read.group, read.consumer = args[1], args[2]
+		i = 3
+	}
+	for ; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "count":
+			i++
+			if i >= len(args) {
+				return nil, errMemoryRedisSyntax
+			}
+			count, err := strconv.Atoi(args[i])
+			if err != nil {
+				return nil, errMemoryRedisNotInteger
+			}
+			read.count = count
+		case "block":
+			i++
+			if i >= len(args) {
+				return nil, errMemoryRedisSyntax
+			}
+			block, err := strconv.ParseInt(args[i], 10, 64)
+			if err != nil || block < 0 {
+				return nil, errors.New("ERR timeout is not an integer or out of range")
+			}
+			read.block = time.Duration(block) * time.Millisecond
+		case "noack":
+			read.noAck = true
+		case "streams":
+			rest := args[i+1:]
+			if len(rest) == 0 || len(rest)%2 != 0 {
+				return nil, errors.New("ERR Unbalanced 'xread' list of streams: for each stream key an ID or '$' must be specified.")
+			}
+			read.streams = rest[0 : len(rest)/2]
+			read.ids = rest[len(rest)/2:]
+			return read, nil
+		default:
+			return nil, errMemoryRedisSyntax
+		}
+	}
+	return nil, errMemoryRedisSyntax
+}
+
+func (read *memoryRedisReadArgs) timeout() time.Duration {
+	if read.block < 0 {
+		return -1
+	}
+	if read.block == 0 {
+		return 0
+	}
+	return read.block
+}
+
+func (s *memoryRedisServer) xread(args []string, closed <-chan struct{}) any {
+	read, err := parseMemoryRedisReadArgs(args, false)
+	if err != nil {
+		return err
+	}
+	ids := make([]memoryRedisStreamID, len(read.streams))
+	s.mutex.Lock()
+	for i, key := range read.streams {
+		if read.ids[i] == "$" {
+			stream, err := s.getStream(key, false)
+			if err != nil {
+				s.mutex.Unlock()
+				return err
+			}
+			if stream != nil {
+				ids[i] = stream.lastID
+			}
+			continue
+		}
+		ids[i], err = parseMemoryRedisStreamID(read.ids[i], false)
+		if err != nil {
+			s.mutex.Unlock()
+			return err
+		}
+	}
+	s.mutex.Unlock()
+	return s.wait(read.timeout(), closed, memoryRedisNilArray{}, func() (any, bool) {
+		result := make([]any, 0)
+		for i, key := range read.streams {
+			stream, err := s.getStream(key, false)
+			if err != nil {
+				return err, true
+			}
+			if stream == nil {
+				continue
+			}
+			messages := make([]any, 0)
+			for _, entry := range stream.entries {
+				if read.count > 0 && len(messages) >= read.count {
+					break
+				}
+				if ids[i].less(entry.id) {
+					messages = append(messages, entry.reply())
+				}
+			}
+			if len(messages) > 0 {
+				result = append(result, []any{key, messages})
+			}
+		}
+		if len(result) == 0 {
+			return memoryRedisNilArray{}, false
+		}
+		return result, true
+	})
+}
+
+func (s *memoryRedisServer) xreadgroup(args []string, closed <-chan struct{}) any {
+	read, err := parseMemoryRedisReadArgs(args, true)
+	if err != nil {
+		return err
+	}
+	return s.wait(read.timeout(), closed, memoryRedisNilArray{}, func() (any, bool) {
+		result := make([]any, 0)
+		history := false
+		for i, key := range read.streams {
+			stream, streamGroup, err := s.getStreamGroup(key, read.group, "XREADGROUP")
+			if err != nil {
+				return err, true
+			}
+			streamGroup.consumers[read.consumer] = time.Now()
+			messages := make([]any, 0)
+			if read.ids[i] != ">" {
+				history = true
+				from, err := parseMemoryRedisStreamID(read.ids[i], false)
+				if err != nil {
+					return err, true
+				}
+				for _, pending := range streamGroup.pending {
+					if read.count > 0 && len(messages) >= read.count {
+						break
+					}
+					if pending.consumer != read.consumer || !from.less(pending.id) {
+						continue
+					}
+					entry, has := stream.find(pending.id)
+					if has {
+						messages = append(messages, entry.reply())
+					} else {
+						messages = append(messages, []any{pending.id.String(), memoryRedisNilArray{}})
+					}
+				}
+				result = append(result, []any{key, messages})
+				continue
+			}
+			now := time.Now()
+			for _, entry := range stream.entries {
+				if read.count > 0 && len(messages) >= read.count {
+					break
+				}
+				if !streamGroup.lastDelivered.less(entry.id) {
+					continue
+				}
+				messages = append(messages, entry.reply())
+				streamGroup.lastDelivered = entry.id
+				streamGroup.entriesRead++
+				if !read.noAck {
+					streamGroup.pending = append(streamGroup.pending,
+						&memoryRedisPendingEntry{id: entry.id, consumer: read.consumer, delivered: now, count: 1})
+				}
+			}
+			if len(messages) > 0 {
+				result = append(result, []any{key, messages})
+			}
+		}
+		if len(result) == 0 && !history {
+			return memoryRedisNilArray{}, false
+		}
+		return result, true
+	})
+}
+
+func (s *memoryRedisServer) xackCommand(args []string) any {
+	if len(args) < 3 {
+		return memoryRedisArgumentsError("xack")
+	}
+	stream, err := s.getStream(args[0], false)
+	if err != nil {
+		return err
+	}
+	if stream == nil || stream.groups[args[1]] == nil {
+		return int64(0)
+	}
+	streamGroup := stream.groups[args[1]]
+	acked := int64(0)
+	for _, value := range args[2:] {
+		id, err := parseMemoryRedisStreamID(value, false)
+		if err != nil {
+			return err
+		}
+		for i, pending := range streamGroup.pending {
+			if pending.id == id {
+				streamGroup.pending = append(streamGroup.pending[0:i], streamGroup.pending[i+1:]...)
+				acked++
+				break
+			}
+		}
+	}
+	return acked
+}
+
+func (s *memoryRedisServer) xpendingCommand(args []string) any {
+	if len(args) < 2 {
+		return memoryRedisArgumentsError("xpending")
+	}
+	_, streamGroup, err := s.getStreamGroup(args[0], args[1], "XPENDING")
+	if err != nil {
+		return err
+	}
+	if len(args) == 2 {
+		if len(streamGroup.pending) == 0 {
+			return []any{int64(0), nil, nil, memoryRedisNilArray{}}
+		}
+		counts := make(map[string]int64)
+		consumers := make([]string, 0)
+		for _, pending := range streamGroup.pending {
+			if counts[pending.consumer] == 0 {
+				consumers = append(consumers, pending.consumer)
+			}
+			counts[pending.consumer]++
+		}
+		rows := make([]any, len(consumers))
+		for i, consumer := range consumers {
+			rows[i] = []any{consumer, strconv.FormatInt(counts[consumer], 10)}
+		}
+		return []any{int64(len(streamGroup.pending)), streamGroup.pending[0].id.String(),
+			streamGroup.pending[len(streamGroup.pending)-1].id.String(), rows}
+	}
+	rest := args[2:]
+	minIdle := time.Duration(0)
+	if strings.ToLower(rest[0]) == "idle" {
+		if len(rest) < 2 {
+			return errMemoryRedisSyntax
+		}
+		idle, err := strconv.ParseInt(rest[1], 10, 64)
+		if err != nil {
+			return errMemoryRedisNotInteger
+		}
+		minIdle = time.Duration(idle) * time.Millisecond
+		rest = rest[2:]
+	}
+	if len(rest) < 3 {
+		return errMemoryRedisSyntax
+	}
+	start, err := parseMemoryRedisStreamRangeID(rest[0], false)
+	if err != nil {
+		return err
+	}
+	end, err := parseMemoryRedisStreamRangeID(rest[1], true)
+	if err != nil {
+		return err
+	}
+	count, err := strconv.Atoi(rest[2])
+	if err != nil {
+		return errMemoryRedisNotInteger
+	}
+	consumer := ""
+	if len(rest) > 3 {
+		consumer = rest[3]
+	}
+	result := make([]any, 0)
+	now := time.Now()
+	for _, pending := range streamGroup.pending {
+		if len(result) >= count {
+			break
+		}
+		idle := now.Sub(pending.delivered)
+		if pending.id.less(start) || end.less(pending.id) || (consumer != "" && pending.consumer != consumer) || idle < minIdle {
+			continue
+		}
+		result = append(result, []any{pending.id.String(), pending.consumer, idle.Milliseconds(), pending.count})
+	}
+	return result
+}
+
+func (s *memoryRedisServer) xclaimCommand(args []string) any {
+	if len(args) < 5 {
+		return memoryRedisArgumentsError("xclaim")
+	}
+	stream, streamGroup, err := s.getStreamGroup(args[0], args[1], "XCLAIM")
+	if err != nil {
+		return err
+	}
+	consumer := args[2]
+	minIdleMs, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil {
+		return errors.New("ERR Invalid min-idle-time argument for XCLAIM")
+	}
+	minIdle := time.Duration(minIdleMs) * time.Millisecond
+	ids := args[4:]
+	justID := false
+	if len(ids) > 0 && strings.ToLower(ids[len(ids)-1]) == "justid" {
+		justID = true
+		ids = ids[0 : len(ids)-1]
+	}
+	streamGroup.consumers[consumer] = time.Now()
+	result := make([]any, 0)
+	now := time.Now()
+	for _, value := range ids {
+		id, err := parseMemoryRedisStreamID(value, false)
+		if err != nil {
+			return err
+		}
+		for i, pending := range streamGroup.pending {
+			if pending.id != id || now.Sub(pending.delivered) < minIdle {
+				continue
+			}
+			entry, has := stream.find(id)
+			if !has {
+				streamGroup.pending = append(streamGroup.pending[0:i], streamGroup.pending[i+1:]...)
+				break
+			}
+			pending.consumer = consumer
+			pending.delivered = now
+			if justID {
+				result = append(result, id.String())
+			} else {
+				pending.count++
+				result = append(result, entry.reply())
+			}
+			break
+		}
+	}
+	return result
+}
+
+func (s *memoryRedisServer) xinfoCommand(args []string) any {
+	if len(args) < 2 {
+		return memoryRedisArgumentsError("xinfo")
+	}
+	stream, err := s.getStream(args[1], false)
+	if err != nil {
+		return err
+	}
+	if stream == nil {
+		return errors.New("ERR no such key")
+	}
+	switch strings.ToLower(args[0]) {
+	case "stream":
+		var first, last any = memoryRedisNilArray{}, memoryRedisNilArray{}
+		firstID := "0-0"
+		if len(stream.entries) > 0 {
+			first = stream.entries[0].reply()
+			last = stream.entries[len(stream.entries)-1].reply()
+			firstID = stream.entries[0].id.String()
+		}
+		return []any{
+			"length", int64(len(stream.entries)),
+			"radix-tree-keys", int64(1),
+			"radix-tree-nodes", int64(2),
+			"last-generated-id", stream.lastID.String(),
+			"max-deleted-entry-id", "0-0",
+			"entries-added", stream.added,
+			"recorded-first-entry-id", firstID,
+			"groups", int64(len(stream.groups)),
+			"first-entry", first,
+			"last-entry", last,
+		}
+	case "groups":
+		names := make([]string, 0, len(stream.groups))
+		for name := range stream.groups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		result := make([]any, len(names))
+		for i, name := range names {
+			streamGroup := stream.groups[name]
+			lag := int64(0)
+			for _, entry := range stream.entries {
+				if streamGroup.lastDelivered.less(entry.id) {
+					lag++
+				}
+			}
+			result[i] = []any{
+				"name", name,
+				"consumers", int64(len(streamGroup.consumers)),
+				"pending", int64(len(streamGroup.pending)),
+				"last-delivered-id", streamGroup.lastDelivered.String(),
+				"entries-read", streamGroup.entriesRead,
+				"lag", lag,
+			}
+		}
+		return result
+	}
+	return errMemoryRedisSyntax
+}
@@ -0,0 +1,190 @@
+package beeorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisInMemory(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterRedisInMemory(DefaultPoolCode)
+	validatedRegistry, err := registry.Validate()
+	assert.Nil(t, err)
+	orm := validatedRegistry.NewORM(context.Background())
+	r := orm.Engine().Redis(DefaultPoolCode)
+	assert.Equal(t, "memory:default", r.GetConfig().GetAddress())
+
+	testLogger := &MockLogHandler{}
+	orm.RegisterQueryLogger(testLogger, false, true, false)
+	r.FlushDB(orm)
+	assert.Len(t, testLogger.Logs, 1)
+
+	val := r.GetSet(orm, "test_get_set", time.Second*10, func() any {
+		return "ok"
+	})
+	assert.Equal(t, "ok", val)
+	_, has := r.Get(orm, "test_get")
+	assert.False(t, has)
+	r.Set(orm, "test_get", "hello", time.Second)
+	val, has = r.Get(orm, "test_get")
+	assert.True(t, has)
+	assert.Equal(t, "hello", val)
+	assert.True(t, r.SetNX(orm, "test_get_nx", "hello nx", time.Second))
+	assert.False(t, r.SetNX(orm, "test_get_nx", "hello nx", time.Second))
+	assert.Equal(t, "string", r.Type(orm, "test_get"))
+
+	r.LPush(orm, "test_list", "a")
+	r.RPush(orm, "test_list", "b", "c")
+	assert.Equal(t, int64(3), r.LLen(orm, "test_list"))
+	assert.Equal(t, []string{"b", "c"}, r.LRange(orm, "test_list", 1, 5))
+	r.LSet(orm, "test_list", 1, "d")
+	r.LRem(orm, "test_list", 1, "c")
+	assert.Equal(t, []string{"a", "d"}, r.LRange(orm, "test_list", 0, -1))
+	val, has = r.RPop(orm, "test_list")
+	assert.True(t, has)
+	assert.Equal(t, "d", val)
+	assert.Equal(t, "a", r.LMove(orm, "test_list", "test_list_next", "RIGHT", "LEFT"))
+	assert.Equal(t, int64(0), r.Exists(orm, "test_list"))
+	assert.Equal(t, "a", r.BLMove(orm, "test_list_next", "test_list", "RIGHT", "LEFT", time.Second))
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		r.RPush(validatedRegistry.NewORM(context.Background()), "test_list_blocked", "b")
+	}()
+	assert.Equal(t, "b", r.BLMove(orm, "test_list_blocked", "test_list", "LEFT", "RIGHT", time.Second))
+
+	r.HSet(orm, "test_map", "name", "Tom", "last", "Summer", "age", "16")
+	assert.Equal(t, map[string]any{"age": "16", "missing": nil, "name": "Tom"}, r.HMGet(orm, "test_map", "name", "age", "missing"))
+	r.HDel(orm, "test_map", "age")
+	assert.Equal(t, map[string]string{"last": "Summer", "name": "Tom"}, r.HGetAll(orm, "test_map"))
+	assert.True(t, r.HSetNx(orm, "test_map", "key", "value"))
+	assert.False(t, r.HSetNx(orm, "test_map", "key", "value"))
+	assert.Equal(t, int64(5), r.HIncrBy(orm, "test_inc", "a", 5))
+	assert.Equal(t, int64(3), r.IncrBy(orm, "test_inc_2", 3))
+	assert.Equal(t, int64(4), r.Incr(orm, "test_inc_2"))
+	assert.Equal(t, int64(1), r.IncrWithExpire(orm, "test_inc_exp", time.Second))
+	r.Set(orm, "test_expire", "value", time.Millisecond*100)
+	assert.True(t, r.Expire(orm, "test_map", time.Second))
+	assert.Panics(t, func() {
+		r.Incr(orm, "test_map")
+	})
+
+	assert.Equal(t, int64(2), r.ZAdd(orm, "test_z", redis.Z{Member: "a", Score: 10}, redis.Z{Member: "b", Score: 20}))
+	assert.Equal(t, []string{"b", "a"}, r.ZRevRange(orm, "test_z", 0, 3))
+	assert.Equal(t, float64(10), r.ZScore(orm, "test_z", "a"))
+	assert.Equal(t, []redis.Z{{Member: "a", Score: 10}, {Member: "b", Score: 20}}, r.ZRangeWithScores(orm, "test_z", 0, 3))
+	assert.Equal(t, []redis.Z{{Member: "b", Score: 20}, {Member: "a", Score: 10}}, r.ZRevRangeWithScores(orm, "test_z", 0, 3))
+	assert.Equal(t, int64(2), r.ZCard(orm, "test_z"))
+	assert.Equal(t, int64(1), r.ZCount(orm, "test_z", "(10", "+inf"))
+
+	r.MSet(orm, "key_1", "a", "key_2", "b")
+	assert.Equal(t, []any{"a", "b", nil}, r.MGet(orm, "key_1", "key_2", "missing"))
+	assert.Equal(t, int64(4), r.SAdd(orm, "test_s", "a", "b", "c", "d", "a"))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, r.SMembers(orm, "test_s"))
+	assert.True(t, r.SIsMember(orm, "test_s", "a"))
+	_, has = r.SPop(orm, "test_s")
+	assert.True(t, has)
+	assert.Len(t, r.SPopN(orm, "test_s", 10), 3)
+	assert.Equal(t, int64(0), r.SCard(orm, "test_s"))
+	assert.Contains(t, r.Info(orm), "redis_version")
+
+	pipeLine := orm.RedisPipeLine(DefaultPoolCode)
+	pipeLine.Set("pipeline_key", "value", time.Minute)
+	pipeLine.HSet("pipeline_hash", "a", "b")
+	get := pipeLine.Get("pipeline_key")
+	missing := pipeLine.Get("pipeline_missing")
+	pipeLine.Exec(orm)
+	val, has = get.Result()
+	assert.True(t, has)
+	assert.Equal(t, "value", val)
+	_, has = missing.Result()
+	assert.False(t, has)
+
+	time.Sleep(time.Millisecond * 150)
+	assert.Equal(t, int64(0), r.Exists(orm, "test_expire"))
+	assert.Panics(t, func() {
+		r.Eval(orm, "return 1", nil)
+	})
+}
+
+func TestRedisInMemoryStreams(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterRedisInMemory(DefaultPoolCode)
+	validatedRegistry, err := registry.Validate()
+	assert.Nil(t, err)
+	orm := validatedRegistry.NewORM(context.Background())
+	r := orm.Engine().Redis(DefaultPoolCode)
+
+	_, exists := r.XGroupCreateMkStream(orm, "test_stream", "group", "0")
+	assert.False(t, exists)
+	_, exists = r.XGroupCreate(orm, "test_stream", "group", "0")
+	assert.True(t, exists)
+	pipeLine := orm.RedisPipeLine(DefaultPoolCode)
+	first := pipeLine.XAdd("test_stream", []string{"name", "a"})
+	pipeLine.XAdd("test_stream", []string{"name", "b"})
+	pipeLine.Exec(orm)
+	assert.Equal(t, int64(2), r.XLen(orm, "test_stream"))
+	messages := r.XRange(orm, "test_stream", "-", "+", 10)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, first.Result(), messages[0].ID)
+	assert.Equal(t, map[string]any{"name": "a"}, messages[0].Values)
+	assert.Equal(t, "b", r.XRevRange(orm, "test_stream", "+", "-", 1)[0].Values["name"])
+
+	streams := r.XReadGroup(orm, &redis.XReadGroupArgs{Group: "group", Consumer: "consumer", Streams: []string{"test_stream", ">"}, Count: 10, Block: -1})
+	assert.Len(t, streams, 1)
+	assert.Len(t, streams[0].Messages, 2)
+	assert.Len(t, r.XReadGroup(orm, &redis.XReadGroupArgs{Group: "group", Consumer: "consumer", Streams: []string{"test_stream", ">"}, Block: -1}), 0)
+	pending := r.XPending(orm, "test_stream", "group")
+	assert.Equal(t, int64(2), pending.Count)
+	assert.Equal(t, int64(2), pending.Consumers["consumer"])
+	assert.Len(t, r.XPendingExt(orm, &redis.XPendingExtArgs{Stream: "test_stream", Group: "group", Start: "-", End: "+", Count: 10}), 2)
+	claimed := r.XClaim(orm, &redis.XClaimArgs{Stream: "test_stream", Group: "group", Consumer: "other", Messages: []string{first.Result()}})
+	assert.Len(t, claimed, 1)
+	assert.Equal(t, []string{first.Result()}, r.XClaimJustID(orm, &redis.XClaimArgs{Stream: "test_stream", Group: "group", Consumer: "other", Messages: []string{first.Result()}}))
+	assert.Equal(t, int64(2), r.XAck(orm, "test_stream", "group", messages[0].ID, messages[1].ID))
+	assert.Equal(t, int64(0), r.XPending(orm, "test_stream", "group").Count)
+
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		r.Process(validatedRegistry.NewORM(context.Background()), redis.NewStringCmd(context.Background(), "xadd", "test_stream", "*", "name", "c"))
+	}()
+	streams = r.XReadGroup(orm, &redis.XReadGroupArgs{Group: "group", Consumer: "consumer", Streams: []string{"test_stream", ">"}, Block: time.Second})
+	assert.Len(t, streams, 1)
+	assert.Equal(t, "c", streams[0].Messages[0].Values["name"])
+
+	info := r.XInfoStream(orm, "test_stream")
+	assert.Equal(t, int64(3), info.Length)
+	assert.Equal(t, int64(1), info.Groups)
+	groups := r.XInfoGroups(orm, "test_stream")
+	assert.Len(t, groups, 1)
+	assert.Equal(t, int64(1), groups[0].Pending)
+	assert.Equal(t, int64(2), r.XGroupDelConsumer(orm, "test_stream", "group", "consumer")-r.XGroupDelConsumer(orm, "test_stream", "group", "other")+1)
+	assert.Equal(t, int64(1), r.XDel(orm, "test_stream", first.Result()))
+	assert.Equal(t, int64(1), r.XTrim(orm, "test_stream", 1))
+	assert.Len(t, r.XRead(orm, &redis.XReadArgs{Streams: []string{"test_stream", "0"}, Block: -1}), 1)
+	assert.Equal(t, int64(1), r.XGroupDestroy(orm, "test_stream", "group"))
+}
+
+func TestRedisInMemoryLocker(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterRedisInMemory(DefaultPoolCode)
+	validatedRegistry, err := registry.Validate()
+	assert.Nil(t, err)
+	orm := validatedRegistry.NewORM(context.Background())
+
+	l := orm.Engine().Redis(DefaultPoolCode).GetLocker()
+	lock, has := l.Obtain(orm, "test_key", time.Second, 0)
+	assert.True(t, has)
+	assert.True(t, lock.Refresh(orm, time.Second))
+	_, has = l.Obtain(orm, "test_key", time.Second, time.Millisecond*100)
+	assert.False(t, has)
+	assert.LessOrEqual(t, lock.TTL(orm).Microseconds(), time.Second.Microseconds())
+	lock.Release(orm)
+	lock, has = l.Obtain(orm, "test_key", time.Second*10, time.Second*10)
+	assert.True(t, has)
+	lock.Release(orm)
+	assert.False(t, lock.Refresh(orm, time.Second))
+}
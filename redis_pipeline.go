@@ -156,6 +156,15 @@ func (rp *RedisPipeLine) XAdd(stream string, values []string) *PipeLineString {
 	return &PipeLineString{p: rp, cmd: rp.pipeLine.XAdd(rp.orm.Context(), &redis.XAddArgs{Stream: stream, Values: values})}
 }
 
+func (rp *RedisPipeLine) Publish(channel string, message any) {
+	rp.commands++
+	hasLog, _ := rp.orm.getRedisLoggers()
+	if hasLog {
+		rp.log = append(rp.log, fmt.Sprintf("PUBLISH %s %v", channel, message))
+	}
+	rp.pipeLine.Publish(rp.orm.Context(), channel, message)
+}
+
 func (rp *RedisPipeLine) Exec(orm ORM) {
 	if rp.commands == 0 {
 		return
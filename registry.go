@@ -22,19 +22,28 @@ type Registry interface {
 	RegisterEntity(entity ...any)
 	RegisterPlugin(plugin ...any)
 	RegisterMySQL(dataSourceName string, poolCode string, poolOptions *MySQLOptions)
+	RegisterPostgreSQL(dataSourceName string, poolCode string, poolOptions *MySQLOptions)
+	RegisterSQLite(path string, poolCode string)
 	RegisterLocalCache(code string, limit int)
 	RegisterRedis(address string, db int, poolCode string, options *RedisOptions)
+	RegisterRedisInMemory(poolCode string)
 	InitByYaml(yaml map[string]any) error
 	SetOption(key string, value any)
+	SetAsyncRetryPolicy(policy *AsyncRetryPolicy)
+	EnableAsyncFlushStreams()
+	SetAsyncBufferOptions(options *AsyncBufferOptions)
 }
 
 type registry struct {
-	mysqlPools  map[string]MySQLConfig
-	localCaches map[string]LocalCache
-	redisPools  map[string]RedisPoolConfig
-	entities    map[string]reflect.Type
-	plugins     []any
-	options     map[string]any
+	mysqlPools       map[string]MySQLConfig
+	localCaches      map[string]LocalCache
+	redisPools       map[string]RedisPoolConfig
+	entities         map[string]reflect.Type
+	plugins          []any
+	options          map[string]any
+	asyncRetryPolicy *AsyncRetryPolicy
+	asyncStreams     bool
+	asyncBuffer      *AsyncBufferOptions
 }
 
 func NewRegistry() Registry {
@@ -47,6 +56,19 @@ func (r *registry) Validate() (Engine, error) {
 	e.registry = &engineRegistryImplementation{engine: e}
 	e.registry.options = make(map[string]any)
 	e.registry.asyncConsumerBlockTime = asyncConsumerBlockTime
+	e.registry.asyncRetryPolicy = r.asyncRetryPolicy
+	if e.registry.asyncRetryPolicy == nil {
+		e.registry.asyncRetryPolicy = newDefaultAsyncRetryPolicy()
+	}
+	e.registry.asyncConsumerMetrics = &asyncConsumerMetrics{}
+	e.registry.asyncFlushStreams = r.asyncStreams
+	e.registry.asyncBufferOptions = &AsyncBufferOptions{Size: asyncBufferDefaultSize}
+	if r.asyncBuffer != nil {
+		*e.registry.asyncBufferOptions = *r.asyncBuffer
+		if e.registry.asyncBufferOptions.Size <= 0 {
+			e.registry.asyncBufferOptions.Size = asyncBufferDefaultSize
+		}
+	}
 	l := len(r.entities)
 	e.registry.entitySchemas = make(map[reflect.Type]*entitySchema, l)
 	e.registry.entitySchemasQuickMap = make(map[reflect.Type]*entitySchema, l)
@@ -54,6 +76,7 @@ func (r *registry) Validate() (Engine, error) {
 	e.registry.entityLogSchemas = make(map[reflect.Type]*entitySchema, l)
 	e.registry.entities = make(map[string]reflect.Type)
 	e.registry.enums = make(map[string][]string)
+	e.registry.localCacheSchemas = make(map[string]*entitySchema)
 	e.options = make(map[string]any)
 	if e.dbServers == nil {
 		e.dbServers = make(map[string]DB)
@@ -63,19 +86,12 @@ func (r *registry) Validate() (Engine, error) {
 		if len(k) > maxPoolLen {
 			maxPoolLen = len(k)
 		}
-		db, err := sql.Open("mysql", v.GetDataSourceURI())
+		db, err := sql.Open(v.getDialect().driverName(v.GetOptions()), v.GetDataSourceURI())
 		if err != nil {
 			return nil, err
 		}
 
-		var maxConnections int
-		var skip string
-		err = db.QueryRow("SHOW VARIABLES LIKE 'max_connections'").Scan(&skip, &maxConnections)
-		if err != nil {
-			return nil, err
-		}
-		var waitTimeout int
-		err = db.QueryRow("SHOW VARIABLES LIKE 'wait_timeout'").Scan(&skip, &waitTimeout)
+		maxConnections, waitTimeout, err := v.getDialect().loadServerLimits(db)
 		if err != nil {
 			return nil, err
 		}
@@ -115,7 +131,16 @@ func (r *registry) Validate() (Engine, error) {
 			}
 		}
 		v.(*mySQLConfig).client = db
-		e.dbServers[k] = &dbImplementation{config: v, client: &standardSQLClient{db: v.getClient()}}
+		dbServer := &dbImplementation{config: v, client: &standardSQLClient{db: v.getClient()}}
+		if len(options.Replicas) > 0 {
+			dbServer.replicas, err = newDBReplicas(v, maxLimit, maxIdle, maxDuration)
+			if err != nil {
+				e.Close()
+				return nil, err
+			}
+			e.closers = append(e.closers, dbServer.replicas.close)
+		}
+		e.dbServers[k] = dbServer
 	}
 	if e.localCacheServers == nil {
 		e.localCacheServers = make(map[string]LocalCache)
@@ -171,6 +196,7 @@ func (r *registry) Validate() (Engine, error) {
 	for _, schema := range e.registry.entitySchemas {
 		if schema.hasLocalCache {
 			schema.localCache = e.localCacheServers[schema.cacheKey].(*localCache)
+			e.registry.localCacheSchemas[schema.cacheKey] = schema
 		}
 		if schema.hasRedisCache {
 			schema.redisCache = e.redisServers[schema.redisCacheName].(*redisCache)
@@ -183,6 +209,17 @@ func (r *registry) Validate() (Engine, error) {
 			return nil, errors.Wrapf(err, "invalid entity struct '%s'", schema.t.String())
 		}
 		schema.engine = e
+		schema.initShards()
+	}
+	for _, schema := range e.registry.entitySchemas {
+		err := schema.initForeignKeys(e.registry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid entity struct '%s'", schema.t.String())
+		}
+		err = schema.initOnDeleteReferences(e.registry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid entity struct '%s'", schema.t.String())
+		}
 	}
 	for _, plugin := range r.plugins {
 		pluginInterfaceValidateRegistry, isInterface := plugin.(PluginInterfaceValidateRegistry)
@@ -200,6 +237,11 @@ func (r *registry) Validate() (Engine, error) {
 	for key, value := range r.options {
 		e.registry.options[key] = value
 	}
+	err := e.subscribeLocalCacheInvalidation()
+	if err != nil {
+		e.Close()
+		return nil, err
+	}
 	return e, nil
 }
 
@@ -211,6 +253,18 @@ func (r *registry) SetOption(key string, value any) {
 	r.options[key] = value
 }
 
+func (r *registry) SetAsyncRetryPolicy(policy *AsyncRetryPolicy) {
+	r.asyncRetryPolicy = policy
+}
+
+func (r *registry) EnableAsyncFlushStreams() {
+	r.asyncStreams = true
+}
+
+func (r *registry) SetAsyncBufferOptions(options *AsyncBufferOptions) {
+	r.asyncBuffer = options
+}
+
 func (r *registry) RegisterEntity(entity ...any) {
 	if r.entities == nil {
 		r.entities = make(map[string]reflect.Type)
@@ -239,16 +293,20 @@ func (r *registry) RegisterPlugin(plugin ...any) {
 }
 
 type MySQLOptions struct {
-	ConnMaxLifetime    time.Duration
-	MaxOpenConnections int
-	MaxIdleConnections int
-	DefaultEncoding    string
-	DefaultCollate     string
-	IgnoredTables      []string
+	ConnMaxLifetime            time.Duration
+	MaxOpenConnections         int
+	MaxIdleConnections         int
+	DefaultEncoding            string
+	DefaultCollate             string
+	IgnoredTables              []string
+	Replicas                   []string
+	ReplicaStickiness          time.Duration
+	ReplicaHealthCheckInterval time.Duration
+	Driver                     string
 }
 
 func (r *registry) RegisterMySQL(dataSourceName string, poolCode string, poolOptions *MySQLOptions) {
-	db := &mySQLConfig{code: poolCode, dataSourceName: dataSourceName, options: poolOptions}
+	db := &mySQLConfig{code: poolCode, dataSourceName: dataSourceName, options: poolOptions, dialect: &mysqlDialect{}}
 	if r.mysqlPools == nil {
 		r.mysqlPools = make(map[string]MySQLConfig)
 	}
@@ -258,6 +316,24 @@ func (r *registry) RegisterMySQL(dataSourceName string, poolCode string, poolOpt
 	r.mysqlPools[poolCode] = db
 }
 
+func (r *registry) RegisterPostgreSQL(dataSourceName string, poolCode string, poolOptions *MySQLOptions) {
+	db := &mySQLConfig{code: poolCode, dataSourceName: dataSourceName, options: poolOptions, dialect: &postgreSQLDialect{}}
+	if r.mysqlPools == nil {
+		r.mysqlPools = make(map[string]MySQLConfig)
+	}
+	db.databaseName = getPostgreSQLDatabaseName(dataSourceName)
+	r.mysqlPools[poolCode] = db
+}
+
+func (r *registry) RegisterSQLite(path string, poolCode string) {
+	db := &mySQLConfig{code: poolCode, dataSourceName: path, options: &MySQLOptions{}, dialect: &sqliteDialect{}}
+	if r.mysqlPools == nil {
+		r.mysqlPools = make(map[string]MySQLConfig)
+	}
+	db.databaseName = sqliteDatabaseName
+	r.mysqlPools[poolCode] = db
+}
+
 func (r *registry) RegisterLocalCache(code string, limit int) {
 	if r.localCaches == nil {
 		r.localCaches = make(map[string]LocalCache)
@@ -266,11 +342,12 @@ func (r *registry) RegisterLocalCache(code string, limit int) {
 }
 
 type RedisOptions struct {
-	User            string
-	Password        string
-	Master          string
-	Sentinels       []string
-	SentinelOptions *redis.FailoverOptions
+	User                   string
+	Password               string
+	Master                 string
+	Sentinels              []string
+	SentinelOptions        *redis.FailoverOptions
+	LocalCacheInvalidation bool
 }
 
 func (r *registry) RegisterRedis(address string, db int, poolCode string, options *RedisOptions) {
@@ -287,7 +364,7 @@ func (r *registry) RegisterRedis(address string, db int, poolCode string, option
 			}
 		}
 		client := redis.NewFailoverClient(sentinelOptions)
-		r.registerRedis(client, poolCode, fmt.Sprintf("%v", options.Sentinels), db)
+		r.registerRedis(client, poolCode, fmt.Sprintf("%v", options.Sentinels), db, options.LocalCacheInvalidation)
 		return
 	}
 	redisOptions := &redis.Options{
@@ -303,11 +380,24 @@ func (r *registry) RegisterRedis(address string, db int, poolCode string, option
 		redisOptions.Network = "unix"
 	}
 	client := redis.NewClient(redisOptions)
-	r.registerRedis(client, poolCode, address, db)
+	r.registerRedis(client, poolCode, address, db, options != nil && options.LocalCacheInvalidation)
+}
+
+func (r *registry) RegisterRedisInMemory(poolCode string) {
+	address := "memory:" + poolCode
+	server := newMemoryRedisServer(address)
+	client := redis.NewClient(&redis.Options{
+		Addr:             address,
+		Dialer:           server.dial,
+		Protocol:         2,
+		DisableIndentity: true,
+		ConnMaxIdleTime:  time.Minute * 2,
+	})
+	r.registerRedis(client, poolCode, address, 0, false)
 }
 
-func (r *registry) registerRedis(client *redis.Client, code string, address string, db int) {
-	redisPool := &redisCacheConfig{code: code, client: client, address: address, db: db}
+func (r *registry) registerRedis(client *redis.Client, code string, address string, db int, localCacheInvalidation bool) {
+	redisPool := &redisCacheConfig{code: code, client: client, address: address, db: db, localCacheInvalidation: localCacheInvalidation}
 	if r.redisPools == nil {
 		r.redisPools = make(map[string]RedisPoolConfig)
 	}
@@ -318,14 +408,16 @@ type RedisPoolConfig interface {
 	GetCode() string
 	GetDatabaseNumber() int
 	GetAddress() string
+	HasLocalCacheInvalidation() bool
 	getClient() *redis.Client
 }
 
 type redisCacheConfig struct {
-	code    string
-	client  *redis.Client
-	db      int
-	address string
+	code                   string
+	client                 *redis.Client
+	db                     int
+	address                string
+	localCacheInvalidation bool
 }
 
 func (p *redisCacheConfig) GetCode() string {
@@ -340,6 +432,10 @@ func (p *redisCacheConfig) GetAddress() string {
 	return p.address
 }
 
+func (p *redisCacheConfig) HasLocalCacheInvalidation() bool {
+	return p.localCacheInvalidation
+}
+
 func (p *redisCacheConfig) getClient() *redis.Client {
 	return p.client
 }
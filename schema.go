@@ -12,9 +12,12 @@ import (
 )
 
 type Alter struct {
-	SQL  string
-	Safe bool
-	Pool string
+	SQL           string
+	Down          string
+	Safe          bool
+	Pool          string
+	EstimatedRows uint64
+	online        *onlineAlter
 }
 
 type TableSQLSchemaDefinition struct {
@@ -113,7 +116,7 @@ func getAlters(orm ORM) (preAlters, alters, postAlters []Alter) {
 
 	for poolName, pool := range orm.Engine().Registry().DBPools() {
 		tablesInDB[poolName] = make(map[string]bool)
-		tables := getAllTables(pool.GetDBClient())
+		tables := pool.GetConfig().getDialect().getAllTables(pool)
 		for _, table := range tables {
 			tablesInDB[poolName][table] = true
 		}
@@ -121,24 +124,35 @@ func getAlters(orm ORM) (preAlters, alters, postAlters []Alter) {
 	}
 	alters = make([]Alter, 0)
 	for _, schemaInterface := range orm.Engine().Registry().Entities() {
-		schema := schemaInterface.(*entitySchema)
-		db := schema.GetDB()
-		tablesInEntities[db.GetConfig().GetCode()][schema.GetTableName()] = true
-		pre, middle, post := getSchemaChanges(orm, schema)
-		preAlters = append(preAlters, pre...)
-		alters = append(alters, middle...)
-		postAlters = append(postAlters, post...)
+		for _, schema := range schemaInterface.(*entitySchema).getShards() {
+			db := schema.GetDB()
+			tablesInEntities[db.GetConfig().GetCode()][schema.GetTableName()] = true
+			if schema.tableRenamedFrom != "" {
+				tablesInEntities[db.GetConfig().GetCode()][schema.tableRenamedFrom] = true
+			}
+			pre, middle, post := getSchemaChanges(orm, schema)
+			preAlters = append(preAlters, pre...)
+			alters = append(alters, middle...)
+			postAlters = append(postAlters, post...)
+		}
 	}
 	for poolName, tables := range tablesInDB {
 		for tableName := range tables {
 			_, has := tablesInEntities[poolName][tableName]
-			if !has {
+			if !has && tableName != migrationsTableName {
 				_, has = orm.Engine().Registry().getDBTables()[poolName][tableName]
 				if !has {
-					pool := orm.Engine().DB(poolName)
-					dropSQL := fmt.Sprintf("DROP TABLE IF EXISTS `%s`.`%s`;", pool.GetConfig().GetDatabaseName(), tableName)
+					pool := orm.Engine().DB(poolName).Primary()
+					dropSQL := fmt.Sprintf("DROP TABLE IF EXISTS %s;", pool.GetConfig().getDialect().tableIdentifier(pool.GetConfig(), tableName))
 					isEmpty := isTableEmptyInPool(orm, poolName, tableName)
-					alters = append(alters, Alter{SQL: dropSQL, Safe: isEmpty, Pool: poolName})
+					downSQL := pool.GetConfig().getDialect().createTableSQL(orm, pool, tableName)
+					alters = append(alters, Alter{SQL: dropSQL, Down: downSQL, Safe: isEmpty, Pool: poolName})
+					if _, isMySQL := pool.GetConfig().getDialect().(*mysqlDialect); isMySQL {
+						for name := range getMySQLDBForeignKeys(orm, pool, tableName) {
+							preAlters = append(preAlters, Alter{SQL: fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY `%s`;",
+								pool.GetConfig().getDialect().tableIdentifier(pool.GetConfig(), tableName), name), Safe: true, Pool: poolName})
+						}
+					}
 				}
 			}
 		}
@@ -150,39 +164,39 @@ func getAlters(orm ORM) (preAlters, alters, postAlters []Alter) {
 }
 
 func isTableEmptyInPool(orm ORM, poolName string, tableName string) bool {
-	return isTableEmpty(orm.Engine().DB(poolName).GetDBClient(), tableName)
+	return isTableEmpty(orm.Engine().DB(poolName), tableName)
 }
 
-func getAllTables(db DBClient) []string {
-	tables := make([]string, 0)
-	results, err := db.Query("SHOW FULL TABLES WHERE Table_Type = 'BASE TABLE'")
-	checkError(err)
-	defer func() {
-		_ = results.Close()
-	}()
-	var skip string
-	for results.Next() {
-		var row string
-		err = results.Scan(&row, &skip)
-		checkError(err)
-		tables = append(tables, row)
-	}
-	err = results.Err()
-	checkError(err)
-	return tables
+func getSchemaChanges(orm ORM, entitySchema *entitySchema) (preAlters, alters, postAlters []Alter) {
+	return entitySchema.GetDB().GetConfig().getDialect().getSchemaChanges(orm, entitySchema)
 }
 
-func getSchemaChanges(orm ORM, entitySchema *entitySchema) (preAlters, alters, postAlters []Alter) {
+func getMySQLSchemaChanges(orm ORM, entitySchema *entitySchema) (preAlters, alters, postAlters []Alter) {
 	indexes := make(map[string]*IndexSchemaDefinition)
 	columns, err := checkStruct(orm.Engine(), entitySchema, entitySchema.GetType(), indexes, nil, "", -1)
 	checkError(err)
+	addForeignKeyIndexes(entitySchema, indexes)
 	indexesSlice := make([]*IndexSchemaDefinition, 0)
 	for _, index := range indexes {
 		indexesSlice = append(indexesSlice, index)
 	}
-	pool := entitySchema.GetDB()
+	pool := entitySchema.GetDB().Primary()
+	poolCode := pool.GetConfig().GetCode()
+	databaseName := pool.GetConfig().GetDatabaseName()
+	tableName := entitySchema.GetTableName()
+	dbTableName := tableName
 	var skip string
-	hasTable := pool.QueryRow(orm, NewWhere(fmt.Sprintf("SHOW TABLES LIKE '%s'", entitySchema.GetTableName())), &skip)
+	hasTable := pool.QueryRow(orm, NewWhere(fmt.Sprintf("SHOW TABLES LIKE '%s'", tableName)), &skip)
+	if !hasTable && entitySchema.tableRenamedFrom != "" {
+		hasTable = pool.QueryRow(orm, NewWhere(fmt.Sprintf("SHOW TABLES LIKE '%s'", entitySchema.tableRenamedFrom)), &skip)
+		if hasTable {
+			dbTableName = entitySchema.tableRenamedFrom
+			preAlters = append(preAlters, Alter{
+				SQL:  fmt.Sprintf("RENAME TABLE `%s`.`%s` TO `%s`.`%s`;", databaseName, dbTableName, databaseName, tableName),
+				Down: fmt.Sprintf("RENAME TABLE `%s`.`%s` TO `%s`.`%s`;", databaseName, tableName, databaseName, dbTableName),
+				Safe: true, Pool: poolCode})
+		}
+	}
 	sqlSchema := &TableSQLSchemaDefinition{
 		orm:           orm,
 		EntitySchema:  entitySchema,
@@ -191,7 +205,7 @@ func getSchemaChanges(orm ORM, entitySchema *entitySchema) (preAlters, alters, p
 		EntityColumns: columns}
 	if hasTable {
 		sqlSchema.DBTableColumns = make([]*ColumnSchemaDefinition, 0)
-		pool.QueryRow(orm, NewWhere(fmt.Sprintf("SHOW CREATE TABLE `%s`", entitySchema.GetTableName())), &skip, &sqlSchema.DBCreateSchema)
+		pool.QueryRow(orm, NewWhere(fmt.Sprintf("SHOW CREATE TABLE `%s`", dbTableName)), &skip, &sqlSchema.DBCreateSchema)
 		lines := strings.Split(sqlSchema.DBCreateSchema, "\n")
 		for x := 1; x < len(lines); x++ {
 			if lines[x][2] != 96 {
@@ -212,7 +226,7 @@ func getSchemaChanges(orm ORM, entitySchema *entitySchema) (preAlters, alters, p
 
 		var rows []indexDB
 		/* #nosec */
-		results, def := pool.Query(orm, fmt.Sprintf("SHOW INDEXES FROM `%s`", entitySchema.GetTableName()))
+		results, def := pool.Query(orm, fmt.Sprintf("SHOW INDEXES FROM `%s`", dbTableName))
 		defer def()
 		for results.Next() {
 			var row indexDB
@@ -234,12 +248,34 @@ func getSchemaChanges(orm ORM, entitySchema *entitySchema) (preAlters, alters, p
 				sqlSchema.DBIndexes = append(sqlSchema.DBIndexes, current)
 			}
 		}
+		renamedTo := entitySchema.getColumnsRenamedTo()
+		for _, column := range sqlSchema.DBTableColumns {
+			newName, isRenamed := renamedTo[column.ColumnName]
+			if !isRenamed || hasColumnSchemaDefinition(sqlSchema.DBTableColumns, newName) {
+				continue
+			}
+			oldName := column.ColumnName
+			preAlters = append(preAlters, Alter{
+				SQL:  fmt.Sprintf("ALTER TABLE `%s`.`%s` RENAME COLUMN `%s` TO `%s`;", databaseName, tableName, oldName, newName),
+				Down: fmt.Sprintf("ALTER TABLE `%s`.`%s` RENAME COLUMN `%s` TO `%s`;", databaseName, tableName, newName, oldName),
+				Safe: true, Pool: poolCode})
+			column.ColumnName = newName
+			column.Definition = "`" + newName + "`" + strings.TrimPrefix(column.Definition, "`"+oldName+"`")
+			for _, index := range sqlSchema.DBIndexes {
+				for seq, indexColumn := range index.columnsMap {
+					if indexColumn == oldName {
+						index.columnsMap[seq] = newName
+					}
+				}
+			}
+		}
 	}
 	if sqlSchema.PreAlters != nil {
 		preAlters = append(preAlters, sqlSchema.PreAlters...)
 	}
 	if !hasTable {
-		alters = append(alters, Alter{SQL: sqlSchema.CreateTableSQL(), Safe: true, Pool: entitySchema.GetDB().GetConfig().GetCode()})
+		downSQL := fmt.Sprintf("DROP TABLE IF EXISTS `%s`.`%s`;", pool.GetConfig().GetDatabaseName(), entitySchema.GetTableName())
+		alters = append(alters, Alter{SQL: sqlSchema.CreateTableSQL(), Down: downSQL, Safe: true, Pool: entitySchema.GetDB().GetConfig().GetCode()})
 		if sqlSchema.PostAlters != nil {
 			postAlters = append(postAlters, sqlSchema.PostAlters...)
 		}
@@ -252,6 +288,15 @@ func getSchemaChanges(orm ORM, entitySchema *entitySchema) (preAlters, alters, p
 
 	var newColumns []string
 	var changedColumns [][2]string
+	var downColumns []string
+	var downDroppedIndexes []string
+	var downIndexes []string
+	dbColumnPosition := func(key int) string {
+		if key == 0 {
+			return " FIRST"
+		}
+		return fmt.Sprintf(" AFTER `%s`", sqlSchema.DBTableColumns[key-1].ColumnName)
+	}
 
 	for key, value := range columns {
 		var tableColumn string
@@ -277,8 +322,11 @@ func getSchemaChanges(orm ORM, entitySchema *entitySchema) (preAlters, alters, p
 				alter += fmt.Sprintf(" AFTER `%s`", columns[key-1].ColumnName)
 			}
 			newColumns = append(newColumns, alter)
+			downColumns = append(downColumns, fmt.Sprintf("DROP COLUMN `%s`", value.ColumnName))
 			hasAlters = true
 		} else {
+			downColumns = append(downColumns, fmt.Sprintf("CHANGE COLUMN `%s` %s%s", value.ColumnName,
+				sqlSchema.DBTableColumns[hasName].Definition, dbColumnPosition(hasName)))
 			if hasDefinition == -1 {
 				alter := fmt.Sprintf("CHANGE COLUMN `%s` %s", value.ColumnName, value.Definition)
 				if key > 0 {
@@ -300,13 +348,14 @@ func getSchemaChanges(orm ORM, entitySchema *entitySchema) (preAlters, alters, p
 	}
 	droppedColumns := make([]string, 0)
 OUTER:
-	for _, value := range sqlSchema.DBTableColumns {
+	for key, value := range sqlSchema.DBTableColumns {
 		for _, v := range columns {
 			if v.ColumnName == value.ColumnName {
 				continue OUTER
 			}
 		}
 		droppedColumns = append(droppedColumns, fmt.Sprintf("DROP COLUMN `%s`", value.ColumnName))
+		downColumns = append(downColumns, fmt.Sprintf("ADD COLUMN %s%s", value.Definition, dbColumnPosition(key)))
 		hasAlters = true
 	}
 
@@ -322,6 +371,8 @@ OUTER:
 				if addIndexSQLEntity != addIndexSQLDB {
 					droppedIndexes = append(droppedIndexes, fmt.Sprintf("DROP INDEX `%s`", indexEntity.Name))
 					newIndexes = append(newIndexes, addIndexSQLEntity)
+					downDroppedIndexes = append(downDroppedIndexes, fmt.Sprintf("DROP INDEX `%s`", indexEntity.Name))
+					downIndexes = append(downIndexes, addIndexSQLDB)
 					hasAlters = true
 				}
 				break
@@ -329,6 +380,7 @@ OUTER:
 		}
 		if !hasIndex {
 			newIndexes = append(newIndexes, buildCreateIndexSQL(indexEntity))
+			downDroppedIndexes = append(downDroppedIndexes, fmt.Sprintf("DROP INDEX `%s`", indexEntity.Name))
 			hasAlters = true
 		}
 	}
@@ -346,6 +398,7 @@ OUTER:
 		}
 		if !hasIndex {
 			droppedIndexes = append(droppedIndexes, fmt.Sprintf("DROP INDEX `%s`", key.Name))
+			downIndexes = append(downIndexes, buildCreateIndexSQL(key))
 			hasAlters = true
 		}
 	}
@@ -408,11 +461,20 @@ OUTER:
 		if len(droppedColumns) == 0 && len(changedColumns) == 0 {
 			safe = true
 		} else {
-			db := entitySchema.GetDB()
-			isEmpty := isTableEmpty(db.GetDBClient(), entitySchema.GetTableName())
-			safe = isEmpty
-		}
-		alters = append(alters, Alter{SQL: alterSQL, Safe: safe, Pool: entitySchema.GetDB().GetConfig().GetCode()})
+			safe = isTableEmpty(pool, dbTableName)
+		}
+		sort.Strings(downDroppedIndexes)
+		sort.Strings(downIndexes)
+		downClauses := append(downDroppedIndexes, downColumns...)
+		downClauses = append(downClauses, downIndexes...)
+		downSQL := fmt.Sprintf("ALTER TABLE `%s`.`%s`\n    %s;", pool.GetConfig().GetDatabaseName(),
+			entitySchema.GetTableName(), strings.Join(downClauses, ",\n    "))
+		alter := Alter{SQL: alterSQL, Down: downSQL, Safe: safe, Pool: entitySchema.GetDB().GetConfig().GetCode()}
+		alter.EstimatedRows = getMySQLEstimatedRows(orm, pool, dbTableName)
+		if !entitySchema.archived && len(entitySchema.foreignKeyChildren) == 0 {
+			alter.online = newOnlineAlter(sqlSchema)
+		}
+		alters = append(alters, alter)
 	} else if hasAlterEngineCharset || hasAlterEngine {
 		collate := " COLLATE=" + pool.GetConfig().GetOptions().DefaultEncoding + "_" + pool.GetConfig().GetOptions().DefaultCollate
 		alterSQL += " ENGINE="
@@ -422,7 +484,10 @@ OUTER:
 			alterSQL += "InnoDB"
 		}
 		alterSQL += fmt.Sprintf(" DEFAULT CHARSET=%s%s;", pool.GetConfig().GetOptions().DefaultEncoding, collate)
-		alters = append(alters, Alter{SQL: alterSQL, Safe: true, Pool: entitySchema.GetDB().GetConfig().GetCode()})
+		downSQL := fmt.Sprintf("ALTER TABLE `%s`.`%s`\n ENGINE=%s DEFAULT CHARSET=%s;", pool.GetConfig().GetDatabaseName(),
+			entitySchema.GetTableName(), sqlSchema.Engine, sqlSchema.DBEncoding)
+		alters = append(alters, Alter{SQL: alterSQL, Down: downSQL, Safe: true, Pool: entitySchema.GetDB().GetConfig().GetCode(),
+			EstimatedRows: getMySQLEstimatedRows(orm, pool, dbTableName)})
 	}
 	if sqlSchema.PostAlters != nil {
 		postAlters = append(postAlters, sqlSchema.PostAlters...)
@@ -430,16 +495,6 @@ OUTER:
 	return
 }
 
-func isTableEmpty(db DBClient, tableName string) bool {
-	/* #nosec */
-	rows, err := db.Query(fmt.Sprintf("SELECT * FROM `%s` LIMIT 1", tableName))
-	defer func() {
-		_ = rows.Close()
-	}()
-	checkError(err)
-	return !rows.Next()
-}
-
 func checkColumn(engine Engine, schema *entitySchema, field *reflect.StructField, indexes map[string]*IndexSchemaDefinition, prefix string) ([]*ColumnSchemaDefinition, error) {
 	var definition string
 	var addNotNullIfNotSet bool
@@ -579,20 +634,16 @@ func checkColumn(engine Engine, schema *entitySchema, field *reflect.StructField
 				return nil, fmt.Errorf("field type %s is not supported, consider adding  tag `ignore`", field.Type.String())
 			}
 		}
-		isNotNull := false
-		if addNotNullIfNotSet || isRequired {
-			definition += " NOT NULL"
-			isNotNull = true
-		}
+		column := &columnDefinition{name: columnName, columnType: definition}
+		column.notNull = addNotNullIfNotSet || isRequired
 		if defaultValue != "nil" && columnName != "ID" {
-			definition += " DEFAULT " + defaultValue
-		} else if !isNotNull && addDefaultNullIfNullable {
-			definition += " DEFAULT NULL"
+			column.defaultValue = defaultValue
+		} else if !column.notNull && addDefaultNullIfNullable {
+			column.defaultValue = "NULL"
 		}
-		if schema.archived && prefix == "" && columnName == "ID" {
-			definition += " AUTO_INCREMENT"
-		}
-		columns = append(columns, &ColumnSchemaDefinition{columnName, fmt.Sprintf("`%s` %s", columnName, definition)})
+		column.autoIncrement = schema.archived && prefix == "" && columnName == "ID"
+		definition = schema.GetDB().GetConfig().getDialect().columnDefinition(column)
+		columns = append(columns, &ColumnSchemaDefinition{columnName, definition})
 	}
 	return columns, nil
 }
@@ -789,3 +840,12 @@ func buildCreateIndexSQL(index *IndexSchemaDefinition) string {
 	}
 	return fmt.Sprintf("ADD %s `%s` (%s)", indexType, index.Name, strings.Join(indexColumns, ","))
 }
+
+func hasColumnSchemaDefinition(columns []*ColumnSchemaDefinition, name string) bool {
+	for _, column := range columns {
+		if column.ColumnName == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package beeorm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+func (e *entitySchema) initRenamedColumns() error {
+	e.renamedColumns = make(map[string]string)
+	for field, tags := range e.tags {
+		oldName, has := tags["renamedFrom"]
+		if !has || field == "ID" {
+			continue
+		}
+		if oldName == "" || oldName == "true" {
+			return fmt.Errorf("missing renamedFrom value in field '%s'", field)
+		}
+		if slices.Contains(e.columnNames, field) {
+			e.renamedColumns[field] = oldName
+			continue
+		}
+		for _, column := range e.columnNames {
+			if strings.HasPrefix(column, field+"_") {
+				e.renamedColumns[column] = oldName + column[len(field):]
+			}
+		}
+	}
+	for _, oldName := range e.renamedColumns {
+		if slices.Contains(e.columnNames, oldName) {
+			return fmt.Errorf("renamedFrom column '%s' already exists in entity '%s'", oldName, e.t.String())
+		}
+	}
+	return nil
+}
+
+func (e *entitySchema) getCacheFieldsQuery() string {
+	if len(e.renamedColumns) == 0 {
+		return e.fieldsQuery
+	}
+	columns := make([]string, len(e.columnNames))
+	for i, column := range e.columnNames {
+		if oldName, has := e.renamedColumns[column]; has {
+			column = oldName
+		}
+		columns[i] = "`" + column + "`"
+	}
+	return strings.Join(columns, ",")
+}
+
+func (e *entitySchema) getColumnsRenamedTo() map[string]string {
+	renamedTo := make(map[string]string, len(e.renamedColumns))
+	for newName, oldName := range e.renamedColumns {
+		renamedTo[oldName] = newName
+	}
+	return renamedTo
+}
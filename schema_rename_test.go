@@ -0,0 +1,72 @@
+package beeorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type renameEntityOld struct {
+	ID   uint64 `orm:"table=renameEntityOld;redisCache"`
+	Name string `orm:"unique=Name"`
+	Age  uint8
+}
+
+type renameEntity struct {
+	ID    uint64 `orm:"renamedFrom=renameEntityOld;redisCache"`
+	Title string `orm:"unique=Name;renamedFrom=Name"`
+	Age   uint8
+}
+
+type renameEntityInvalid struct {
+	ID    uint64
+	Title string `orm:"renamedFrom=Age"`
+	Age   uint8
+}
+
+func TestSchemaRename(t *testing.T) {
+	registry := NewRegistry()
+	orm := PrepareTables(t, registry, renameEntityOld{})
+	entity := NewEntity[renameEntityOld](orm)
+	entity.Name = "Tom"
+	entity.Age = 18
+	assert.NoError(t, orm.Flush())
+	oldSchema := GetEntitySchema[renameEntityOld](orm)
+
+	registry = NewRegistry()
+	registry.RegisterMySQL("root:root@tcp(localhost:3377)/test", DefaultPoolCode, &MySQLOptions{})
+	registry.RegisterRedis("localhost:6385", 0, DefaultPoolCode, nil)
+	registry.RegisterEntity(renameEntity{})
+	engine, err := registry.Validate()
+	assert.NoError(t, err)
+	orm = engine.NewORM(orm.Context())
+	schema := GetEntitySchema[renameEntity](orm).(*entitySchema)
+	assert.Equal(t, oldSchema.(*entitySchema).cacheKey, schema.cacheKey)
+	assert.Equal(t, map[string]string{"Title": "Name"}, schema.renamedColumns)
+
+	alters := GetAlters(orm)
+	assert.Len(t, alters, 2)
+	assert.Equal(t, "RENAME TABLE `test`.`renameEntityOld` TO `test`.`renameEntity`;", alters[0].SQL)
+	assert.Equal(t, "RENAME TABLE `test`.`renameEntity` TO `test`.`renameEntityOld`;", alters[0].Down)
+	assert.True(t, alters[0].Safe)
+	assert.Equal(t, "ALTER TABLE `test`.`renameEntity` RENAME COLUMN `Name` TO `Title`;", alters[1].SQL)
+	assert.True(t, alters[1].Safe)
+	for _, alter := range alters {
+		alter.Exec(orm)
+	}
+	assert.Len(t, GetAlters(orm), 0)
+
+	loaded, found := GetByID[renameEntity](orm, entity.ID)
+	assert.True(t, found)
+	assert.Equal(t, "Tom", loaded.Title)
+	assert.Equal(t, uint8(18), loaded.Age)
+	loaded, found = GetByUniqueIndex[renameEntity](orm, "Name", "Tom")
+	assert.True(t, found)
+	assert.Equal(t, entity.ID, loaded.ID)
+
+	registry = NewRegistry()
+	registry.RegisterMySQL("root:root@tcp(localhost:3377)/test", DefaultPoolCode, &MySQLOptions{})
+	registry.RegisterEntity(renameEntityInvalid{})
+	_, err = registry.Validate()
+	assert.EqualError(t, err, "renamedFrom column 'Age' already exists in entity 'beeorm.renameEntityInvalid'")
+}
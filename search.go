@@ -253,47 +253,62 @@ func prepareScanForFields(fields *tableFields, start int, pointers []any) int {
 
 func searchRow[E any](orm ORM, where Where) (entity *E, found bool) {
 	schema := getEntitySchema[E](orm)
-	pool := schema.GetDB()
+	shards := schema.getWhereShards(where)
+	withDeleted := isWithDeleted(where)
+	where = schema.applySoftDelete(where)
 	whereQuery := where.String()
 
-	if schema.hasLocalCache {
-		query := "SELECT ID FROM `" + schema.GetTableName() + "` WHERE " + whereQuery + " LIMIT 1"
+	if schema.hasLocalCache && !withDeleted {
+		query := "SELECT `ID` FROM `" + schema.GetTableName() + "` WHERE " + whereQuery + " LIMIT 1"
 		var id uint64
-		if pool.QueryRow(orm, NewWhere(query, where.GetParameters()...), &id) {
-			return GetByID[E](orm, id)
+		for _, shard := range shards {
+			if shard.GetDB().QueryRow(orm, NewWhere(query, where.GetParameters()...), &id) {
+				return GetByID[E](orm, id)
+			}
 		}
 		return nil, false
 	}
 
 	/* #nosec */
 	query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.GetTableName() + "` WHERE " + whereQuery + " LIMIT 1"
-	pointers := prepareScan(schema)
-	found = pool.QueryRow(orm, NewWhere(query, where.GetParameters()...), pointers...)
-	if !found {
-		return nil, false
+	for _, shard := range shards {
+		pointers := prepareScan(schema)
+		found = shard.GetDB().QueryRow(orm, NewWhere(query, where.GetParameters()...), pointers...)
+		if found {
+			value := reflect.New(schema.t)
+			entity = value.Interface().(*E)
+			deserializeFromDB(schema.fields, value.Elem(), pointers)
+			return entity, true
+		}
 	}
-	value := reflect.New(schema.t)
-	entity = value.Interface().(*E)
-	deserializeFromDB(schema.fields, value.Elem(), pointers)
-	return entity, true
+	return nil, false
 }
 
 func search[E any](orm ORM, where Where, pager *Pager, withCount bool) (results EntityIterator[E], totalRows int) {
 	schema := getEntitySchema[E](orm)
 	entities := make([]*E, 0)
-	if schema.hasLocalCache {
+	if schema.hasLocalCache && !isWithDeleted(where) {
 		ids, total := SearchIDsWithCount[E](orm, where, pager)
 		if total == 0 {
 			return &emptyResultsIterator[E]{}, 0
 		}
 		return &localCacheIDsIterator[E]{orm: orm.(*ormImplementation), schema: schema, ids: ids, index: -1}, total
 	}
+	shards := schema.getWhereShards(where)
+	if len(shards) > 1 {
+		rows, total := searchShards(orm, schema, shards, where, pager, withCount, false)
+		for _, row := range rows {
+			entities = append(entities, row.value.Interface().(*E))
+		}
+		return &entityIterator[E]{orm: orm.(*ormImplementation), schema: schema, index: -1, rows: entities}, total
+	}
+	pool := getWhereDB(shards[0], where)
+	where = schema.applySoftDelete(where)
 	whereQuery := where.String()
 	query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.GetTableName() + "` WHERE " + whereQuery
 	if pager != nil {
 		query += " " + pager.String()
 	}
-	pool := schema.GetDB()
 	queryResults, def := pool.Query(orm, query, where.GetParameters()...)
 	defer def()
 
@@ -309,9 +324,9 @@ func search[E any](orm ORM, where Where, pager *Pager, withCount bool) (results
 	def()
 	totalRows = i
 	if pager != nil {
-		totalRows = getTotalRows(orm, withCount, pager, where, schema, i)
+		totalRows = getTotalRows(orm, withCount, pager, where, shards[0], i)
 	}
-	resultsIterator := &entityIterator[E]{index: -1}
+	resultsIterator := &entityIterator[E]{orm: orm.(*ormImplementation), schema: schema, index: -1}
 	resultsIterator.rows = entities
 	return resultsIterator, totalRows
 }
@@ -321,13 +336,24 @@ func searchOne[E any](orm ORM, where Where) (*E, bool) {
 }
 
 func searchIDs(orm ORM, schema EntitySchema, where Where, pager *Pager, withCount bool) (ids []uint64, total int) {
+	shards := schema.(*entitySchema).getWhereShards(where)
+	if len(shards) > 1 {
+		rows, totalRows := searchShards(orm, schema.(*entitySchema), shards, where, pager, withCount, true)
+		result := make([]uint64, len(rows))
+		for i, row := range rows {
+			result[i] = row.id
+		}
+		return result, totalRows
+	}
+	schema = shards[0]
+	pool := getWhereDB(shards[0], where)
+	where = schema.(*entitySchema).applySoftDelete(where)
 	whereQuery := where.String()
 	/* #nosec */
 	query := "SELECT `ID` FROM `" + schema.GetTableName() + "` WHERE " + whereQuery
 	if pager != nil {
 		query += " " + pager.String()
 	}
-	pool := schema.GetDB()
 	results, def := pool.Query(orm, query, where.GetParameters()...)
 	defer def()
 	result := make([]uint64, 0)
@@ -0,0 +1,413 @@
+package beeorm
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v2"
+)
+
+func WithShardKey(where Where, key uint64) Where {
+	options := getWhereOptions(where)
+	options.shardKey = &key
+	return options
+}
+
+func (e *entitySchema) initShardPools(registry *registry) error {
+	shards := e.getTag("shards", "", "")
+	if shards == "" {
+		return nil
+	}
+	for _, pool := range strings.Split(shards, ",") {
+		pool = strings.TrimSpace(pool)
+		_, has := registry.mysqlPools[pool]
+		if !has {
+			return fmt.Errorf("mysql pool '%s' not found", pool)
+		}
+		e.shardPools = append(e.shardPools, pool)
+	}
+	e.mysqlPoolCode = e.shardPools[0]
+	e.shardKey = e.getTag("shardKey", "", "")
+	if e.shardKey != "" {
+		field, has := e.t.FieldByName(e.shardKey)
+		if !has {
+			return fmt.Errorf("shard key field '%s' not found", e.shardKey)
+		}
+		switch field.Type.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return fmt.Errorf("shard key field '%s' must be unsigned integer", e.shardKey)
+		}
+	}
+	return nil
+}
+
+func (e *entitySchema) initShards() {
+	if len(e.shardPools) == 0 {
+		return
+	}
+	e.shards = make([]*entitySchema, len(e.shardPools))
+	e.shards[0] = e
+	for i, pool := range e.shardPools[1:] {
+		shard := *e
+		shard.mysqlPoolCode = pool
+		shard.asyncCacheKey = e.asyncCacheKey + ":" + pool
+		shard.asyncTemporaryQueue = newAsyncTemporaryQueue(e.engine.Registry().(*engineRegistryImplementation).asyncBufferOptions)
+		shard.asyncTemporaryQueueLen = &atomic.Int64{}
+		e.shards[i+1] = &shard
+	}
+	for _, shard := range e.shards[1:] {
+		shard.shards = e.shards
+	}
+}
+
+func (e *entitySchema) getShards() []*entitySchema {
+	if len(e.shards) == 0 {
+		return []*entitySchema{e}
+	}
+	return e.shards
+}
+
+func (e *entitySchema) getShard(id uint64) *entitySchema {
+	if len(e.shards) == 0 {
+		return e
+	}
+	return e.shards[id%uint64(len(e.shards))]
+}
+
+func (e *entitySchema) getWhereShards(where Where) []*entitySchema {
+	if len(e.shards) > 0 {
+		options, has := where.(*whereOptions)
+		if has && options.shardKey != nil {
+			return []*entitySchema{e.getShard(*options.shardKey)}
+		}
+	}
+	return e.getShards()
+}
+
+func (e *entitySchema) shardedID(uuid uint64) uint64 {
+	total := uint64(len(e.shards))
+	return uuid*total + uuid%total
+}
+
+func (e *entitySchema) groupIDsByShard(ids []uint64) map[*entitySchema][]uint64 {
+	grouped := make(map[*entitySchema][]uint64)
+	for _, id := range ids {
+		shard := e.getShard(id)
+		grouped[shard] = append(grouped[shard], id)
+	}
+	return grouped
+}
+
+func (e *entitySchema) shardKeyID(id, key uint64) uint64 {
+	total := uint64(len(e.shards))
+	return id - id%total + key%total
+}
+
+func (e *entitySchema) getShardKey(elem reflect.Value) uint64 {
+	if e.shardKey == "" {
+		return 0
+	}
+	return elem.FieldByName(e.shardKey).Uint()
+}
+
+func (orm *ormImplementation) checkShardKeys() error {
+	var err error
+	orm.trackedEntities.Range(func(_ uint64, value *xsync.MapOf[uint64, EntityFlush]) bool {
+		value.Range(func(id uint64, flush EntityFlush) bool {
+			schema := flush.Schema()
+			if schema.shardKey == "" || (flush.flushType() != Insert && flush.flushType() != Upsert) {
+				return true
+			}
+			key := schema.getShardKey(flush.(entityFlushInsert).getValue().Elem())
+			if schema.getShard(id) != schema.getShard(key) {
+				err = fmt.Errorf("shard key %d of entity '%s' with ID %d points to another shard, use NewEntityWithShardKey", key, schema.t.String(), id)
+				return false
+			}
+			return true
+		})
+		return err == nil
+	})
+	return err
+}
+
+type shardSearchRow struct {
+	id    uint64
+	value reflect.Value
+	keys  []any
+}
+
+type shardOrderBy struct {
+	expression string
+	desc       bool
+	isString   bool
+}
+
+var shardSearchTailRegexp = regexp.MustCompile(`(?i)(GROUP\s+BY|ORDER\s+BY|LIMIT)\s`)
+var shardSearchLimitRegexp = regexp.MustCompile(`(?i)^\s*(\d+)\s*(?:,\s*(\d+)|OFFSET\s+(\d+))?\s*$`)
+var shardSearchDirectionRegexp = regexp.MustCompile(`(?i)\s+(ASC|DESC)$`)
+
+func searchShards(orm ORM, schema *entitySchema, shards []*entitySchema, where Where, pager *Pager, withCount, idsOnly bool) (rows []shardSearchRow, totalRows int) {
+	sourceWhere := where
+	where = schema.applySoftDelete(where)
+	conditions, tail := splitWhereTail(where.String())
+	if strings.TrimSpace(conditions) == "" {
+		conditions = "1"
+	}
+	groupBy, orderBy, offset, limit := parseShardSearchTail(tail)
+	for i, order := range orderBy {
+		field, has := schema.t.FieldByName(strings.Trim(order.expression, "`"))
+		orderBy[i].isString = has && (field.Type.Kind() == reflect.String || field.Type.String() == "*string")
+	}
+	if pager != nil {
+		offset = (pager.GetCurrentPage() - 1) * pager.GetPageSize()
+		limit = pager.GetPageSize()
+	}
+	columns := "`ID`"
+	if !idsOnly {
+		columns = schema.fieldsQuery
+	}
+	orderByQuery := ""
+	for _, order := range orderBy {
+		columns += "," + order.expression
+		orderByQuery += order.expression
+		if order.desc {
+			orderByQuery += " DESC"
+		}
+		orderByQuery += ","
+	}
+	/* #nosec */
+	query := "SELECT " + columns + " FROM `" + schema.GetTableName() + "` WHERE " + conditions
+	if groupBy != "" {
+		query += " GROUP BY " + groupBy
+	}
+	query += " ORDER BY " + orderByQuery + "`ID`"
+	if limit >= 0 {
+		query += " LIMIT " + strconv.Itoa(offset+limit)
+	}
+	shardRows := make([][]shardSearchRow, len(shards))
+	for i, shard := range shards {
+		func() {
+			results, def := getWhereDB(shard, sourceWhere).Query(orm, query, where.GetParameters()...)
+			defer def()
+			for results.Next() {
+				row := shardSearchRow{keys: make([]any, len(orderBy))}
+				var pointers []any
+				if idsOnly {
+					pointers = []any{&row.id}
+				} else {
+					pointers = prepareScan(schema)
+				}
+				for k := range row.keys {
+					pointers = append(pointers, &row.keys[k])
+				}
+				results.Scan(pointers...)
+				if !idsOnly {
+					row.value = reflect.New(schema.t)
+					deserializeFromDB(schema.fields, row.value.Elem(), pointers)
+					row.id = *pointers[0].(*uint64)
+				}
+				shardRows[i] = append(shardRows[i], row)
+			}
+		}()
+		if withCount && pager != nil {
+			total := 0
+			/* #nosec */
+			shard.GetDB().QueryRow(orm, NewWhere("SELECT count(1) FROM `"+schema.GetTableName()+"` WHERE "+conditions, where.GetParameters()...), &total)
+			totalRows += total
+		}
+	}
+	rows = mergeShardSearchRows(shardRows, orderBy)
+	if !withCount || pager == nil {
+		totalRows = len(rows)
+	}
+	if offset >= len(rows) {
+		return nil, totalRows
+	}
+	end := len(rows)
+	if limit >= 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return rows[offset:end], totalRows
+}
+
+func parseShardSearchTail(tail string) (groupBy string, orderBy []shardOrderBy, offset, limit int) {
+	limit = -1
+	var positions [][]int
+	for _, loc := range shardSearchTailRegexp.FindAllStringSubmatchIndex(tail, -1) {
+		if isTopLevelSQLPosition(tail, loc[0]) {
+			positions = append(positions, loc)
+		}
+	}
+	for i, loc := range positions {
+		end := len(tail)
+		if i+1 < len(positions) {
+			end = positions[i+1][0]
+		}
+		keyword := strings.ToUpper(strings.Join(strings.Fields(tail[loc[2]:loc[3]]), " "))
+		value := strings.TrimSpace(tail[loc[1]:end])
+		switch keyword {
+		case "GROUP BY":
+			groupBy = value
+		case "ORDER BY":
+			for _, expression := range splitTopLevelSQLList(value) {
+				order := shardOrderBy{expression: strings.TrimSpace(expression)}
+				if direction := shardSearchDirectionRegexp.FindStringSubmatch(order.expression); direction != nil {
+					order.desc = strings.EqualFold(direction[1], "DESC")
+					order.expression = strings.TrimSpace(order.expression[0 : len(order.expression)-len(direction[0])])
+				}
+				if order.expression != "" {
+					orderBy = append(orderBy, order)
+				}
+			}
+		case "LIMIT":
+			matches := shardSearchLimitRegexp.FindStringSubmatch(value)
+			if matches == nil {
+				panic(fmt.Errorf("unsupported LIMIT '%s' in sharded search", value))
+			}
+			limit, _ = strconv.Atoi(matches[1])
+			if matches[2] != "" {
+				offset = limit
+				limit, _ = strconv.Atoi(matches[2])
+			} else if matches[3] != "" {
+				offset, _ = strconv.Atoi(matches[3])
+			}
+		}
+	}
+	return groupBy, orderBy, offset, limit
+}
+
+func splitTopLevelSQLList(value string) []string {
+	var items []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		if value[i] == ',' && isTopLevelSQLPosition(value, i) {
+			items = append(items, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, value[start:])
+}
+
+func mergeShardSearchRows(shardRows [][]shardSearchRow, orderBy []shardOrderBy) []shardSearchRow {
+	total := 0
+	for _, rows := range shardRows {
+		total += len(rows)
+	}
+	merged := make([]shardSearchRow, 0, total)
+	positions := make([]int, len(shardRows))
+	for len(merged) < total {
+		best := -1
+		for i, rows := range shardRows {
+			if positions[i] >= len(rows) {
+				continue
+			}
+			if best == -1 || compareShardSearchRows(rows[positions[i]], shardRows[best][positions[best]], orderBy) < 0 {
+				best = i
+			}
+		}
+		merged = append(merged, shardRows[best][positions[best]])
+		positions[best]++
+	}
+	return merged
+}
+
+func compareShardSearchRows(a, b shardSearchRow, orderBy []shardOrderBy) int {
+	for i, order := range orderBy {
+		result := compareShardSearchValues(a.keys[i], b.keys[i], order.isString)
+		if result != 0 {
+			if order.desc {
+				return -result
+			}
+			return result
+		}
+	}
+	if a.id < b.id {
+		return -1
+	} else if a.id > b.id {
+		return 1
+	}
+	return 0
+}
+
+func compareShardSearchValues(a, b any, isString bool) int {
+	if a == nil || b == nil {
+		if a == nil && b == nil {
+			return 0
+		} else if a == nil {
+			return -1
+		}
+		return 1
+	}
+	aTime, aIsTime := a.(time.Time)
+	bTime, bIsTime := b.(time.Time)
+	if aIsTime && bIsTime {
+		return aTime.Compare(bTime)
+	}
+	aString := shardSearchValueToString(a)
+	bString := shardSearchValueToString(b)
+	if !isString {
+		aNumber, aErr := strconv.ParseFloat(aString, 64)
+		bNumber, bErr := strconv.ParseFloat(bString, 64)
+		if aErr == nil && bErr == nil {
+			if aNumber < bNumber {
+				return -1
+			} else if aNumber > bNumber {
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(strings.ToLower(aString), strings.ToLower(bString))
+}
+
+func shardSearchValueToString(value any) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func (e *entitySchema) queryByIDs(orm ORM, ids []uint64, primary bool, f func(pointers []any)) {
+	for shard, shardIDs := range e.groupIDsByShard(ids) {
+		func() {
+			/* #nosec */
+			query := "SELECT " + e.fieldsQuery + " FROM `" + e.GetTableName() + "` WHERE `ID` IN ("
+			for i, id := range shardIDs {
+				if i > 0 {
+					query += ","
+				}
+				query += strconv.FormatUint(id, 10)
+			}
+			query += ")"
+			db := shard.GetDB()
+			if primary {
+				db = db.Primary()
+			}
+			res, def := db.Query(orm, query)
+			defer def()
+			for res.Next() {
+				pointers := prepareScan(e)
+				res.Scan(pointers...)
+				f(pointers)
+			}
+		}()
+	}
+}
+
+func getAllShardSchemas(registry EngineRegistry) []*entitySchema {
+	schemas := make([]*entitySchema, 0, len(registry.Entities()))
+	for _, schema := range registry.Entities() {
+		schemas = append(schemas, schema.(*entitySchema).getShards()...)
+	}
+	return schemas
+}
@@ -0,0 +1,140 @@
+package beeorm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type shardEntity struct {
+	ID     uint64 `orm:"shards=default,shard;shardKey=UserID"`
+	UserID uint64
+	Name   string
+}
+
+type shardReferenceEntity struct {
+	ID    uint64
+	Shard Reference[shardEntity]
+}
+
+func TestShards(t *testing.T) {
+	var entity *shardEntity
+	registry := NewRegistry()
+	registry.RegisterMySQL("root:root@tcp(localhost:3377)/test_shard", "shard", &MySQLOptions{})
+	orm := PrepareTables(t, registry, entity, &shardReferenceEntity{})
+	schema := getEntitySchema[shardEntity](orm)
+	assert.Len(t, schema.shards, 2)
+	assert.Equal(t, DefaultPoolCode, schema.shards[0].GetDB().GetConfig().GetCode())
+	assert.Equal(t, "shard", schema.shards[1].GetDB().GetConfig().GetCode())
+	assert.NotEqual(t, schema.shards[0].asyncCacheKey, schema.shards[1].asyncCacheKey)
+
+	var ids []uint64
+	for i := 1; i <= 10; i++ {
+		entity = NewEntityWithShardKey[shardEntity](orm, uint64(i))
+		entity.Name = fmt.Sprintf("Name %02d", 11-i)
+		ids = append(ids, entity.ID)
+	}
+	assert.NoError(t, orm.Flush())
+	ids = SearchIDs[shardEntity](orm, NewWhere("1"), nil)
+	assert.Len(t, ids, 10)
+	for _, id := range ids {
+		loaded, found := GetByID[shardEntity](orm, id)
+		assert.True(t, found)
+		assert.Equal(t, id%2, loaded.UserID%2)
+	}
+
+	total := 0
+	schema.shards[1].GetDB().QueryRow(orm, NewWhere("SELECT COUNT(1) FROM `shardEntity`"), &total)
+	assert.Equal(t, 5, total)
+
+	rows := GetByIDs[shardEntity](orm, ids...)
+	assert.Equal(t, 10, rows.Len())
+
+	iterator, total := SearchWithCount[shardEntity](orm, NewWhere("1"), NewPager(2, 3))
+	assert.Equal(t, 10, total)
+	assert.Equal(t, 3, iterator.Len())
+	iterator.Next()
+	assert.Equal(t, ids[3], iterator.Entity().ID)
+
+	iterator, total = SearchWithCount[shardEntity](orm, NewWhere("1 ORDER BY `Name`"), NewPager(2, 3))
+	assert.Equal(t, 10, total)
+	var userIDs []uint64
+	for iterator.Next() {
+		userIDs = append(userIDs, iterator.Entity().UserID)
+	}
+	assert.Equal(t, []uint64{7, 6, 5}, userIDs)
+	iterator = Search[shardEntity](orm, NewWhere("`UserID` > ? ORDER BY `Name` DESC LIMIT 2", 1), NewPager(2, 2))
+	userIDs = nil
+	for iterator.Next() {
+		userIDs = append(userIDs, iterator.Entity().UserID)
+	}
+	assert.Equal(t, []uint64{4, 5}, userIDs)
+	ids = SearchIDs[shardEntity](orm, NewWhere("1 ORDER BY `Name` DESC LIMIT 1, 2"), nil)
+	assert.Len(t, ids, 2)
+	loaded, _ := GetByID[shardEntity](orm, ids[0])
+	assert.Equal(t, uint64(2), loaded.UserID)
+
+	assert.Len(t, SearchIDs[shardEntity](orm, WithShardKey(NewWhere("1"), 3), nil), 5)
+	assert.Equal(t, 10, Count[shardEntity](orm, nil))
+	assert.Equal(t, float64(55), Sum[shardEntity](orm, "UserID", nil))
+	maxUserID, found := Max[shardEntity](orm, "UserID", nil)
+	assert.True(t, found)
+	assert.Equal(t, "10", maxUserID)
+
+	entity = NewEntityWithShardKey[shardEntity](orm, 13)
+	entity.Name = "Referenced"
+	reference := NewEntity[shardReferenceEntity](orm)
+	reference.Shard = Reference[shardEntity](entity.ID)
+	assert.NoError(t, orm.Flush())
+	reference, _ = GetByID[shardReferenceEntity](orm, reference.ID)
+	assert.Equal(t, "Referenced", reference.Shard.GetEntity(orm).Name)
+	assert.Equal(t, uint64(13), reference.Shard.GetEntity(orm).UserID)
+	total = 0
+	schema.shards[1].GetDB().QueryRow(orm, NewWhere("SELECT COUNT(1) FROM `shardEntity` WHERE `ID` = ?", entity.ID), &total)
+	assert.Equal(t, 1, total)
+
+	entity = NewEntityWithShardKey[shardEntity](orm, 2)
+	entity.UserID = 3
+	assert.EqualError(t, orm.Flush(), fmt.Sprintf("shard key 3 of entity 'beeorm.shardEntity' with ID %d points to another shard, use NewEntityWithShardKey", entity.ID))
+	orm.ClearFlush()
+	assert.PanicsWithError(t, "entity 'beeorm.shardReferenceEntity' has no shard key", func() {
+		NewEntityWithShardKey[shardReferenceEntity](orm, 1)
+	})
+
+	alters, has := schema.GetSchemaChanges(orm)
+	assert.False(t, has)
+	assert.Len(t, alters, 0)
+	schema.DropTable(orm)
+	alters, has = schema.GetSchemaChanges(orm)
+	assert.True(t, has)
+	assert.Len(t, alters, 2)
+	assert.Equal(t, DefaultPoolCode, alters[0].Pool)
+	assert.Equal(t, "shard", alters[1].Pool)
+}
+
+func TestShardSearchTail(t *testing.T) {
+	groupBy, orderBy, offset, limit := parseShardSearchTail(" GROUP BY `Age` ORDER BY IF(`A` = 'x, y', 1, 0) DESC, `Name` LIMIT 5, 10")
+	assert.Equal(t, "`Age`", groupBy)
+	assert.Equal(t, []shardOrderBy{{expression: "IF(`A` = 'x, y', 1, 0)", desc: true}, {expression: "`Name`"}}, orderBy)
+	assert.Equal(t, 5, offset)
+	assert.Equal(t, 10, limit)
+	_, orderBy, offset, limit = parseShardSearchTail("")
+	assert.Nil(t, orderBy)
+	assert.Equal(t, 0, offset)
+	assert.Equal(t, -1, limit)
+	_, _, offset, limit = parseShardSearchTail(" LIMIT 3 OFFSET 6")
+	assert.Equal(t, 6, offset)
+	assert.Equal(t, 3, limit)
+
+	orderBy = []shardOrderBy{{expression: "`Name`", isString: true}, {expression: "`Age`", desc: true}}
+	rows := mergeShardSearchRows([][]shardSearchRow{
+		{{id: 1, keys: []any{[]byte("a"), int64(5)}}, {id: 3, keys: []any{[]byte("b"), []byte("10")}}},
+		{{id: 6, keys: []any{nil, int64(1)}}, {id: 2, keys: []any{[]byte("A"), int64(9)}}, {id: 4, keys: []any{[]byte("b"), []byte("9")}}},
+	}, orderBy)
+	ids := make([]uint64, len(rows))
+	for i, row := range rows {
+		ids[i] = row.id
+	}
+	assert.Equal(t, []uint64{6, 2, 1, 3, 4}, ids)
+}
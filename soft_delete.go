@@ -0,0 +1,191 @@
+package beeorm
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const softDeleteBoolField = "FakeDelete"
+const softDeleteTimeField = "DeletedAt"
+
+var whereTailRegexp = regexp.MustCompile(`(?i)\s(ORDER\s+BY|GROUP\s+BY|LIMIT)\s`)
+
+type whereOptions struct {
+	Where
+	withDeleted bool
+	cacheTTL    time.Duration
+	shardKey    *uint64
+	primary     bool
+}
+
+func WithDeleted(where Where) Where {
+	options := getWhereOptions(where)
+	options.withDeleted = true
+	return options
+}
+
+func getWhereOptions(where Where) *whereOptions {
+	current, isOptions := where.(*whereOptions)
+	if isOptions {
+		options := *current
+		return &options
+	}
+	return &whereOptions{Where: where}
+}
+
+func ForceDeleteEntity[E any](orm ORM, source E) {
+	toRemove := &removableEntity{force: true}
+	toRemove.orm = orm
+	toRemove.source = source
+	toRemove.value = reflect.ValueOf(source).Elem()
+	toRemove.id = toRemove.value.Field(0).Uint()
+	schema := getEntitySchema[E](orm)
+	toRemove.schema = schema
+	orm.trackEntity(toRemove)
+}
+
+func (e *entitySchema) initSoftDelete() error {
+	softDelete := e.getTag("softDelete", "true", "")
+	if softDelete == "" {
+		return nil
+	}
+	var field reflect.StructField
+	has := false
+	if softDelete == "true" {
+		field, has = e.t.FieldByName(softDeleteBoolField)
+		if !has {
+			field, has = e.t.FieldByName(softDeleteTimeField)
+		}
+		if !has {
+			return fmt.Errorf("soft delete requires field %s bool or %s *time.Time", softDeleteBoolField, softDeleteTimeField)
+		}
+	} else {
+		field, has = e.t.FieldByName(softDelete)
+		if !has {
+			return fmt.Errorf("soft delete field '%s' not found", softDelete)
+		}
+	}
+	if len(field.Index) > 1 {
+		return fmt.Errorf("soft delete field '%s' must be defined in main struct", field.Name)
+	}
+	switch field.Type.String() {
+	case "bool":
+		e.softDeleteCondition = "`" + field.Name + "` = 0"
+	case "*time.Time":
+		e.softDeleteCondition = "`" + field.Name + "` IS NULL"
+		e.softDeleteWithTime = true
+	default:
+		return fmt.Errorf("soft delete field '%s' must be bool or *time.Time", field.Name)
+	}
+	e.softDeleteColumn = field.Name
+	return nil
+}
+
+func (e *entitySchema) isSoftDeleted(entity any) bool {
+	if e.softDeleteColumn == "" {
+		return false
+	}
+	field := reflect.ValueOf(entity).Elem().FieldByName(e.softDeleteColumn)
+	if field.Kind() == reflect.Bool {
+		return field.Bool()
+	}
+	return !field.IsNil()
+}
+
+func (e *entitySchema) getSoftDeleteBindValue() any {
+	var value any = true
+	if e.softDeleteWithTime {
+		value = time.Now().UTC()
+	}
+	bindValue, err := e.fieldBindSetters[e.softDeleteColumn](value)
+	checkError(err)
+	return bindValue
+}
+
+func (e *entitySchema) getDeleteQuery() string {
+	return "DELETE FROM `" + e.GetTableName() + "`"
+}
+
+func (e *entitySchema) getSoftDeleteQuery() string {
+	return "UPDATE `" + e.GetTableName() + "` SET `" + e.softDeleteColumn + "` = ?"
+}
+
+func (e *entitySchema) applySoftDelete(where Where) Where {
+	if e.softDeleteColumn == "" {
+		return where
+	}
+	options, isOptions := where.(*whereOptions)
+	if isOptions && options.withDeleted {
+		return where
+	}
+	query, tail := splitWhereTail(where.String())
+	if strings.TrimSpace(query) == "" {
+		query = e.softDeleteCondition
+	} else {
+		query = e.softDeleteCondition + " AND (" + query + ")"
+	}
+	return &BaseWhere{query: query + tail, parameters: where.GetParameters()}
+}
+
+func splitWhereTail(query string) (conditions, tail string) {
+	for _, loc := range whereTailRegexp.FindAllStringIndex(query, -1) {
+		if isTopLevelSQLPosition(query, loc[0]) {
+			return query[0:loc[0]], query[loc[0]:]
+		}
+	}
+	return query, ""
+}
+
+func whereTailHasClause(tail, clause string) bool {
+	tail = " " + tail
+	for _, match := range whereTailRegexp.FindAllStringSubmatchIndex(tail, -1) {
+		name := strings.ToUpper(strings.Join(strings.Fields(tail[match[2]:match[3]]), " "))
+		if name == clause && isTopLevelSQLPosition(tail, match[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkWhereWithoutTail(where Where, operation string) error {
+	if where == nil {
+		return nil
+	}
+	if _, tail := splitWhereTail(where.String()); tail != "" {
+		return fmt.Errorf("%s doesn't support ORDER BY, GROUP BY or LIMIT in where: '%s'", operation, strings.TrimSpace(tail))
+	}
+	return nil
+}
+
+func isTopLevelSQLPosition(query string, position int) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < position; i++ {
+		c := query[i]
+		if quote != 0 {
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+	}
+	return depth == 0 && quote == 0
+}
+
+func isWithDeleted(where Where) bool {
+	options, isOptions := where.(*whereOptions)
+	return isOptions && options.withDeleted
+}
@@ -0,0 +1,111 @@
+package beeorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type softDeleteEntity struct {
+	ID         uint64                         `orm:"localCache;redisCache;softDelete"`
+	Name       string                         `orm:"unique=Name"`
+	Ref        Reference[softDeleteReference] `orm:"index=Ref;cached"`
+	FakeDelete bool
+}
+
+type softDeleteTimeEntity struct {
+	ID        uint64 `orm:"softDelete"`
+	Name      string
+	DeletedAt *time.Time `orm:"time"`
+}
+
+type softDeleteReference struct {
+	ID   uint64
+	Name string
+}
+
+func TestSoftDeleteLocalCache(t *testing.T) {
+	testSoftDelete(t, true, false)
+}
+
+func TestSoftDeleteRedisCache(t *testing.T) {
+	testSoftDelete(t, false, true)
+}
+
+func TestSoftDeleteNoCache(t *testing.T) {
+	testSoftDelete(t, false, false)
+}
+
+func TestSoftDeleteWhereTail(t *testing.T) {
+	schema := &entitySchema{softDeleteColumn: "FakeDelete", softDeleteCondition: "`FakeDelete` = 0"}
+	where := schema.applySoftDelete(NewWhere("`ID` IN (SELECT `ID` FROM `b` ORDER BY `ID` LIMIT 10) ORDER BY `Name` LIMIT 5"))
+	assert.Equal(t, "`FakeDelete` = 0 AND (`ID` IN (SELECT `ID` FROM `b` ORDER BY `ID` LIMIT 10)) ORDER BY `Name` LIMIT 5", where.String())
+	where = schema.applySoftDelete(NewWhere("`Name` = ' ORDER BY ' OR `Name` = 'it\\'s LIMIT 1'"))
+	assert.Equal(t, "`FakeDelete` = 0 AND (`Name` = ' ORDER BY ' OR `Name` = 'it\\'s LIMIT 1')", where.String())
+	where = schema.applySoftDelete(NewWhere("`Name` = ? GROUP BY `Age`", "Tom"))
+	assert.Equal(t, "`FakeDelete` = 0 AND (`Name` = ?) GROUP BY `Age`", where.String())
+	assert.Equal(t, []any{"Tom"}, where.GetParameters())
+}
+
+func testSoftDelete(t *testing.T, local, redis bool) {
+	var entity *softDeleteEntity
+	var timeEntity *softDeleteTimeEntity
+	var reference *softDeleteReference
+	orm := PrepareTables(t, NewRegistry(), entity, timeEntity, reference)
+	schema := GetEntitySchema[softDeleteEntity](orm)
+	schema.DisableCache(!local, !redis)
+
+	ref := NewEntity[softDeleteReference](orm)
+	ref.Name = "Ref"
+	var ids []uint64
+	for _, name := range []string{"a", "b", "c"} {
+		entity = NewEntity[softDeleteEntity](orm)
+		entity.Name = name
+		entity.Ref = Reference[softDeleteReference](ref.ID)
+		ids = append(ids, entity.ID)
+	}
+	assert.NoError(t, orm.Flush())
+
+	entity, _ = GetByID[softDeleteEntity](orm, ids[0])
+	DeleteEntity(orm, entity)
+	assert.NoError(t, orm.Flush())
+	assert.True(t, entity.FakeDelete)
+
+	_, found := GetByID[softDeleteEntity](orm, ids[0])
+	assert.False(t, found)
+	iterator := GetByIDs[softDeleteEntity](orm, ids...)
+	assert.Equal(t, 3, iterator.Len())
+	assert.Nil(t, iterator.All()[0])
+	assert.NotNil(t, iterator.All()[1])
+	assert.Equal(t, 2, Search[softDeleteEntity](orm, NewWhere("1 ORDER BY ID"), nil).Len())
+	assert.Equal(t, 1, Search[softDeleteEntity](orm, NewWhere("`Name` = ? OR `Name` = ?", "a", "b"), nil).Len())
+	assert.Len(t, SearchIDs[softDeleteEntity](orm, NewWhere("1"), nil), 2)
+	_, found = SearchOne[softDeleteEntity](orm, NewWhere("`Name` = ?", "a"))
+	assert.False(t, found)
+	assert.Equal(t, 2, GetAll[softDeleteEntity](orm).Len())
+	assert.Equal(t, 2, GetByReference[softDeleteEntity](orm, "Ref", ref.ID).Len())
+	_, found = GetByUniqueIndex[softDeleteEntity](orm, "Name", "a")
+	assert.False(t, found)
+
+	assert.Equal(t, 3, Search[softDeleteEntity](orm, WithDeleted(NewWhere("1")), nil).Len())
+	assert.Len(t, SearchIDs[softDeleteEntity](orm, WithDeleted(NewWhere("1")), nil), 3)
+	deleted, found := SearchOne[softDeleteEntity](orm, WithDeleted(NewWhere("`Name` = ?", "a")))
+	assert.True(t, found)
+	assert.True(t, deleted.FakeDelete)
+
+	ForceDeleteEntity(orm, deleted)
+	assert.NoError(t, orm.Flush())
+	assert.Len(t, SearchIDs[softDeleteEntity](orm, WithDeleted(NewWhere("1")), nil), 2)
+
+	timeEntity = NewEntity[softDeleteTimeEntity](orm)
+	timeEntity.Name = "Time"
+	assert.NoError(t, orm.Flush())
+	DeleteEntity(orm, timeEntity)
+	assert.NoError(t, orm.Flush())
+	assert.NotNil(t, timeEntity.DeletedAt)
+	_, found = GetByID[softDeleteTimeEntity](orm, timeEntity.ID)
+	assert.False(t, found)
+	assert.Equal(t, 0, GetAll[softDeleteTimeEntity](orm).Len())
+	assert.Equal(t, 1, Search[softDeleteTimeEntity](orm, WithDeleted(NewWhere("1")), nil).Len())
+}
@@ -0,0 +1,381 @@
+package beeorm
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const sqliteDatabaseName = "main"
+const sqliteWaitTimeout = 28800
+
+var sqliteErrorsToSkip = []string{
+	"CHECK constraint failed",
+	"FOREIGN KEY constraint failed",
+	"NOT NULL constraint failed",
+	"UNIQUE constraint failed",
+	"datatype mismatch",
+	"no such column",
+	"no such table",
+	"syntax error",
+}
+
+var sqliteErrorsToRetry = []string{
+	"database is locked",
+	"database table is locked",
+	"SQLITE_BUSY",
+}
+
+type sqliteDialect struct{}
+
+type sqliteColumn struct {
+	name         string
+	columnType   string
+	notNull      bool
+	defaultValue string
+}
+
+func (d *sqliteDialect) driverName(options *MySQLOptions) string {
+	if options != nil && options.Driver != "" {
+		return options.Driver
+	}
+	drivers := sql.Drivers()
+	if !slices.Contains(drivers, "sqlite3") && slices.Contains(drivers, "sqlite") {
+		return "sqlite"
+	}
+	return "sqlite3"
+}
+
+func (d *sqliteDialect) translateQuery(query string) string {
+	return query
+}
+
+func (d *sqliteDialect) translateArgs(args []any) []any {
+	return args
+}
+
+func (d *sqliteDialect) loadServerLimits(db *sql.DB) (maxConnections, waitTimeout int, err error) {
+	err = db.Ping()
+	if err != nil {
+		return 0, 0, err
+	}
+	return 1, sqliteWaitTimeout, nil
+}
+
+func (d *sqliteDialect) tableIdentifier(_ MySQLConfig, tableName string) string {
+	return "`" + tableName + "`"
+}
+
+func (d *sqliteDialect) resetAutoIncrementSQL(_ MySQLConfig, _ string) string {
+	return ""
+}
+
+func (d *sqliteDialect) truncateTableSQL(config MySQLConfig, tableName string) string {
+	return "DELETE FROM " + d.tableIdentifier(config, tableName)
+}
+
+func (d *sqliteDialect) columnDefinition(column *columnDefinition) string {
+	definition := fmt.Sprintf("`%s` %s", column.name, sqliteColumnType(column.columnType))
+	if column.autoIncrement {
+		return definition + " PRIMARY KEY AUTOINCREMENT"
+	}
+	if column.notNull {
+		definition += " NOT NULL"
+	}
+	if column.defaultValue != "" {
+		definition += " DEFAULT " + column.defaultValue
+	}
+	return definition
+}
+
+func (d *sqliteDialect) upsertClause(schema *entitySchema, columns []string) string {
+	if len(columns) == 0 && schema.versionColumn == "" {
+		return " ON CONFLICT DO NOTHING"
+	}
+	updates := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		updates = append(updates, "`"+column+"`=excluded.`"+column+"`")
+	}
+	if schema.versionColumn != "" {
+		updates = append(updates, "`"+schema.versionColumn+"`=`"+schema.versionColumn+"`+1")
+	}
+	doUpdate := " DO UPDATE SET " + strings.Join(updates, ",")
+	indexNames := make([]string, 0, len(schema.uniqueIndices))
+	for indexName := range schema.uniqueIndices {
+		indexNames = append(indexNames, indexName)
+	}
+	sort.Strings(indexNames)
+	clause := ""
+	for _, indexName := range indexNames {
+		clause += " ON CONFLICT(`" + strings.Join(schema.uniqueIndices[indexName], "`,`") + "`)" + doUpdate
+	}
+	return clause + " ON CONFLICT(`ID`)" + doUpdate
+}
+
+func (d *sqliteDialect) upsertReturnsID() bool {
+	return false
+}
+
+func (d *sqliteDialect) getAllTables(db DB) []string {
+	tables := make([]string, 0)
+	results, err := db.GetDBClient().Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	checkError(err)
+	defer func() {
+		_ = results.Close()
+	}()
+	for results.Next() {
+		var row string
+		err = results.Scan(&row)
+		checkError(err)
+		tables = append(tables, row)
+	}
+	err = results.Err()
+	checkError(err)
+	return tables
+}
+
+func (d *sqliteDialect) createTableSQL(orm ORM, db DB, tableName string) string {
+	var createSQL string
+	if !db.QueryRow(orm, NewWhere("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", tableName), &createSQL) {
+		return ""
+	}
+	return createSQL + ";"
+}
+
+func (d *sqliteDialect) skippableAsyncError(rec any) (error, bool) {
+	asError, isError := rec.(error)
+	if !isError {
+		return nil, false
+	}
+	for _, message := range sqliteErrorsToSkip {
+		if strings.Contains(asError.Error(), message) {
+			return asError, true
+		}
+	}
+	return nil, false
+}
+
+func (d *sqliteDialect) retryableAsyncError(rec any) (error, bool) {
+	asError, isError := rec.(error)
+	if !isError {
+		return nil, false
+	}
+	if isConnectionError(asError) {
+		return asError, true
+	}
+	for _, message := range sqliteErrorsToRetry {
+		if strings.Contains(asError.Error(), message) {
+			return asError, true
+		}
+	}
+	return nil, false
+}
+
+func (d *sqliteDialect) getSchemaChanges(orm ORM, schema *entitySchema) (preAlters, alters, postAlters []Alter) {
+	indexes := make(map[string]*IndexSchemaDefinition)
+	columns, err := checkStruct(orm.Engine(), schema, schema.GetType(), indexes, nil, "", -1)
+	checkError(err)
+	pool := schema.GetDB().Primary()
+	poolCode := pool.GetConfig().GetCode()
+	tableName := schema.GetTableName()
+	dbTableName := tableName
+	var skip string
+	hasTable := pool.QueryRow(orm, NewWhere("SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?", tableName), &skip)
+	if !hasTable && schema.tableRenamedFrom != "" {
+		hasTable = pool.QueryRow(orm, NewWhere("SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?", schema.tableRenamedFrom), &skip)
+		if hasTable {
+			dbTableName = schema.tableRenamedFrom
+			preAlters = append(preAlters, Alter{SQL: fmt.Sprintf("ALTER TABLE `%s` RENAME TO `%s`;", dbTableName, tableName),
+				Down: fmt.Sprintf("ALTER TABLE `%s` RENAME TO `%s`;", tableName, dbTableName), Safe: true, Pool: poolCode})
+		}
+	}
+	if !hasTable {
+		alters = append(alters, Alter{SQL: buildSQLiteCreateTableSQL(tableName, columns) + ";",
+			Down: fmt.Sprintf("DROP TABLE IF EXISTS `%s`;", tableName), Safe: true, Pool: poolCode})
+		for _, index := range sortedIndexes(indexes) {
+			postAlters = append(postAlters, Alter{SQL: buildSQLiteCreateIndexSQL(tableName, index) + ";",
+				Down: fmt.Sprintf("DROP INDEX IF EXISTS `%s`;", tableIndexName(tableName, index.Name)), Safe: true, Pool: poolCode})
+		}
+		return
+	}
+
+	dbColumns := make([]*sqliteColumn, 0)
+	func() {
+		results, def := pool.Query(orm, fmt.Sprintf("PRAGMA table_info(`%s`)", dbTableName))
+		defer def()
+		for results.Next() {
+			var position, notNull, primaryKey int
+			var name, columnType string
+			var defaultValue sql.NullString
+			results.Scan(&position, &name, &columnType, &notNull, &defaultValue, &primaryKey)
+			dbColumns = append(dbColumns, &sqliteColumn{name: name, columnType: strings.ToUpper(columnType),
+				notNull: notNull == 1, defaultValue: defaultValue.String})
+		}
+	}()
+	renamedTo := schema.getColumnsRenamedTo()
+	for _, dbColumn := range dbColumns {
+		newName, isRenamed := renamedTo[dbColumn.name]
+		if !isRenamed || slices.ContainsFunc(dbColumns, func(c *sqliteColumn) bool { return c.name == newName }) {
+			continue
+		}
+		preAlters = append(preAlters, Alter{SQL: fmt.Sprintf("ALTER TABLE `%s` RENAME COLUMN `%s` TO `%s`;", tableName, dbColumn.name, newName),
+			Down: fmt.Sprintf("ALTER TABLE `%s` RENAME COLUMN `%s` TO `%s`;", tableName, newName, dbColumn.name), Safe: true, Pool: poolCode})
+		dbColumn.name = newName
+	}
+
+	var addedColumns []*ColumnSchemaDefinition
+	var commonColumns []string
+	rebuild := false
+	destructive := false
+	for _, column := range columns {
+		expected := parseSQLiteColumnDefinition(column.ColumnName, column.Definition)
+		var current *sqliteColumn
+		for _, dbColumn := range dbColumns {
+			if dbColumn.name == column.ColumnName {
+				current = dbColumn
+				break
+			}
+		}
+		if current == nil {
+			if expected.notNull && expected.defaultValue == "" {
+				rebuild = true
+				destructive = true
+			}
+			addedColumns = append(addedColumns, column)
+			continue
+		}
+		commonColumns = append(commonColumns, column.ColumnName)
+		if expected.columnType != current.columnType {
+			rebuild = true
+			destructive = true
+		}
+		if expected.notNull != current.notNull {
+			rebuild = true
+			destructive = destructive || expected.notNull
+		}
+		if expected.defaultValue != current.defaultValue {
+			rebuild = true
+		}
+	}
+	if len(commonColumns) < len(dbColumns) {
+		rebuild = true
+		destructive = true
+	}
+	if rebuild {
+		tmpTableName := "_" + tableName + "_new"
+		quotedColumns := "`" + strings.Join(commonColumns, "`,`") + "`"
+		rebuildSQL := buildSQLiteCreateTableSQL(tmpTableName, columns) + ";\n"
+		if len(commonColumns) > 0 {
+			rebuildSQL += fmt.Sprintf("INSERT INTO `%s` (%s) SELECT %s FROM `%s`;\n", tmpTableName, quotedColumns, quotedColumns, tableName)
+		}
+		rebuildSQL += fmt.Sprintf("DROP TABLE `%s`;\n", tableName)
+		rebuildSQL += fmt.Sprintf("ALTER TABLE `%s` RENAME TO `%s`;", tmpTableName, tableName)
+		safe := !destructive || isTableEmpty(pool, dbTableName)
+		alters = append(alters, Alter{SQL: rebuildSQL, Safe: safe, Pool: poolCode})
+		for _, index := range sortedIndexes(indexes) {
+			postAlters = append(postAlters, Alter{SQL: buildSQLiteCreateIndexSQL(tableName, index) + ";", Safe: true, Pool: poolCode})
+		}
+		return
+	}
+	for _, column := range addedColumns {
+		alters = append(alters, Alter{SQL: fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN %s;", tableName, column.Definition),
+			Down: fmt.Sprintf("ALTER TABLE `%s` DROP COLUMN `%s`;", tableName, column.ColumnName), Safe: true, Pool: poolCode})
+	}
+
+	dbIndexes := make(map[string]string)
+	func() {
+		results, def := pool.Query(orm, "SELECT name, sql FROM sqlite_master WHERE type = 'index' "+
+			"AND tbl_name = ? AND sql IS NOT NULL", dbTableName)
+		defer def()
+		for results.Next() {
+			var name, definition string
+			results.Scan(&name, &definition)
+			dbIndexes[name] = definition
+		}
+	}()
+	for _, index := range sortedIndexes(indexes) {
+		indexName := tableIndexName(tableName, index.Name)
+		createSQL := buildSQLiteCreateIndexSQL(tableName, index)
+		definition, has := dbIndexes[indexName]
+		delete(dbIndexes, indexName)
+		if has && definition == createSQL {
+			continue
+		}
+		if has {
+			alters = append(alters, Alter{SQL: fmt.Sprintf("DROP INDEX `%s`;", indexName), Down: definition + ";", Safe: true, Pool: poolCode})
+		}
+		postAlters = append(postAlters, Alter{SQL: createSQL + ";", Down: fmt.Sprintf("DROP INDEX IF EXISTS `%s`;", indexName),
+			Safe: true, Pool: poolCode})
+	}
+	droppedIndexes := make([]string, 0, len(dbIndexes))
+	for name := range dbIndexes {
+		droppedIndexes = append(droppedIndexes, name)
+	}
+	sort.Strings(droppedIndexes)
+	for _, name := range droppedIndexes {
+		alters = append(alters, Alter{SQL: fmt.Sprintf("DROP INDEX `%s`;", name), Down: dbIndexes[name] + ";", Safe: true, Pool: poolCode})
+	}
+	return
+}
+
+func sqliteColumnType(mysqlType string) string {
+	parts := columnTypeRegexp.FindStringSubmatch(strings.ToLower(mysqlType))
+	if parts == nil {
+		return "TEXT"
+	}
+	switch parts[1] {
+	case "tinyint", "smallint", "mediumint", "int", "bigint":
+		return "INTEGER"
+	case "float", "double":
+		return "REAL"
+	case "decimal":
+		return "NUMERIC"
+	case "blob", "mediumblob", "longblob":
+		return "BLOB"
+	default:
+		return "TEXT"
+	}
+}
+
+func parseSQLiteColumnDefinition(name, definition string) *sqliteColumn {
+	definition = strings.TrimPrefix(definition, "`"+name+"` ")
+	column := &sqliteColumn{name: name}
+	end := len(definition)
+	for _, keyword := range []string{" NOT NULL", " DEFAULT ", " PRIMARY KEY"} {
+		if index := strings.Index(definition, keyword); index >= 0 && index < end {
+			end = index
+		}
+	}
+	column.columnType = definition[0:end]
+	column.notNull = strings.Contains(definition, " NOT NULL")
+	if index := strings.Index(definition, " DEFAULT "); index >= 0 {
+		column.defaultValue = definition[index+9:]
+	}
+	return column
+}
+
+func buildSQLiteCreateTableSQL(tableName string, columns []*ColumnSchemaDefinition) string {
+	definitions := make([]string, 0, len(columns)+1)
+	primaryKey := true
+	for _, column := range columns {
+		definitions = append(definitions, column.Definition)
+		if strings.HasSuffix(column.Definition, " AUTOINCREMENT") {
+			primaryKey = false
+		}
+	}
+	if primaryKey {
+		definitions = append(definitions, "PRIMARY KEY (`ID`)")
+	}
+	return fmt.Sprintf("CREATE TABLE `%s` (\n  %s\n)", tableName, strings.Join(definitions, ",\n  "))
+}
+
+func buildSQLiteCreateIndexSQL(tableName string, index *IndexSchemaDefinition) string {
+	indexType := "INDEX"
+	if index.Unique {
+		indexType = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("CREATE %s `%s` ON `%s` (`%s`)", indexType, tableIndexName(tableName, index.Name),
+		tableName, strings.Join(index.GetColumns(), "`,`"))
+}
@@ -0,0 +1,147 @@
+package beeorm
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+type sqliteEntity struct {
+	ID     uint64 `orm:"localCache;redisCache"`
+	Code   string `orm:"required;unique=Code"`
+	Name   string `orm:"required"`
+	Active bool
+	Age    uint16
+}
+
+func TestSQLiteColumnTypes(t *testing.T) {
+	assert.Equal(t, "INTEGER", sqliteColumnType("tinyint(1)"))
+	assert.Equal(t, "INTEGER", sqliteColumnType("bigint unsigned"))
+	assert.Equal(t, "REAL", sqliteColumnType("double unsigned"))
+	assert.Equal(t, "NUMERIC", sqliteColumnType("decimal(10,2)"))
+	assert.Equal(t, "TEXT", sqliteColumnType("varchar(40) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci"))
+	assert.Equal(t, "TEXT", sqliteColumnType("enum('a','b') CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci"))
+	assert.Equal(t, "TEXT", sqliteColumnType("datetime"))
+	assert.Equal(t, "BLOB", sqliteColumnType("mediumblob"))
+
+	d := &sqliteDialect{}
+	definition := d.columnDefinition(&columnDefinition{name: "Age", columnType: "int unsigned", notNull: true, defaultValue: "'0'"})
+	assert.Equal(t, "`Age` INTEGER NOT NULL DEFAULT '0'", definition)
+	column := parseSQLiteColumnDefinition("Age", definition)
+	assert.Equal(t, "INTEGER", column.columnType)
+	assert.True(t, column.notNull)
+	assert.Equal(t, "'0'", column.defaultValue)
+	definition = d.columnDefinition(&columnDefinition{name: "ID", columnType: "bigint unsigned", notNull: true, autoIncrement: true})
+	assert.Equal(t, "`ID` INTEGER PRIMARY KEY AUTOINCREMENT", definition)
+	column = parseSQLiteColumnDefinition("ID", definition)
+	assert.Equal(t, "INTEGER", column.columnType)
+	assert.False(t, column.notNull)
+
+	columns := []*ColumnSchemaDefinition{{"ID", "`ID` INTEGER NOT NULL"}, {"Name", "`Name` TEXT DEFAULT NULL"}}
+	assert.Equal(t, "CREATE TABLE `Table` (\n  `ID` INTEGER NOT NULL,\n  `Name` TEXT DEFAULT NULL,\n  PRIMARY KEY (`ID`)\n)",
+		buildSQLiteCreateTableSQL("Table", columns))
+	columns[0].Definition = definition
+	assert.Equal(t, "CREATE TABLE `Table` (\n  `ID` INTEGER PRIMARY KEY AUTOINCREMENT,\n  `Name` TEXT DEFAULT NULL\n)",
+		buildSQLiteCreateTableSQL("Table", columns))
+
+	index := &IndexSchemaDefinition{Name: "name", Unique: true}
+	index.SetColumns([]string{"Name", "Age"})
+	assert.Equal(t, "CREATE UNIQUE INDEX `Table_name` ON `Table` (`Name`,`Age`)", buildSQLiteCreateIndexSQL("Table", index))
+}
+
+func TestSQLiteDialect(t *testing.T) {
+	d := &sqliteDialect{}
+	assert.Equal(t, "sqlite3", d.driverName(&MySQLOptions{}))
+	assert.Equal(t, "sqlite", d.driverName(&MySQLOptions{Driver: "sqlite"}))
+	query := "SELECT `ID` FROM `Table` WHERE IFNULL(`Name`, '') = ? LIMIT 20,10"
+	assert.Equal(t, query, d.translateQuery(query))
+	assert.Equal(t, "DELETE FROM `Table`", d.truncateTableSQL(nil, "Table"))
+	assert.Equal(t, "TRUNCATE TABLE `test`.`Table`", (&mysqlDialect{}).truncateTableSQL(&mySQLConfig{databaseName: "test"}, "Table"))
+
+	err, skip := d.skippableAsyncError(errors.New("UNIQUE constraint failed: Table.Name"))
+	assert.True(t, skip)
+	assert.EqualError(t, err, "UNIQUE constraint failed: Table.Name")
+	_, skip = d.skippableAsyncError(errors.New("database is locked"))
+	assert.False(t, skip)
+	_, skip = d.skippableAsyncError("UNIQUE constraint failed")
+	assert.False(t, skip)
+
+	schema := &entitySchema{tableName: "Table", versionColumn: "Version"}
+	assert.Equal(t, " ON CONFLICT(`ID`) DO UPDATE SET `Name`=excluded.`Name`,`Version`=`Version`+1",
+		d.upsertClause(schema, []string{"Name"}))
+	assert.Equal(t, " ON CONFLICT DO NOTHING", d.upsertClause(&entitySchema{tableName: "Table"}, nil))
+	schema = &entitySchema{tableName: "Table", uniqueIndices: map[string][]string{"Name": {"Name"}, "Code": {"Code", "Age"}}}
+	assert.Equal(t, " ON CONFLICT(`Code`,`Age`) DO UPDATE SET `Name`=excluded.`Name`"+
+		" ON CONFLICT(`Name`) DO UPDATE SET `Name`=excluded.`Name` ON CONFLICT(`ID`) DO UPDATE SET `Name`=excluded.`Name`",
+		d.upsertClause(schema, []string{"Name"}))
+
+	r := NewRegistry()
+	r.RegisterSQLite(":memory:", "embedded")
+	config := r.(*registry).mysqlPools["embedded"]
+	assert.Equal(t, ":memory:", config.GetDataSourceURI())
+	assert.Equal(t, "main", config.GetDatabaseName())
+	assert.IsType(t, &sqliteDialect{}, config.getDialect())
+}
+
+func TestSQLiteCRUD(t *testing.T) {
+	var entity *sqliteEntity
+	registry := NewRegistry()
+	registry.RegisterSQLite(filepath.Join(t.TempDir(), "test.db"), DefaultPoolCode)
+	registry.RegisterRedisInMemory(DefaultPoolCode)
+	registry.RegisterLocalCache(DefaultPoolCode, 0)
+	registry.RegisterEntity(entity)
+	engine, err := registry.Validate()
+	assert.NoError(t, err)
+	defer engine.Close()
+	orm := engine.NewORM(context.Background())
+	for _, alter := range GetAlters(orm) {
+		alter.Exec(orm)
+	}
+	assert.Len(t, GetAlters(orm), 0)
+
+	entity = NewEntity[sqliteEntity](orm)
+	entity.Code = "a"
+	entity.Name = "First"
+	entity.Active = true
+	entity.Age = 18
+	assert.NoError(t, orm.Flush())
+
+	loaded, found := GetByID[sqliteEntity](orm, entity.ID)
+	assert.True(t, found)
+	assert.Equal(t, "First", loaded.Name)
+	assert.True(t, loaded.Active)
+	assert.Equal(t, uint16(18), loaded.Age)
+
+	edit := EditEntity(orm, loaded)
+	edit.Name = "Second"
+	edit.Active = false
+	assert.NoError(t, orm.Flush())
+	loaded, found = SearchOne[sqliteEntity](orm, NewWhere("`Active` = ? AND `Age` > ?", false, 10))
+	assert.True(t, found)
+	assert.Equal(t, "Second", loaded.Name)
+
+	upsert := &sqliteEntity{Code: "a", Name: "Third", Age: 20}
+	UpsertEntity(orm, upsert, "Name", "Age")
+	assert.NoError(t, orm.Flush())
+	assert.Equal(t, entity.ID, upsert.ID)
+	total := 0
+	orm.Engine().DB(DefaultPoolCode).QueryRow(orm, NewWhere("SELECT COUNT(*) FROM `sqliteEntity`"), &total)
+	assert.Equal(t, 1, total)
+
+	orm.Engine().DB(DefaultPoolCode).Exec(orm, "INSERT INTO `sqliteEntity`(`ID`,`Code`,`Name`,`Active`,`Age`) VALUES(?,?,?,?,?)"+
+		(&sqliteDialect{}).upsertClause(GetEntitySchema[sqliteEntity](orm).(*entitySchema), []string{"Name"}), 999, "a", "Fourth", 0, 1)
+	name := ""
+	orm.Engine().DB(DefaultPoolCode).QueryRow(orm, NewWhere("SELECT `Name` FROM `sqliteEntity` WHERE `ID` = ?", entity.ID), &name)
+	assert.Equal(t, "Fourth", name)
+	orm.Engine().DB(DefaultPoolCode).QueryRow(orm, NewWhere("SELECT COUNT(*) FROM `sqliteEntity`"), &total)
+	assert.Equal(t, 1, total)
+
+	DeleteEntity(orm, upsert)
+	assert.NoError(t, orm.Flush())
+	_, found = GetByID[sqliteEntity](orm, entity.ID)
+	assert.False(t, found)
+}
@@ -22,6 +22,10 @@ func (h *MockLogHandler) Clear() {
 
 func PrepareTables(t *testing.T, registry Registry, entities ...any) (orm ORM) {
 	registry.RegisterMySQL("root:root@tcp(localhost:3377)/test", DefaultPoolCode, &MySQLOptions{})
+	return prepareTables(t, registry, entities...)
+}
+
+func prepareTables(t *testing.T, registry Registry, entities ...any) (orm ORM) {
 	registry.RegisterRedis("localhost:6385", 0, DefaultPoolCode, nil)
 	registry.RegisterRedis("localhost:6385", 1, "second", nil)
 	registry.RegisterLocalCache(DefaultPoolCode, 0)
@@ -0,0 +1,225 @@
+package beeorm
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/puzpuzpuz/xsync/v2"
+)
+
+const transactionSavepointPrefix = "beeorm_sp_"
+
+type transactionTouchedEntity struct {
+	schema *entitySchema
+	id     uint64
+}
+
+type transactionSavepoint struct {
+	pipelines   int
+	postActions int
+	asyncEvents int
+	touched     int
+	reverts     int
+}
+
+type ormTransaction struct {
+	transactions map[string]*dbImplementation
+	poolsOrder   []string
+	savepoints   []transactionSavepoint
+	pipelines    []map[string]*RedisPipeLine
+	postActions  []func(orm ORM)
+	asyncEvents  []pendingAsyncEvent
+	touched      []transactionTouchedEntity
+	reverts      []func()
+	mutex        sync.Mutex
+}
+
+func (orm *ormImplementation) Transaction(f func(tx ORM) error) (err error) {
+	if orm.transaction != nil {
+		return orm.savepoint(f)
+	}
+	state := &ormTransaction{transactions: make(map[string]*dbImplementation)}
+	tx := orm.Clone().(*ormImplementation)
+	tx.transaction = state
+	committed := false
+	defer func() {
+		if !committed {
+			state.rollback(tx)
+		}
+	}()
+	err = f(tx)
+	if err != nil {
+		return err
+	}
+	err = tx.Flush()
+	if err != nil {
+		return err
+	}
+	for _, code := range state.poolsOrder {
+		state.transactions[code].Commit(tx)
+	}
+	committed = true
+	for _, code := range state.poolsOrder {
+		orm.engine.DB(code).(*dbImplementation).stickToPrimary(orm)
+	}
+	for _, pipelines := range state.pipelines {
+		for _, pipeline := range pipelines {
+			pipeline.Exec(tx)
+		}
+	}
+	if len(state.asyncEvents) > 0 {
+		tx.publishAsyncEvents(state.asyncEvents)
+		for _, pipeline := range tx.redisPipeLines {
+			pipeline.Exec(tx)
+		}
+		tx.redisPipeLines = nil
+	}
+	for _, action := range state.postActions {
+		action(tx)
+	}
+	return nil
+}
+
+func (orm *ormImplementation) savepoint(f func(tx ORM) error) (err error) {
+	state := orm.transaction
+	state.mutex.Lock()
+	state.savepoints = append(state.savepoints, transactionSavepoint{
+		pipelines:   len(state.pipelines),
+		postActions: len(state.postActions),
+		asyncEvents: len(state.asyncEvents),
+		touched:     len(state.touched),
+		reverts:     len(state.reverts),
+	})
+	level := len(state.savepoints)
+	name := transactionSavepointPrefix + strconv.Itoa(level)
+	for _, code := range state.poolsOrder {
+		state.transactions[code].Exec(orm, "SAVEPOINT "+name)
+	}
+	state.mutex.Unlock()
+	tx := orm.Clone().(*ormImplementation)
+	tx.transaction = state
+	released := false
+	defer func() {
+		if !released {
+			state.rollbackToSavepoint(tx, level)
+		}
+	}()
+	err = f(tx)
+	if err != nil {
+		return err
+	}
+	err = tx.Flush()
+	if err != nil {
+		return err
+	}
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	for _, code := range state.poolsOrder {
+		state.transactions[code].Exec(tx, "RELEASE SAVEPOINT "+name)
+	}
+	state.savepoints = state.savepoints[0 : level-1]
+	released = true
+	return nil
+}
+
+func (orm *ormImplementation) getDBTransaction(db *dbImplementation, begin bool) DBBase {
+	if orm.transaction == nil || db.transaction {
+		return nil
+	}
+	state := orm.transaction
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	code := db.GetConfig().GetCode()
+	tx, has := state.transactions[code]
+	if has {
+		return tx
+	}
+	if !begin {
+		return nil
+	}
+	tx = db.Begin(orm).(*dbImplementation)
+	for i := range state.savepoints {
+		tx.Exec(orm, "SAVEPOINT "+transactionSavepointPrefix+strconv.Itoa(i+1))
+	}
+	state.transactions[code] = tx
+	state.poolsOrder = append(state.poolsOrder, code)
+	return tx
+}
+
+func (orm *ormImplementation) publishAsyncEvent(schema *entitySchema, event asyncTemporaryQueueEvent) {
+	if orm.transaction != nil {
+		orm.transaction.mutex.Lock()
+		defer orm.transaction.mutex.Unlock()
+		orm.transaction.asyncEvents = append(orm.transaction.asyncEvents, pendingAsyncEvent{schema: schema, event: event})
+		return
+	}
+	orm.asyncEvents = append(orm.asyncEvents, pendingAsyncEvent{schema: schema, event: event})
+}
+
+func (orm *ormImplementation) deferFlushSideEffects() {
+	state := orm.transaction
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if len(orm.redisPipeLines) > 0 {
+		state.pipelines = append(state.pipelines, orm.redisPipeLines)
+	}
+	state.postActions = append(state.postActions, orm.flushPostActions...)
+	for _, update := range orm.flushEntityUpdates {
+		state.reverts = append(state.reverts, update.revert)
+	}
+	orm.trackedEntities.Range(func(_ uint64, entities *xsync.MapOf[uint64, EntityFlush]) bool {
+		entities.Range(func(id uint64, flush EntityFlush) bool {
+			state.touched = append(state.touched, transactionTouchedEntity{schema: flush.Schema(), id: id})
+			return true
+		})
+		return true
+	})
+}
+
+func (t *ormTransaction) rollback(orm *ormImplementation) {
+	for _, code := range t.poolsOrder {
+		t.transactions[code].Rollback(orm)
+	}
+	t.evict(orm, 0)
+	t.revert(0)
+	t.pipelines = nil
+	t.postActions = nil
+	t.asyncEvents = nil
+	t.touched = nil
+	t.reverts = nil
+}
+
+func (t *ormTransaction) rollbackToSavepoint(orm *ormImplementation, level int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	name := transactionSavepointPrefix + strconv.Itoa(level)
+	for _, code := range t.poolsOrder {
+		t.transactions[code].Exec(orm, "ROLLBACK TO SAVEPOINT "+name)
+	}
+	savepoint := t.savepoints[level-1]
+	t.evict(orm, savepoint.touched)
+	t.revert(savepoint.reverts)
+	t.pipelines = t.pipelines[0:savepoint.pipelines]
+	t.postActions = t.postActions[0:savepoint.postActions]
+	t.asyncEvents = t.asyncEvents[0:savepoint.asyncEvents]
+	t.touched = t.touched[0:savepoint.touched]
+	t.reverts = t.reverts[0:savepoint.reverts]
+	t.savepoints = t.savepoints[0 : level-1]
+}
+
+func (t *ormTransaction) evict(orm *ormImplementation, from int) {
+	for _, touched := range t.touched[from:] {
+		if touched.schema.hasLocalCache {
+			touched.schema.localCache.removeEntity(orm, touched.id)
+		}
+		if touched.schema.hasRedisCache {
+			touched.schema.redisCache.Del(orm, touched.schema.getCacheKey()+":"+strconv.FormatUint(touched.id, 10))
+		}
+	}
+}
+
+func (t *ormTransaction) revert(from int) {
+	for i := len(t.reverts) - 1; i >= from; i-- {
+		t.reverts[i]()
+	}
+}
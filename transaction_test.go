@@ -0,0 +1,110 @@
+package beeorm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type transactionEntity struct {
+	ID   uint64 `orm:"localCache;redisCache"`
+	Name string `orm:"required"`
+}
+
+func TestTransaction(t *testing.T) {
+	var entity *transactionEntity
+	orm := PrepareTables(t, NewRegistry(), entity)
+
+	var id uint64
+	err := orm.Transaction(func(tx ORM) error {
+		e := NewEntity[transactionEntity](tx)
+		e.Name = "Committed"
+		id = e.ID
+		if err := tx.Flush(); err != nil {
+			return err
+		}
+		tx.Engine().DB(DefaultPoolCode).Exec(tx, "UPDATE `transactionEntity` SET `Name` = ? WHERE ID = ?", "Raw", id)
+		name := ""
+		found := tx.Engine().DB(DefaultPoolCode).QueryRow(tx, NewWhere("SELECT `Name` FROM `transactionEntity` WHERE ID = ?", id), &name)
+		assert.True(t, found)
+		assert.Equal(t, "Raw", name)
+		return nil
+	})
+	assert.NoError(t, err)
+	name := ""
+	found := orm.Engine().DB(DefaultPoolCode).QueryRow(orm, NewWhere("SELECT `Name` FROM `transactionEntity` WHERE ID = ?", id), &name)
+	assert.True(t, found)
+	assert.Equal(t, "Raw", name)
+
+	var rolledBackID uint64
+	err = orm.Transaction(func(tx ORM) error {
+		e := NewEntity[transactionEntity](tx)
+		e.Name = "Rolled back"
+		rolledBackID = e.ID
+		if err := tx.Flush(); err != nil {
+			return err
+		}
+		for _, rawID := range []uint64{id + 1000, id + 1001} {
+			tx.Engine().DB(DefaultPoolCode).Exec(tx, "INSERT INTO `transactionEntity` (`ID`, `Name`) VALUES (?, ?)", rawID, "Raw")
+		}
+		_, found := GetByID[transactionEntity](tx, id+1000)
+		assert.True(t, found)
+		assert.Len(t, GetByIDs[transactionEntity](tx, rolledBackID, id+1001).All(), 2)
+		return errors.New("stop")
+	})
+	assert.EqualError(t, err, "stop")
+	_, found = GetByID[transactionEntity](orm, rolledBackID)
+	assert.False(t, found)
+	_, found = GetByID[transactionEntity](orm, id+1000)
+	assert.False(t, found)
+	_, found = GetByID[transactionEntity](orm, id+1001)
+	assert.False(t, found)
+
+	var savedID, nestedID uint64
+	err = orm.Transaction(func(tx ORM) error {
+		e := NewEntity[transactionEntity](tx)
+		e.Name = "Outer"
+		savedID = e.ID
+		if err := tx.Flush(); err != nil {
+			return err
+		}
+		nestedErr := tx.Transaction(func(tx ORM) error {
+			e := NewEntity[transactionEntity](tx)
+			e.Name = "Nested"
+			nestedID = e.ID
+			if err := tx.Flush(); err != nil {
+				return err
+			}
+			_, found := GetByID[transactionEntity](tx, nestedID)
+			assert.True(t, found)
+			return errors.New("nested")
+		})
+		assert.EqualError(t, nestedErr, "nested")
+		return tx.Transaction(func(tx ORM) error {
+			e, _ := GetByID[transactionEntity](tx, savedID)
+			e = EditEntity(tx, e)
+			e.Name = "Outer changed"
+			return nil
+		})
+	})
+	assert.NoError(t, err)
+	e, found := GetByID[transactionEntity](orm, savedID)
+	assert.True(t, found)
+	assert.Equal(t, "Outer changed", e.Name)
+	_, found = GetByID[transactionEntity](orm, nestedID)
+	assert.False(t, found)
+
+	assert.Panics(t, func() {
+		_ = orm.Transaction(func(tx ORM) error {
+			e := NewEntity[transactionEntity](tx)
+			e.Name = "Panic"
+			rolledBackID = e.ID
+			_ = tx.Flush()
+			GetByID[transactionEntity](tx, rolledBackID)
+			panic("stop")
+		})
+	})
+	_, found = GetByID[transactionEntity](orm, rolledBackID)
+	assert.False(t, found)
+}
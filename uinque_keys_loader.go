@@ -26,7 +26,7 @@ func LoadUniqueKeys(orm ORM, force bool, entities ...EntitySchema) (inserted int
 		if !hasRedis {
 			cache = orm.Engine().Redis(DefaultPoolCode)
 		}
-		db := schema.GetDB()
+		shards := schema.(*entitySchema).getShards()
 		for indexName, columns := range schema.GetUniqueIndexes() {
 			if len(columns) == 0 {
 				continue
@@ -55,48 +55,59 @@ func LoadUniqueKeys(orm ORM, force bool, entities ...EntitySchema) (inserted int
 				var val string
 				pointers[i+1] = &val
 			}
+			softDeleteCondition := schema.(*entitySchema).softDeleteCondition
+			if softDeleteCondition != "" {
+				where.Append(" AND " + softDeleteCondition)
+			}
 			where.Append(" ORDER BY `ID`")
 			whereCount := "SELECT COUNT(`ID`) FROM `" + schema.GetTableName() + "` WHERE " + where.String()
-			selectWhere.Append(" FROM `" + schema.GetTableName() + "` WHERE ID > ? AND")
+			selectWhere.Append(" FROM `" + schema.GetTableName() + "` WHERE `ID` > ? AND")
 			selectWhere.Append(where.String())
 
 			total := uint64(0)
-			db.QueryRow(orm, NewWhere(whereCount), &total)
+			for _, shard := range shards {
+				shardTotal := uint64(0)
+				shard.GetDB().Primary().QueryRow(orm, NewWhere(whereCount), &shardTotal)
+				total += shardTotal
+			}
 			if total == 0 {
 				cache.HSet(orm, hSetKey, "", 0)
 				continue
 			}
-			func() {
-				p, cl := db.Prepare(orm, selectWhere.String())
-				defer cl()
-				lastID := uint64(0)
-				executed := uint64(0)
-				for {
-					count := 0
-					func() {
-						rows, cl2 := p.Query(orm, lastID)
-						defer cl2()
-						for rows.Next() {
-							rows.Scan(pointers...)
-							id := *pointers[0].(*string)
-							lastID, _ = strconv.ParseUint(id, 10, 64)
-							hField := ""
-							for i := 1; i < len(pointers); i++ {
-								hField += *pointers[i].(*string)
+			for _, shard := range shards {
+				db := shard.GetDB()
+				func() {
+					p, cl := db.Prepare(orm, selectWhere.String())
+					defer cl()
+					lastID := uint64(0)
+					executed := uint64(0)
+					for {
+						count := 0
+						func() {
+							rows, cl2 := p.Query(orm, lastID)
+							defer cl2()
+							for rows.Next() {
+								rows.Scan(pointers...)
+								id := *pointers[0].(*string)
+								lastID, _ = strconv.ParseUint(id, 10, 64)
+								hField := ""
+								for i := 1; i < len(pointers); i++ {
+									hField += *pointers[i].(*string)
+								}
+								cache.HSet(orm, hSetKey, hashString(hField), id)
+								count++
+								executed++
+								inserted++
 							}
-							cache.HSet(orm, hSetKey, hashString(hField), id)
-							count++
-							executed++
-							inserted++
+							cl2()
+						}()
+						if count < loadingUniqueKeysPage {
+							break
 						}
-						cl2()
-					}()
-					if count < loadingUniqueKeysPage {
-						break
 					}
-				}
-				cl()
-			}()
+					cl()
+				}()
+			}
 			cache.HSet(orm, hSetKey, "_is_valid", "1")
 		}
 	}
@@ -0,0 +1,221 @@
+package beeorm
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type upsertableEntity struct {
+	insertableEntity
+	onDuplicateFields []string
+}
+
+func (u *upsertableEntity) flushType() FlushType {
+	return Upsert
+}
+
+func UpsertEntity[E any](orm ORM, entity E, onDuplicateFields ...string) {
+	schema := getEntitySchema[E](orm)
+	upsert := &upsertableEntity{onDuplicateFields: onDuplicateFields}
+	upsert.orm = orm
+	upsert.schema = schema
+	upsert.entity = entity
+	upsert.value = reflect.ValueOf(entity)
+	upsert.id = upsert.value.Elem().Field(0).Uint()
+	if upsert.id == 0 {
+		upsert.id = schema.uuid(orm)
+		if schema.shardKey != "" {
+			upsert.id = schema.shardKeyID(upsert.id, schema.getShardKey(upsert.value.Elem()))
+		}
+		upsert.value.Elem().Field(0).SetUint(upsert.id)
+	}
+	orm.trackEntity(upsert)
+}
+
+func (orm *ormImplementation) handleUpserts(async bool, schema *entitySchema, operations []EntityFlush) error {
+	for _, operation := range operations {
+		upsert := operation.(*upsertableEntity)
+		bind, err := upsert.getBind()
+		if err != nil {
+			return err
+		}
+		columns := upsert.onDuplicateFields
+		if len(columns) == 0 {
+			columns = schema.GetColumns()[1:]
+		}
+		updateColumns := make([]string, 0, len(columns))
+		for _, column := range columns {
+			if _, has := bind[column]; !has || column == "ID" {
+				return &BindError{column, "unknown field"}
+			}
+			if column == schema.versionColumn {
+				continue
+			}
+			updateColumns = append(updateColumns, column)
+		}
+		onDuplicate := schema.GetDB().GetConfig().getDialect().upsertClause(schema, updateColumns)
+		existingID, oldBind := orm.findUpsertDuplicate(schema, bind)
+		if existingID == 0 {
+			err = orm.handleInserts(async, schema, []EntityFlush{upsert}, onDuplicate)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		newBind := Bind{}
+		for _, column := range updateColumns {
+			if !reflect.DeepEqual(bind[column], oldBind[column]) {
+				newBind[column] = bind[column]
+			}
+		}
+		if schema.versionColumn != "" {
+			newBind[schema.versionColumn] = oldBind[schema.versionColumn].(uint64) + 1
+		}
+		bind["ID"] = existingID
+		elem := upsert.value.Elem()
+		elem.Field(0).SetUint(existingID)
+		for column, value := range oldBind {
+			if _, has := newBind[column]; !has && column != "ID" {
+				schema.fieldSetters[column](value, elem)
+			}
+		}
+		for column, value := range newBind {
+			schema.fieldSetters[column](value, elem)
+		}
+		orm.appendUpsertQuery(async, schema.getShard(existingID), bind, onDuplicate)
+		orm.invalidateBulkOperationRow(schema, existingID, oldBind, newBind)
+	}
+	return nil
+}
+
+func (orm *ormImplementation) findUpsertDuplicate(schema *entitySchema, bind Bind) (uint64, Bind) {
+	uniqueIndexes := schema.GetUniqueIndexes()
+	if len(uniqueIndexes) > 0 {
+		cache := orm.Engine().Redis(schema.getForcedRedisCode())
+		for indexName, indexColumns := range uniqueIndexes {
+			hField, hasKey := buildUniqueKeyHSetField(schema, indexColumns, bind)
+			if !hasKey {
+				continue
+			}
+			previousID, inUse := cache.HGet(orm, schema.getCacheKey()+":"+indexName, hField)
+			if !inUse {
+				continue
+			}
+			id, _ := strconv.ParseUint(previousID, 10, 64)
+			/* #nosec */
+			query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.GetTableName() + "` WHERE `ID` = ?"
+			ids, binds := orm.loadBulkOperationRows(schema.getShard(id), query, []any{id})
+			if len(ids) > 0 {
+				return ids[0], binds[0]
+			}
+		}
+	}
+	conditions := "`ID` = ?"
+	parameters := []any{bind["ID"]}
+	for _, indexColumns := range uniqueIndexes {
+		if _, hasKey := buildUniqueKeyHSetField(schema, indexColumns, bind); !hasKey {
+			continue
+		}
+		conditions += " OR (`" + strings.Join(indexColumns, "` = ? AND `") + "` = ?)"
+		for _, column := range indexColumns {
+			parameters = append(parameters, bind[column])
+		}
+	}
+	/* #nosec */
+	query := "SELECT " + schema.fieldsQuery + " FROM `" + schema.GetTableName() + "` WHERE " + conditions + " LIMIT 1"
+	for _, shard := range schema.getShards() {
+		ids, binds := orm.loadBulkOperationRows(shard, query, parameters)
+		if len(ids) > 0 {
+			return ids[0], binds[0]
+		}
+	}
+	return 0, nil
+}
+
+func (orm *ormImplementation) appendUpsertQuery(async bool, schema *entitySchema, bind Bind, onDuplicate string) {
+	columns := schema.GetColumns()
+	/* #nosec */
+	sql := "INSERT INTO `" + schema.GetTableName() + "`(`" + strings.Join(columns, "`,`") + "`) VALUES(?" +
+		strings.Repeat(",?", len(columns)-1) + ")" + onDuplicate
+	args := make([]any, len(columns))
+	for i, column := range columns {
+		value := bind[column]
+		if async {
+			asUint64, isUint64 := value.(uint64)
+			if isUint64 {
+				value = strconv.FormatUint(asUint64, 10)
+			}
+		}
+		args[i] = value
+	}
+	if async {
+		orm.publishAsyncEvent(schema, append([]any{sql}, args...))
+		return
+	}
+	orm.appendDBAction(schema, func(db DBBase) {
+		db.Exec(orm, sql, args...)
+	})
+}
+
+func (orm *ormImplementation) executeUpsertReturningID(db DBBase, schema *entitySchema, insert entityFlushInsert, query string, args []any) {
+	if primary, isDB := db.(DB); isDB {
+		db = primary.Primary()
+	}
+	var id uint64
+	rows, closeRows := db.Query(orm, query, args...)
+	defer closeRows()
+	if rows.Next() {
+		rows.Scan(&id)
+	}
+	if id != insert.ID() {
+		orm.handleUpsertConflict(schema, insert, id)
+	}
+}
+
+func (orm *ormImplementation) handleUpsertConflict(schema *entitySchema, insert entityFlushInsert, id uint64) {
+	insertedID := insert.ID()
+	bind, err := insert.getBind()
+	checkError(err)
+	if id == 0 {
+		id = insertedID
+	}
+	insert.getValue().Elem().Field(0).SetUint(id)
+	idAsString := strconv.FormatUint(id, 10)
+	insertedIDAsString := strconv.FormatUint(insertedID, 10)
+	for _, evicted := range []uint64{insertedID, id} {
+		evictedID := evicted
+		if schema.hasLocalCache {
+			orm.flushPostActions = append(orm.flushPostActions, func(_ ORM) {
+				schema.localCache.removeEntity(orm, evictedID)
+			})
+			orm.invalidateLocalCacheEntity(schema, evictedID)
+		}
+		if schema.hasRedisCache {
+			orm.RedisPipeLine(schema.redisCache.GetCode()).Del(schema.getCacheKey() + ":" + strconv.FormatUint(evictedID, 10))
+		}
+	}
+	p := orm.RedisPipeLine(schema.getForcedRedisCode())
+	for indexName, indexColumns := range schema.GetUniqueIndexes() {
+		hField, hasKey := buildUniqueKeyHSetField(schema, indexColumns, bind)
+		if hasKey {
+			p.HSet(schema.getCacheKey()+":"+indexName, hField, idAsString)
+		}
+	}
+	for columnName := range schema.cachedReferences {
+		refID := bind[columnName]
+		if refID == nil {
+			continue
+		}
+		orm.invalidateBulkOperationReference(schema, columnName, refID.(uint64))
+		redisSetKey := schema.cacheKey + ":" + columnName + ":" + strconv.FormatUint(refID.(uint64), 10)
+		if insertedID != id {
+			p.SRem(redisSetKey, insertedIDAsString)
+			p.SAdd(redisSetKey, idAsString)
+		}
+	}
+	if schema.cacheAll && insertedID != id {
+		orm.invalidateBulkOperationReference(schema, cacheAllFakeReferenceKey, 0)
+		p.SRem(schema.cacheKey+":"+cacheAllFakeReferenceKey, insertedIDAsString)
+	}
+}
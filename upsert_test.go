@@ -0,0 +1,98 @@
+package beeorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type upsertEntity struct {
+	ID   uint64                     `orm:"localCache;redisCache"`
+	Code string                     `orm:"unique=Code;required"`
+	Name string                     `orm:"required"`
+	Age  uint8                      `orm:"index=Age"`
+	Ref  Reference[upsertReference] `orm:"index=Ref;cached"`
+}
+
+type upsertReference struct {
+	ID   uint64
+	Name string
+}
+
+func TestUpsertLocalCache(t *testing.T) {
+	testUpsert(t, false, true, false)
+}
+
+func TestUpsertRedisCache(t *testing.T) {
+	testUpsert(t, false, false, true)
+}
+
+func TestUpsertNoCache(t *testing.T) {
+	testUpsert(t, false, false, false)
+}
+
+func TestUpsertAsync(t *testing.T) {
+	testUpsert(t, true, true, true)
+}
+
+func testUpsert(t *testing.T, async, local, redis bool) {
+	var entity *upsertEntity
+	var reference *upsertReference
+	orm := PrepareTables(t, NewRegistry(), entity, reference)
+	schema := GetEntitySchema[upsertEntity](orm)
+	schema.DisableCache(!local, !redis)
+
+	flush := func() {
+		if async {
+			assert.NoError(t, orm.FlushAsync())
+			assert.NoError(t, runAsyncConsumer(orm, false))
+		} else {
+			assert.NoError(t, orm.Flush())
+		}
+	}
+
+	ref1 := NewEntity[upsertReference](orm)
+	ref1.Name = "Ref 1"
+	ref2 := NewEntity[upsertReference](orm)
+	ref2.Name = "Ref 2"
+	assert.NoError(t, orm.Flush())
+
+	entity = &upsertEntity{Code: "a", Name: "First", Age: 10, Ref: Reference[upsertReference](ref1.ID)}
+	UpsertEntity(orm, entity)
+	flush()
+	id := entity.ID
+	assert.NotZero(t, id)
+	loaded, found := GetByID[upsertEntity](orm, id)
+	assert.True(t, found)
+	assert.Equal(t, "First", loaded.Name)
+	assert.Equal(t, 1, GetByReference[upsertEntity](orm, "Ref", ref1.ID).Len())
+
+	entity = &upsertEntity{Code: "a", Name: "Second", Age: 20, Ref: Reference[upsertReference](ref2.ID)}
+	UpsertEntity(orm, entity, "Name", "Ref")
+	flush()
+	assert.Equal(t, id, entity.ID)
+	assert.Equal(t, uint8(10), entity.Age)
+	loaded, found = GetByID[upsertEntity](orm, id)
+	assert.True(t, found)
+	assert.Equal(t, "Second", loaded.Name)
+	assert.Equal(t, uint8(10), loaded.Age)
+	assert.Equal(t, ref2.ID, loaded.Ref.GetID())
+	assert.Equal(t, 0, GetByReference[upsertEntity](orm, "Ref", ref1.ID).Len())
+	assert.Equal(t, 1, GetByReference[upsertEntity](orm, "Ref", ref2.ID).Len())
+	loaded, found = GetByUniqueIndex[upsertEntity](orm, "Code", "a")
+	assert.True(t, found)
+	assert.Equal(t, id, loaded.ID)
+
+	orm.Engine().Redis(DefaultPoolCode).FlushDB(orm)
+	entity = &upsertEntity{Code: "a", Name: "Third"}
+	UpsertEntity(orm, entity)
+	flush()
+	assert.Equal(t, id, entity.ID)
+	loaded, found = GetByID[upsertEntity](orm, id)
+	assert.True(t, found)
+	assert.Equal(t, "Third", loaded.Name)
+	assert.Equal(t, 1, len(Search[upsertEntity](orm, NewWhere("1"), nil).All()))
+
+	UpsertEntity(orm, &upsertEntity{Code: "b", Name: "Invalid"}, "Invalid")
+	assert.EqualError(t, orm.Flush(), "[Invalid] unknown field")
+}
@@ -22,6 +22,17 @@ func (err *DuplicatedKeyError) Error() string {
 	return err.Message
 }
 
+type OptimisticLockError struct {
+	EntityName string
+	ID         uint64
+	Version    uint64
+	schema     *entitySchema
+}
+
+func (err *OptimisticLockError) Error() string {
+	return fmt.Sprintf("entity %s with ID %d was modified by another process, version %d is outdated", err.EntityName, err.ID, err.Version)
+}
+
 func hashString(value string) string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(value)))
 }
@@ -17,10 +17,21 @@ func (r *registry) InitByYaml(yaml map[string]any) error {
 		for dataKey, value := range dataAsMap {
 			switch dataKey {
 			case "mysql":
-				err = validateOrmMysqlURI(r, value, key)
+				err = validateOrmMysqlURI(r.RegisterMySQL, value, key)
 				if err != nil {
 					return err
 				}
+			case "postgresql":
+				err = validateOrmMysqlURI(r.RegisterPostgreSQL, value, key)
+				if err != nil {
+					return err
+				}
+			case "sqlite":
+				path, err := validateOrmString(value, key)
+				if err != nil {
+					return err
+				}
+				r.RegisterSQLite(path, key)
 			case "redis":
 				err = validateRedisURI(r, value, key)
 				if err != nil {
@@ -52,7 +63,7 @@ func (r *registry) InitByYaml(yaml map[string]any) error {
 	return nil
 }
 
-func validateOrmMysqlURI(registry *registry, value any, key string) error {
+func validateOrmMysqlURI(register func(dataSourceName string, poolCode string, poolOptions *MySQLOptions), value any, key string) error {
 	def, err := fixYamlMap(value, key)
 	if err != nil {
 		return err
@@ -94,9 +105,31 @@ func validateOrmMysqlURI(registry *registry, value any, key string) error {
 			if err != nil {
 				return err
 			}
+		case "replicas":
+			options.Replicas, err = validateOrmStrings(v, "replicas")
+			if err != nil {
+				return err
+			}
+		case "replicaStickiness":
+			replicaStickiness, err := validateOrmInt(v, "replicaStickiness")
+			if err != nil {
+				return err
+			}
+			options.ReplicaStickiness = time.Duration(replicaStickiness) * time.Second
+		case "replicaHealthCheckInterval":
+			interval, err := validateOrmInt(v, "replicaHealthCheckInterval")
+			if err != nil {
+				return err
+			}
+			options.ReplicaHealthCheckInterval = time.Duration(interval) * time.Second
+		case "driver":
+			options.Driver, err = validateOrmString(v, "driver")
+			if err != nil {
+				return err
+			}
 		}
 	}
-	registry.RegisterMySQL(uri, key, options)
+	register(uri, key, options)
 	return nil
 }
 
@@ -105,6 +138,10 @@ func validateRedisURI(registry *registry, value any, key string) error {
 	if !ok {
 		return fmt.Errorf("redis uri '%v' is not valid", value)
 	}
+	if asString == "memory" {
+		registry.RegisterRedisInMemory(key)
+		return nil
+	}
 	parts := strings.Split(asString, "?")
 	elements := strings.Split(parts[0], ":")
 	dbNumber := ""
@@ -142,6 +179,12 @@ func validateRedisURI(registry *registry, value any, key string) error {
 		if values.Has("user") && values.Has("password") {
 			options = &RedisOptions{User: values.Get("user"), Password: values.Get("password")}
 		}
+		if values.Get("localCacheInvalidation") == "true" {
+			if options == nil {
+				options = &RedisOptions{}
+			}
+			options.LocalCacheInvalidation = true
+		}
 	}
 	registry.RegisterRedis(uri, int(db), key, options)
 	return nil
@@ -183,6 +226,7 @@ func validateSentinel(registry *registry, value any, key string) error {
 				options.User = extra.Get("user")
 				options.Password = extra.Get("password")
 			}
+			options.LocalCacheInvalidation = extra.Get("localCacheInvalidation") == "true"
 		}
 		registry.RegisterRedis("", db, key, options)
 	}
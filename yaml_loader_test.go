@@ -3,6 +3,7 @@ package beeorm
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
@@ -15,9 +16,22 @@ func TestYamlLoader(t *testing.T) {
 	err = yaml.Unmarshal(yamlFileData, &parsedYaml)
 	assert.Nil(t, err)
 
-	registry := NewRegistry()
-	err = registry.InitByYaml(parsedYaml)
+	r := NewRegistry()
+	err = r.InitByYaml(parsedYaml)
 	assert.NoError(t, err)
+	mysqlOptions := r.(*registry).mysqlPools[DefaultPoolCode].GetOptions()
+	assert.Equal(t, []string{"root:root@tcp(localhost:3309)/test"}, mysqlOptions.Replicas)
+	assert.Equal(t, time.Second*2, mysqlOptions.ReplicaStickiness)
+	assert.Equal(t, time.Second*10, mysqlOptions.ReplicaHealthCheckInterval)
+	postgreSQLConfig := r.(*registry).mysqlPools["postgres"]
+	assert.Equal(t, "test", postgreSQLConfig.GetDatabaseName())
+	assert.Equal(t, "pgx", postgreSQLConfig.GetOptions().Driver)
+	assert.IsType(t, &postgreSQLDialect{}, postgreSQLConfig.getDialect())
+	sqliteConfig := r.(*registry).mysqlPools["embedded"]
+	assert.Equal(t, "/tmp/beeorm.db", sqliteConfig.GetDataSourceURI())
+	assert.Equal(t, "main", sqliteConfig.GetDatabaseName())
+	assert.IsType(t, &sqliteDialect{}, sqliteConfig.getDialect())
+	assert.Equal(t, "memory:embedded", r.(*registry).redisPools["embedded"].GetAddress())
 
 	invalidYaml := make(map[string]any)
 	invalidYaml["test"] = "invalid"